@@ -0,0 +1,123 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourcePostgreSQLDatabase() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: PGResourceFunc(dataSourcePostgreSQLDatabaseRead),
+		Schema: map[string]*schema.Schema{
+			dbNameAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the PostgreSQL database (e.g. a template) to look up",
+			},
+			dbOwnerAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The ROLE which owns the database",
+			},
+			dbEncodingAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Character set encoding of the database",
+			},
+			dbCollationAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Collation order (LC_COLLATE) of the database",
+			},
+			dbCTypeAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Character classification (LC_CTYPE) of the database",
+			},
+			dbTablespaceAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The name of the tablespace associated with the database",
+			},
+			dbConnLimitAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "How many concurrent connections can be made to this database",
+			},
+			dbAllowConnsAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "If false then no one can connect to this database",
+			},
+			dbIsTemplateAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "If true, then this database can be cloned by any user with CREATEDB privileges",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLDatabaseRead(db *DBConnection, d *schema.ResourceData) error {
+	dbName := d.Get(dbNameAttr).(string)
+
+	var owner, encoding, collation, ctype, tablespaceName string
+	var connLimit int
+	var allowConns, isTemplate bool
+
+	columns := []string{
+		"pg_catalog.pg_get_userbyid(d.datdba)",
+		"pg_catalog.pg_encoding_to_char(d.encoding)",
+		"d.datcollate",
+		"d.datctype",
+		"ts.spcname",
+		"d.datconnlimit",
+	}
+	values := []interface{}{
+		&owner,
+		&encoding,
+		&collation,
+		&ctype,
+		&tablespaceName,
+		&connLimit,
+	}
+
+	if db.featureSupported(featureDBAllowConnections) {
+		columns = append(columns, "d.datallowconn")
+		values = append(values, &allowConns)
+	}
+
+	if db.featureSupported(featureDBIsTemplate) {
+		columns = append(columns, "d.datistemplate")
+		values = append(values, &isTemplate)
+	}
+
+	query := fmt.Sprintf(
+		`SELECT %s `+
+			`FROM pg_catalog.pg_database AS d, pg_catalog.pg_tablespace AS ts `+
+			`WHERE d.datname = $1 AND d.dattablespace = ts.oid`,
+		strings.Join(columns, ", "),
+	)
+	err := db.QueryRow(query, dbName).Scan(values...)
+	switch {
+	case err == sql.ErrNoRows:
+		return fmt.Errorf("database %q not found", dbName)
+	case err != nil:
+		return fmt.Errorf("Error reading database %q: %w", dbName, err)
+	}
+
+	d.Set(dbOwnerAttr, owner)
+	d.Set(dbEncodingAttr, encoding)
+	d.Set(dbCollationAttr, collation)
+	d.Set(dbCTypeAttr, ctype)
+	d.Set(dbTablespaceAttr, tablespaceName)
+	d.Set(dbConnLimitAttr, connLimit)
+	d.Set(dbAllowConnsAttr, allowConns)
+	d.Set(dbIsTemplateAttr, isTemplate)
+	d.SetId(dbName)
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package postgresql
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ownedObjectTypes maps the catalogs referenced by pg_shdepend.classid
+// to the type of the objects they contain.
+var ownedObjectTypes = map[string]string{
+	"pg_class":                "relation",
+	"pg_collation":            "collation",
+	"pg_conversion":           "conversion",
+	"pg_database":             "database",
+	"pg_event_trigger":        "event trigger",
+	"pg_foreign_data_wrapper": "foreign data wrapper",
+	"pg_foreign_server":       "foreign server",
+	"pg_language":             "language",
+	"pg_largeobject_metadata": "large object",
+	"pg_namespace":            "schema",
+	"pg_opclass":              "operator class",
+	"pg_operator":             "operator",
+	"pg_opfamily":             "operator family",
+	"pg_proc":                 "function",
+	"pg_publication":          "publication",
+	"pg_statistic_ext":        "statistics object",
+	"pg_subscription":         "subscription",
+	"pg_ts_config":            "text search configuration",
+	"pg_ts_dict":              "text search dictionary",
+	"pg_type":                 "type",
+}
+
+// ownedObjectsCount is the number of objects of a type owned in a database.
+type ownedObjectsCount struct {
+	database   string
+	objectType string
+	count      int
+}
+
+func dataSourcePostgreSQLDatabaseOwnedObjects() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: PGResourceFunc(dataSourcePostgreSQLDatabaseOwnedObjectsRead),
+		Schema: map[string]*schema.Schema{
+			"role": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The role owning the objects",
+			},
+			"databases": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The databases containing objects owned by the role, in name order",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the database",
+						},
+						"object_types": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The types of the objects owned by the role in the database",
+						},
+						"object_count": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The number of objects owned by the role in the database",
+						},
+					},
+				},
+			},
+			"tablespaces": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The tablespaces owned by the role, in name order",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLDatabaseOwnedObjectsRead(db *DBConnection, d *schema.ResourceData) error {
+	role := d.Get("role").(string)
+
+	counts, err := readOwnedObjectsCounts(db, role)
+	if err != nil {
+		return err
+	}
+
+	tablespaces, err := readOwnedTablespaces(db, role)
+	if err != nil {
+		return err
+	}
+
+	d.Set("databases", groupOwnedObjectsCounts(counts))
+	d.Set("tablespaces", tablespaces)
+	d.SetId(role)
+
+	return nil
+}
+
+// readOwnedObjectsCounts returns the number of objects owned by *role* per database and type,
+// from the owner dependencies of pg_shdepend. The database itself is counted in the
+// database it is: its dependency is recorded as a shared object (dbid = 0).
+func readOwnedObjectsCounts(db QueryAble, role string) ([]ownedObjectsCount, error) {
+	query := `SELECT COALESCE(d.datname, od.datname) AS datname, s.classid::regclass::text, count(*) ` +
+		`FROM pg_catalog.pg_shdepend s ` +
+		`JOIN pg_catalog.pg_roles r ON r.oid = s.refobjid ` +
+		`LEFT JOIN pg_catalog.pg_database d ON d.oid = s.dbid ` +
+		`LEFT JOIN pg_catalog.pg_database od ON s.classid = 'pg_catalog.pg_database'::regclass AND od.oid = s.objid ` +
+		`WHERE s.refclassid = 'pg_catalog.pg_authid'::regclass AND s.deptype = 'o' AND r.rolname = $1 ` +
+		`AND COALESCE(d.datname, od.datname) IS NOT NULL ` +
+		`GROUP BY 1, 2 ORDER BY 1, 2`
+
+	rows, err := db.Query(query, role)
+	if err != nil {
+		return nil, fmt.Errorf("could not list objects owned by %s: %w", role, err)
+	}
+	defer rows.Close()
+
+	counts := []ownedObjectsCount{}
+	for rows.Next() {
+		var count ownedObjectsCount
+		var catalog string
+		if err := rows.Scan(&count.database, &catalog, &count.count); err != nil {
+			return nil, fmt.Errorf("could not scan owned objects: %w", err)
+		}
+		var ok bool
+		if count.objectType, ok = ownedObjectTypes[catalog]; !ok {
+			count.objectType = catalog
+		}
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
+
+func readOwnedTablespaces(db QueryAble, role string) ([]string, error) {
+	rows, err := db.Query(
+		"SELECT spcname FROM pg_catalog.pg_tablespace WHERE pg_catalog.pg_get_userbyid(spcowner) = $1 ORDER BY spcname",
+		role,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not list tablespaces owned by %s: %w", role, err)
+	}
+	defer rows.Close()
+
+	tablespaces := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("could not scan tablespace name: %w", err)
+		}
+		tablespaces = append(tablespaces, name)
+	}
+	return tablespaces, rows.Err()
+}
+
+// groupOwnedObjectsCounts groups the counts, ordered by database, per database.
+func groupOwnedObjectsCounts(counts []ownedObjectsCount) []map[string]interface{} {
+	databases := []map[string]interface{}{}
+	var current map[string]interface{}
+	for _, count := range counts {
+		if current == nil || current["name"] != count.database {
+			current = map[string]interface{}{
+				"name":         count.database,
+				"object_types": []string{},
+				"object_count": 0,
+			}
+			databases = append(databases, current)
+		}
+		current["object_types"] = append(current["object_types"].([]string), count.objectType)
+		current["object_count"] = current["object_count"].(int) + count.count
+	}
+	return databases
+}
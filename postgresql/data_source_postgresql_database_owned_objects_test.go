@@ -0,0 +1,64 @@
+package postgresql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestGroupOwnedObjectsCounts(t *testing.T) {
+	counts := []ownedObjectsCount{
+		{"db1", "database", 1},
+		{"db1", "relation", 3},
+		{"db1", "schema", 1},
+		{"db2", "function", 2},
+	}
+
+	expected := []map[string]interface{}{
+		{"name": "db1", "object_types": []string{"database", "relation", "schema"}, "object_count": 5},
+		{"name": "db2", "object_types": []string{"function"}, "object_count": 2},
+	}
+	if got := groupOwnedObjectsCounts(counts); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+
+	if got := groupOwnedObjectsCounts(nil); len(got) != 0 {
+		t.Fatalf("expected no database, got %v", got)
+	}
+}
+
+func TestAccPostgresqlDataSourceDatabaseOwnedObjects(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	dbExecute(t, dsn, "CREATE ROLE tf_tests_owned_objects")
+	defer dbExecute(t, dsn, "DROP ROLE tf_tests_owned_objects")
+	dbExecute(t, dsn, "CREATE DATABASE tf_tests_db_owned_objects OWNER tf_tests_owned_objects")
+	defer dbExecute(t, dsn, "DROP DATABASE tf_tests_db_owned_objects")
+	dbExecute(t, config.connStr("tf_tests_db_owned_objects"),
+		"CREATE TABLE owned_table (id int); ALTER TABLE owned_table OWNER TO tf_tests_owned_objects")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "postgresql_database_owned_objects" "role" {
+	role = "tf_tests_owned_objects"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_database_owned_objects.role", "databases.#", "1"),
+					resource.TestCheckResourceAttr("data.postgresql_database_owned_objects.role", "databases.0.name", "tf_tests_db_owned_objects"),
+					resource.TestCheckTypeSetElemAttr("data.postgresql_database_owned_objects.role", "databases.0.object_types.*", "database"),
+					resource.TestCheckTypeSetElemAttr("data.postgresql_database_owned_objects.role", "databases.0.object_types.*", "relation"),
+					resource.TestCheckResourceAttr("data.postgresql_database_owned_objects.role", "tablespaces.#", "0"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,49 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceDatabase(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "postgresql_database" "template" {
+	name        = "tf_tests_template_db"
+	template    = "template0"
+	encoding    = "LATIN1"
+	lc_collate  = "C"
+	lc_ctype    = "C"
+	is_template = true
+}
+
+data "postgresql_database" "template" {
+	name = postgresql_database.template.name
+}
+
+resource "postgresql_database" "from_template" {
+	name       = "tf_tests_from_template_db"
+	template   = data.postgresql_database.template.name
+	encoding   = data.postgresql_database.template.encoding
+	lc_collate = data.postgresql_database.template.lc_collate
+	lc_ctype   = data.postgresql_database.template.lc_ctype
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_database.template", "encoding", "LATIN1"),
+					resource.TestCheckResourceAttr("data.postgresql_database.template", "lc_collate", "C"),
+					resource.TestCheckResourceAttr("data.postgresql_database.template", "lc_ctype", "C"),
+					resource.TestCheckResourceAttr("data.postgresql_database.template", "is_template", "true"),
+					resource.TestCheckResourceAttr("postgresql_database.from_template", "encoding", "LATIN1"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,123 @@
+package postgresql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// databasesFetchBatchSize is the maximum number of database names fetched per query,
+// so listing a large cluster never loads all the rows at once.
+const databasesFetchBatchSize = 1000
+
+func dataSourcePostgreSQLDatabases() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: PGResourceFunc(dataSourcePostgreSQLDatabasesRead),
+		Schema: map[string]*schema.Schema{
+			"include_templates": {
+				Type:        schema.TypeBool,
+				Default:     false,
+				Optional:    true,
+				Description: "Determines whether to include the template databases",
+			},
+			"limit": {
+				Type:         schema.TypeInt,
+				Default:      0,
+				Optional:     true,
+				Description:  "Maximum number of databases to return. Zero means no limit.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"offset": {
+				Type:         schema.TypeInt,
+				Default:      0,
+				Optional:     true,
+				Description:  "Number of databases to skip, in name order",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"databases": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The names of the databases, in name order",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLDatabasesRead(db *DBConnection, d *schema.ResourceData) error {
+	includeTemplates := d.Get("include_templates").(bool)
+	limit := d.Get("limit").(int)
+	offset := d.Get("offset").(int)
+
+	// The batches are fetched with keyset pagination on the name,
+	// which is cheaper than an increasing OFFSET.
+	query := "SELECT datname FROM pg_catalog.pg_database WHERE datname > $1"
+	if !includeTemplates {
+		query += " AND NOT datistemplate"
+	}
+	query += " ORDER BY datname LIMIT $2 OFFSET $3"
+
+	fetch := func(after string, skip, count int) ([]string, error) {
+		rows, err := db.Query(query, after, count, skip)
+		if err != nil {
+			return nil, fmt.Errorf("could not list databases: %w", err)
+		}
+		defer rows.Close()
+
+		names := []string{}
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				return nil, fmt.Errorf("could not scan database name: %w", err)
+			}
+			names = append(names, name)
+		}
+		return names, rows.Err()
+	}
+
+	databases, err := fetchInBatches(fetch, limit, offset, databasesFetchBatchSize)
+	if err != nil {
+		return err
+	}
+
+	d.Set("databases", databases)
+	d.SetId(strings.Join([]string{
+		"databases", strconv.FormatBool(includeTemplates), strconv.Itoa(limit), strconv.Itoa(offset),
+	}, "_"))
+
+	return nil
+}
+
+// fetchInBatches returns at most *limit* (zero means no limit) names after skipping *offset* ones,
+// calling *fetch* for batches of at most *batchSize* names.
+// *fetch* must return the names ordered, greater than *after*, after skipping *skip* of them.
+func fetchInBatches(fetch func(after string, skip, count int) ([]string, error), limit, offset, batchSize int) ([]string, error) {
+	result := []string{}
+	after := ""
+	skip := offset
+
+	for {
+		count := batchSize
+		if limit > 0 && limit-len(result) < count {
+			count = limit - len(result)
+		}
+		if count == 0 {
+			return result, nil
+		}
+
+		batch, err := fetch(after, skip, count)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, batch...)
+
+		if len(batch) < count {
+			return result, nil
+		}
+		after = batch[len(batch)-1]
+		skip = 0
+	}
+}
@@ -0,0 +1,90 @@
+package postgresql
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchInBatches(t *testing.T) {
+	// Mock a cluster with a large number of databases.
+	names := make([]string, 10000)
+	for i := range names {
+		names[i] = fmt.Sprintf("tenant_%05d", i)
+	}
+
+	calls := 0
+	fetch := func(after string, skip, count int) ([]string, error) {
+		calls++
+		assert.LessOrEqual(t, count, 100, "batch size exceeded")
+
+		start := sort.SearchStrings(names, after)
+		if start < len(names) && names[start] == after {
+			start++
+		}
+		start += skip
+		if start > len(names) {
+			return []string{}, nil
+		}
+		end := start + count
+		if end > len(names) {
+			end = len(names)
+		}
+		return names[start:end], nil
+	}
+
+	result, err := fetchInBatches(fetch, 0, 0, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, names, result)
+	assert.Equal(t, 101, calls)
+
+	calls = 0
+	result, err = fetchInBatches(fetch, 250, 42, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, names[42:292], result)
+	assert.Equal(t, 3, calls)
+
+	result, err = fetchInBatches(fetch, 10, 9995, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, names[9995:], result)
+}
+
+func TestAccPostgresqlDataSourceDatabases(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "postgresql_database" "test" {
+	count = 3
+	name  = "tf_tests_databases_${count.index}"
+}
+
+data "postgresql_databases" "all" {
+	include_templates = true
+
+	depends_on = [postgresql_database.test]
+}
+
+data "postgresql_databases" "page" {
+	limit  = 2
+	offset = 1
+
+	depends_on = [postgresql_database.test]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckTypeSetElemAttr("data.postgresql_databases.all", "databases.*", "template1"),
+					resource.TestCheckTypeSetElemAttr("data.postgresql_databases.all", "databases.*", "tf_tests_databases_2"),
+					resource.TestCheckResourceAttr("data.postgresql_databases.page", "databases.#", "2"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,196 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// collationProviders maps pg_collation.collprovider to the provider names of CREATE COLLATION.
+var collationProviders = map[string]string{
+	"c": "libc",
+	"i": "icu",
+	"b": "builtin",
+	"d": "default",
+}
+
+func dataSourcePostgreSQLLocales() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: PGResourceFunc(dataSourcePostgreSQLLocalesRead),
+		Schema: map[string]*schema.Schema{
+			"encodings": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The names of the encodings known by the server",
+			},
+			"collations": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The collations of the database the provider is connected to",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the collation",
+						},
+						"schema": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The schema of the collation",
+						},
+						"provider": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The provider of the collation: libc, icu, builtin or default",
+						},
+						"encoding": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The encoding the collation is usable with, empty for any encoding",
+						},
+						"lc_collate": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "LC_COLLATE of the collation, empty if not applicable",
+						},
+						"lc_ctype": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "LC_CTYPE of the collation, empty if not applicable",
+						},
+					},
+				},
+			},
+			"locales": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The operating system locales usable as lc_collate or lc_ctype, from the libc collations",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLLocalesRead(db *DBConnection, d *schema.ResourceData) error {
+	encodings, err := readServerEncodings(db)
+	if err != nil {
+		return err
+	}
+
+	collations, err := readCollations(db)
+	if err != nil {
+		return err
+	}
+
+	localeSet := map[string]bool{}
+	for _, collation := range collations {
+		if collation["provider"] == "libc" && collation["lc_collate"] != "" {
+			localeSet[collation["lc_collate"].(string)] = true
+		}
+	}
+	locales := make([]string, 0, len(localeSet))
+	for locale := range localeSet {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	d.Set("encodings", encodings)
+	d.Set("collations", collations)
+	d.Set("locales", locales)
+	d.SetId("locales")
+
+	return nil
+}
+
+// readServerEncodings returns the names of the encodings known by the server,
+// pg_encoding_to_char returns an empty string for the invalid ids.
+func readServerEncodings(db QueryAble) ([]string, error) {
+	rows, err := db.Query(
+		"SELECT name FROM (SELECT pg_catalog.pg_encoding_to_char(id) AS name FROM generate_series(0, 255) AS id) AS e " +
+			"WHERE name <> '' ORDER BY name",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not list encodings: %w", err)
+	}
+	defer rows.Close()
+
+	encodings := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("could not scan encoding: %w", err)
+		}
+		encodings = append(encodings, name)
+	}
+	return encodings, rows.Err()
+}
+
+func readCollations(db QueryAble) ([]map[string]interface{}, error) {
+	// collprovider only exists since PostgreSQL 10, the collations are libc ones before.
+	hasProvider, err := catalogColumnExists(db, "pg_collation", "collprovider")
+	if err != nil {
+		return nil, err
+	}
+	provider := "'c'"
+	if hasProvider {
+		provider = "c.collprovider::text"
+	}
+
+	// collcollate and collctype are NULL for the ICU collations since PostgreSQL 15.
+	query := fmt.Sprintf(
+		"SELECT c.collname, n.nspname, %s, "+
+			"CASE WHEN c.collencoding = -1 THEN '' ELSE pg_catalog.pg_encoding_to_char(c.collencoding) END, "+
+			"c.collcollate, c.collctype "+
+			"FROM pg_catalog.pg_collation AS c "+
+			"JOIN pg_catalog.pg_namespace AS n ON n.oid = c.collnamespace "+
+			"ORDER BY n.nspname, c.collname",
+		provider,
+	)
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("could not list collations: %w", err)
+	}
+	defer rows.Close()
+
+	collations := []map[string]interface{}{}
+	for rows.Next() {
+		var name, schemaName, providerCode, encoding string
+		var collate, ctype sql.NullString
+		if err := rows.Scan(&name, &schemaName, &providerCode, &encoding, &collate, &ctype); err != nil {
+			return nil, fmt.Errorf("could not scan collation: %w", err)
+		}
+
+		providerName, ok := collationProviders[providerCode]
+		if !ok {
+			providerName = providerCode
+		}
+		collations = append(collations, map[string]interface{}{
+			"name":       name,
+			"schema":     schemaName,
+			"provider":   providerName,
+			"encoding":   encoding,
+			"lc_collate": collate.String,
+			"lc_ctype":   ctype.String,
+		})
+	}
+	return collations, rows.Err()
+}
+
+// catalogColumnExists returns true if the pg_catalog *table* has the *column*,
+// to support the catalogs of the different PostgreSQL and YugabyteDB versions.
+func catalogColumnExists(db QueryAble, table, column string) (bool, error) {
+	var exists bool
+	err := db.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_attribute "+
+			"WHERE attrelid = ('pg_catalog.' || $1)::regclass AND attname = $2 AND NOT attisdropped)",
+		table, column,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("could not check if column %s.%s exists: %w", table, column, err)
+	}
+	return exists, nil
+}
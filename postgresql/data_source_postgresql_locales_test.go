@@ -0,0 +1,35 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceLocales(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "postgresql_locales" "server" {}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckTypeSetElemAttr("data.postgresql_locales.server", "encodings.*", "UTF8"),
+					resource.TestCheckTypeSetElemAttr("data.postgresql_locales.server", "encodings.*", "LATIN1"),
+					resource.TestCheckTypeSetElemNestedAttrs("data.postgresql_locales.server", "collations.*", map[string]string{
+						"name":       "C",
+						"schema":     "pg_catalog",
+						"provider":   "libc",
+						"encoding":   "",
+						"lc_collate": "C",
+					}),
+					resource.TestCheckTypeSetElemAttr("data.postgresql_locales.server", "locales.*", "C"),
+				),
+			},
+		},
+	})
+}
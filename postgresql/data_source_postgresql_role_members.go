@@ -0,0 +1,116 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourcePostgreSQLRoleMembers() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: PGResourceFunc(dataSourcePostgreSQLRoleMembersRead),
+		Schema: map[string]*schema.Schema{
+			"role": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only list the members of this role",
+			},
+			"member": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only list the roles this role is a member of",
+			},
+			"members": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The memberships, ordered by role and member",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"role": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The role granted",
+						},
+						"member": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The role the role is granted to",
+						},
+						"grantor": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The role which granted the membership",
+						},
+						"admin_option": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "True if the member can grant the role to others",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLRoleMembersRead(db *DBConnection, d *schema.ResourceData) error {
+	role := d.Get("role").(string)
+	member := d.Get("member").(string)
+
+	query, args := roleMembersQuery(role, member)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("could not list role memberships: %w", err)
+	}
+	defer rows.Close()
+
+	members := []map[string]interface{}{}
+	for rows.Next() {
+		var roleName, memberName, grantor string
+		var adminOption bool
+		if err := rows.Scan(&roleName, &memberName, &grantor, &adminOption); err != nil {
+			return fmt.Errorf("could not scan role membership: %w", err)
+		}
+		members = append(members, map[string]interface{}{
+			"role":         roleName,
+			"member":       memberName,
+			"grantor":      grantor,
+			"admin_option": adminOption,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not list role memberships: %w", err)
+	}
+
+	d.Set("members", members)
+	d.SetId(fmt.Sprintf("role_members_%s_%s", role, member))
+
+	return nil
+}
+
+// roleMembersQuery returns the query listing the edges of pg_auth_members,
+// filtered on *role* and *member* if they are not empty.
+func roleMembersQuery(role, member string) (string, []interface{}) {
+	query := "SELECT r.rolname, m.rolname, COALESCE(g.rolname, ''), am.admin_option " +
+		"FROM pg_catalog.pg_auth_members AS am " +
+		"JOIN pg_catalog.pg_roles AS r ON r.oid = am.roleid " +
+		"JOIN pg_catalog.pg_roles AS m ON m.oid = am.member " +
+		"LEFT JOIN pg_catalog.pg_roles AS g ON g.oid = am.grantor"
+
+	filters := []string{}
+	args := []interface{}{}
+	if role != "" {
+		args = append(args, role)
+		filters = append(filters, fmt.Sprintf("r.rolname = $%d", len(args)))
+	}
+	if member != "" {
+		args = append(args, member)
+		filters = append(filters, fmt.Sprintf("m.rolname = $%d", len(args)))
+	}
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+
+	return query + " ORDER BY r.rolname, m.rolname", args
+}
@@ -0,0 +1,71 @@
+package postgresql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestRoleMembersQuery(t *testing.T) {
+	cases := []struct {
+		role, member  string
+		expectedWhere string
+		expectedArgs  []interface{}
+	}{
+		{"", "", "", []interface{}{}},
+		{"app_owner", "", " WHERE r.rolname = $1", []interface{}{"app_owner"}},
+		{"", "admin", " WHERE m.rolname = $1", []interface{}{"admin"}},
+		{"app_owner", "admin", " WHERE r.rolname = $1 AND m.rolname = $2", []interface{}{"app_owner", "admin"}},
+	}
+
+	for _, c := range cases {
+		query, args := roleMembersQuery(c.role, c.member)
+		if !strings.HasSuffix(query, c.expectedWhere+" ORDER BY r.rolname, m.rolname") {
+			t.Errorf("roleMembersQuery(%q, %q) returned %q, expected the filters %q", c.role, c.member, query, c.expectedWhere)
+		}
+		if !reflect.DeepEqual(args, c.expectedArgs) {
+			t.Errorf("roleMembersQuery(%q, %q) returned the arguments %v, expected %v", c.role, c.member, args, c.expectedArgs)
+		}
+	}
+}
+
+func TestAccPostgresqlDataSourceRoleMembers(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	dbExecute(t, dsn, "CREATE ROLE tf_tests_members_group")
+	defer dbExecute(t, dsn, "DROP ROLE tf_tests_members_group")
+	dbExecute(t, dsn, "CREATE ROLE tf_tests_members_user")
+	defer dbExecute(t, dsn, "DROP ROLE tf_tests_members_user")
+	dbExecute(t, dsn, "GRANT tf_tests_members_group TO tf_tests_members_user WITH ADMIN OPTION")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "postgresql_role_members" "group" {
+	role = "tf_tests_members_group"
+}
+
+data "postgresql_role_members" "none" {
+	member = "tf_tests_members_group"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_role_members.group", "members.#", "1"),
+					resource.TestCheckResourceAttr("data.postgresql_role_members.group", "members.0.role", "tf_tests_members_group"),
+					resource.TestCheckResourceAttr("data.postgresql_role_members.group", "members.0.member", "tf_tests_members_user"),
+					resource.TestCheckResourceAttr("data.postgresql_role_members.group", "members.0.grantor", config.Username),
+					resource.TestCheckResourceAttr("data.postgresql_role_members.group", "members.0.admin_option", "true"),
+					resource.TestCheckResourceAttr("data.postgresql_role_members.none", "members.#", "0"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,49 @@
+package postgresql
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourcePostgreSQLServerFeatures() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: PGResourceFunc(dataSourcePostgreSQLServerFeaturesRead),
+		Schema: map[string]*schema.Schema{
+			"version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The PostgreSQL version the features are resolved against",
+			},
+			"server_version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The output of SELECT VERSION()",
+			},
+			"yugabytedb": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "True if the server is YugabyteDB",
+			},
+			"features": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeBool},
+				Description: "The features of the provider and whether the server supports them",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLServerFeaturesRead(db *DBConnection, d *schema.ResourceData) error {
+	pgVersion, err := db.serverVersion()
+	if err != nil {
+		return err
+	}
+
+	d.Set("version", db.version.String())
+	d.Set("server_version", pgVersion)
+	d.Set("yugabytedb", ybVersionRegexp.MatchString(pgVersion))
+	d.Set("features", resolveFeatures(db.version, pgVersion))
+	d.SetId("server_features")
+
+	return nil
+}
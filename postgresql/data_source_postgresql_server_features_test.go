@@ -0,0 +1,84 @@
+package postgresql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestResolveFeatures(t *testing.T) {
+	tests := []struct {
+		version   string
+		pgVersion string
+		expected  map[string]bool
+	}{
+		{
+			"9.1.0", "PostgreSQL 9.1.24 on x86_64-pc-linux-gnu",
+			map[string]bool{
+				"db_allow_connections": false, "db_is_template": false, "force_drop_database": false, "pid": false,
+				"colocation": false, "colocation_paren_syntax": false, "database_connection_limit": true,
+			},
+		},
+		{
+			"16.2.0", "PostgreSQL 16.2 on x86_64-pc-linux-gnu",
+			map[string]bool{
+				"db_allow_connections": true, "db_is_template": true, "force_drop_database": true, "pid": true,
+				"colocation": false, "colocation_paren_syntax": false, "database_connection_limit": true,
+			},
+		},
+		{
+			"11.2.0", "PostgreSQL 11.2-YB-2.18.0.0-b0 on x86_64-pc-linux-gnu",
+			map[string]bool{
+				"db_allow_connections": true, "db_is_template": true, "force_drop_database": false, "pid": true,
+				"colocation": true, "colocation_paren_syntax": false, "database_connection_limit": true,
+			},
+		},
+		{
+			"15.2.0", "PostgreSQL 15.2-YB-2.25.0.0-b0 on x86_64-pc-linux-gnu",
+			map[string]bool{
+				"db_allow_connections": true, "db_is_template": true, "force_drop_database": true, "pid": true,
+				"colocation": true, "colocation_paren_syntax": true, "database_connection_limit": true,
+			},
+		},
+		{
+			"11.2.0", "PostgreSQL 11.2-YB-2.8.0.0-b0 on x86_64-pc-linux-gnu",
+			map[string]bool{
+				"db_allow_connections": true, "db_is_template": true, "force_drop_database": false, "pid": true,
+				"colocation": true, "colocation_paren_syntax": false, "database_connection_limit": false,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pgVersion, func(t *testing.T) {
+			if got := resolveFeatures(semver.MustParse(test.version), test.pgVersion); !reflect.DeepEqual(got, test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestAccPostgresqlDataSourceServerFeatures(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "postgresql_server_features" "server" {}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.postgresql_server_features.server", "version"),
+					resource.TestCheckResourceAttrSet("data.postgresql_server_features.server", "server_version"),
+					resource.TestCheckResourceAttr("data.postgresql_server_features.server", "features.pid", "true"),
+					resource.TestCheckResourceAttr("data.postgresql_server_features.server", "features.db_is_template", "true"),
+					resource.TestCheckResourceAttrSet("data.postgresql_server_features.server", "features.colocation"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,99 @@
+package postgresql
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// adoptExistingDatabase adopts the existing database instead of creating it.
+// Unlike on creation, where every attribute is applied with its default value
+// (e.g. connection_limit = -1), only the attributes set in the configuration
+// are applied, the other ones are read from the database as they are.
+// The creation steps (prototype, post_create_sql, post_create_maintenance,
+// created_at_tracker) are skipped.
+func adoptExistingDatabase(db *DBConnection, d *schema.ResourceData) diag.Diagnostics {
+	dbName := d.Get(dbNameAttr).(string)
+	raw := d.GetRawConfig()
+	configured := func(attrs ...string) bool {
+		for _, attr := range attrs {
+			if !raw.GetAttr(attr).IsNull() {
+				return true
+			}
+		}
+		return false
+	}
+
+	if err := checkAdoptedDatabase(db, d, configured); err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[INFO] adopting existing PostgreSQL database (%q)", dbName)
+	d.SetId(dbName)
+
+	setters := []struct {
+		attrs []string
+		set   func() error
+	}{
+		{[]string{dbOwnerAttr}, func() error { return setDBOwner(db, d) }},
+		{[]string{dbTablespaceAttr}, func() error { return setDBTablespace(db, d) }},
+		{[]string{dbConnLimitAttr, dbUnlimitedConnsAttr}, func() error { return setDBConnLimit(db, d) }},
+		{[]string{dbAllowConnsAttr}, func() error { return setDBAllowConns(db, d) }},
+		{[]string{dbIsTemplateAttr}, func() error { return setDBIsTemplate(db, d) }},
+		{[]string{dbSearchPathAttr, dbSearchPathModeAttr}, func() error { return setDBSearchPath(db, d) }},
+		{[]string{dbPublicSchemaCreate}, func() error { return doSetDBPublicSchemaCreate(db, d) }},
+		{[]string{dbRoleConnLimitsAttr}, func() error { return setDBRoleConnLimits(db, d) }},
+		{[]string{dbDefaultRoleAttr}, func() error { return setDBDefaultRole(db, d) }},
+		{[]string{dbConnectRolesAttr, dbTempRolesAttr}, func() error { return setDBPrivilegeRoles(db, d) }},
+	}
+	for _, setter := range setters {
+		if !configured(setter.attrs...) {
+			continue
+		}
+		if err := setter.set(); err != nil {
+			return diag.FromErr(fmt.Errorf("Error adopting database %q: %w", dbName, err))
+		}
+	}
+
+	// Only the parameters set in the configuration are set, none is reset on creation.
+	if err := reconcileDBSettings(db, d); err != nil {
+		return diag.FromErr(fmt.Errorf("Error adopting database %q: %w", dbName, err))
+	}
+
+	return resourcePostgreSQLDatabaseReadImpl(db, d)
+}
+
+// checkAdoptedDatabase returns an error if the encoding, collation or ctype set in the
+// configuration differ from the ones of the adopted database, as they cannot be changed.
+func checkAdoptedDatabase(db *DBConnection, d *schema.ResourceData, configured func(attrs ...string) bool) error {
+	dbName := d.Get(dbNameAttr).(string)
+
+	var encoding, collation, ctype string
+	err := db.QueryRow(
+		"SELECT pg_catalog.pg_encoding_to_char(d.encoding), d.datcollate, d.datctype FROM pg_catalog.pg_database AS d WHERE d.datname = $1",
+		dbName,
+	).Scan(&encoding, &collation, &ctype)
+	if err != nil {
+		return fmt.Errorf("Error reading database %q to adopt: %w", dbName, err)
+	}
+
+	for attr, actual := range map[string]string{dbEncodingAttr: encoding, dbCollationAttr: collation, dbCTypeAttr: ctype} {
+		requested := d.Get(attr).(string)
+		if !configured(attr) && (attr == dbEncodingAttr || !configured(dbLocaleAttr)) {
+			continue
+		}
+		if requested == "" || strings.EqualFold(requested, "DEFAULT") {
+			continue
+		}
+		if !strings.EqualFold(requested, actual) {
+			return fmt.Errorf(
+				"database %q already exists with %s %q instead of %q, it cannot be adopted without being recreated",
+				dbName, attr, actual, requested,
+			)
+		}
+	}
+	return nil
+}
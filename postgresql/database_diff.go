@@ -0,0 +1,340 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// localeCodesetEncodings maps the codesets of the locale names (e.g. en_US.UTF-8),
+// normalized with normalizeEncodingName, to the matching PostgreSQL encodings.
+// Only the common ones are listed: the locales with another codeset are not checked.
+var localeCodesetEncodings = map[string]string{
+	"utf8":      "utf8",
+	"unicode":   "utf8",
+	"iso88591":  "latin1",
+	"iso88592":  "latin2",
+	"iso88593":  "latin3",
+	"iso88594":  "latin4",
+	"iso88599":  "latin5",
+	"iso885910": "latin6",
+	"iso885913": "latin7",
+	"iso885914": "latin8",
+	"iso885915": "latin9",
+	"iso885916": "latin10",
+	"iso88595":  "iso88595",
+	"iso88596":  "iso88596",
+	"iso88597":  "iso88597",
+	"iso88598":  "iso88598",
+	"eucjp":     "eucjp",
+	"euckr":     "euckr",
+	"euccn":     "euccn",
+	"euctw":     "euctw",
+	"koi8r":     "koi8r",
+	"koi8u":     "koi8u",
+	"cp1251":    "win1251",
+	"cp1252":    "win1252",
+	"gb18030":   "gb18030",
+}
+
+// normalizeEncodingName returns the name of an encoding or codeset in lowercase,
+// without the dashes and underscores PostgreSQL ignores (e.g. UTF-8 and utf8).
+func normalizeEncodingName(name string) string {
+	return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(name))
+}
+
+// checkDBEncodingLocale returns an error if the codeset of a locale is known
+// to be incompatible with the encoding, as CREATE DATABASE would fail.
+// The rules are conservative: the encodings and locales which are not known,
+// DEFAULT, C and POSIX are always accepted, as well as SQL_ASCII which is
+// allowed with any locale for superusers.
+func checkDBEncodingLocale(encoding string, locales map[string]string) error {
+	if encoding == "" || strings.ToUpper(encoding) == "DEFAULT" {
+		return nil
+	}
+	normalizedEncoding := normalizeEncodingName(encoding)
+	if alias, ok := localeCodesetEncodings[normalizedEncoding]; ok {
+		normalizedEncoding = alias
+	}
+	if normalizedEncoding == "sqlascii" {
+		return nil
+	}
+
+	for _, attr := range []string{dbCollationAttr, dbCTypeAttr} {
+		locale := locales[attr]
+		i := strings.Index(locale, ".")
+		if i < 0 {
+			// No codeset, e.g. C, POSIX or DEFAULT.
+			continue
+		}
+
+		codeset := strings.SplitN(locale[i+1:], "@", 2)[0]
+		localeEncoding, ok := localeCodesetEncodings[normalizeEncodingName(codeset)]
+		if !ok || localeEncoding == normalizedEncoding {
+			continue
+		}
+		return fmt.Errorf(
+			"encoding %s does not match the codeset of %s %q, choose a locale with a matching codeset",
+			encoding, attr, locale,
+		)
+	}
+	return nil
+}
+
+// setDBLocaleDiff sets lc_collate and lc_ctype to the locale of the database at plan time,
+// so they are created and verified as if they were set. It returns an error if they are
+// also set in the configuration with a different value.
+func setDBLocaleDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.GetRawConfig().GetAttr(dbLocaleAttr).IsNull() || !d.NewValueKnown(dbLocaleAttr) {
+		return nil
+	}
+	locale := d.Get(dbLocaleAttr).(string)
+
+	for _, attr := range []string{dbCollationAttr, dbCTypeAttr} {
+		if !d.GetRawConfig().GetAttr(attr).IsNull() {
+			if d.NewValueKnown(attr) && !strings.EqualFold(d.Get(attr).(string), locale) {
+				return fmt.Errorf("%s %q is inconsistent with %s %q, set only one of them", attr, d.Get(attr).(string), dbLocaleAttr, locale)
+			}
+			continue
+		}
+		if d.Get(attr).(string) != locale {
+			if err := d.SetNew(attr, locale); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkDBEncodingLocaleDiff validates the encoding, collation and ctype of the database at plan time.
+func checkDBEncodingLocaleDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	var encoding string
+	switch {
+	case d.Id() == "" && d.GetRawConfig().GetAttr(dbEncodingAttr).IsNull():
+		// The provider creates the databases in its default encoding.
+		encoding = "UTF8"
+		if client, ok := meta.(*Client); ok && client != nil {
+			encoding = defaultDBEncoding(client.config)
+		}
+	case d.NewValueKnown(dbEncodingAttr):
+		encoding = d.Get(dbEncodingAttr).(string)
+	default:
+		return nil
+	}
+
+	locales := map[string]string{}
+	for _, attr := range []string{dbCollationAttr, dbCTypeAttr} {
+		if d.NewValueKnown(attr) {
+			locales[attr] = d.Get(attr).(string)
+		}
+	}
+
+	return checkDBEncodingLocale(encoding, locales)
+}
+
+// checkDBTemplateEncodingDiff checks at plan time that the encoding of the database
+// matches the one of its template, which the server requires for any template but template0.
+func checkDBTemplateEncodingDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() != "" && !d.HasChange(dbTemplateAttr) && !d.HasChange(dbEncodingAttr) {
+		return nil
+	}
+	if d.GetRawConfig().GetAttr(dbEncodingAttr).IsNull() || !d.NewValueKnown(dbEncodingAttr) || !d.NewValueKnown(dbTemplateAttr) {
+		return nil
+	}
+	template := d.Get(dbTemplateAttr).(string)
+	encoding := d.Get(dbEncodingAttr).(string)
+	client, ok := meta.(*Client)
+	if template == "" || template == "template0" || !ok || client == nil {
+		return nil
+	}
+
+	db, err := client.Connect()
+	if err != nil {
+		log.Printf("[WARN] could not connect to check the encoding of template %s: %v", template, err)
+		return nil
+	}
+
+	// pg_char_to_encoding resolves the aliases of the encoding names, e.g. UTF-8.
+	var templateEncoding string
+	var compatible bool
+	err = db.WithContext(ctx).QueryRow(
+		"SELECT pg_catalog.pg_encoding_to_char(encoding), pg_catalog.pg_char_to_encoding($2) IN (encoding, -1) "+
+			"FROM pg_catalog.pg_database WHERE datname = $1",
+		template, encoding,
+	).Scan(&templateEncoding, &compatible)
+	switch {
+	case err == sql.ErrNoRows:
+		// The missing template is reported when the database is created.
+		return nil
+	case err != nil:
+		log.Printf("[WARN] could not read the encoding of template %s: %v", template, err)
+		return nil
+	}
+
+	return checkDBTemplateEncoding(template, encoding, templateEncoding, compatible)
+}
+
+// checkDBTemplateEncoding returns an error if *encoding* is not *compatible* with the
+// *templateEncoding* of *template*, instead of the one returned by CREATE DATABASE.
+func checkDBTemplateEncoding(template, encoding, templateEncoding string, compatible bool) error {
+	if compatible {
+		return nil
+	}
+	return fmt.Errorf(
+		"%s %q is incompatible with the encoding of template %q (%s): use the same encoding or template0 as %s",
+		dbEncodingAttr, encoding, template, templateEncoding, dbTemplateAttr,
+	)
+}
+
+// checkDBUnlimitedConnectionsDiff checks that unlimited_connections agrees with
+// connection_limit when set, and computes it from connection_limit otherwise.
+func checkDBUnlimitedConnectionsDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	unlimited := d.GetRawConfig().GetAttr(dbUnlimitedConnsAttr)
+	if !unlimited.IsKnown() || !d.NewValueKnown(dbConnLimitAttr) {
+		return nil
+	}
+
+	connLimit := d.Get(dbConnLimitAttr).(int)
+	if unlimited.IsNull() {
+		if d.HasChange(dbConnLimitAttr) {
+			return d.SetNew(dbUnlimitedConnsAttr, connLimit == -1)
+		}
+		return nil
+	}
+	return checkDBUnlimitedConnections(unlimited.True(), connLimit)
+}
+
+// checkDBUnlimitedConnections returns an error if unlimited_connections contradicts connection_limit.
+// connection_limit defaults to -1, so unlimited_connections = true alone is enough.
+func checkDBUnlimitedConnections(unlimited bool, connLimit int) error {
+	switch {
+	case unlimited && connLimit != -1:
+		return fmt.Errorf("%s = true conflicts with %s = %d, remove one of them", dbUnlimitedConnsAttr, dbConnLimitAttr, connLimit)
+	case !unlimited && connLimit == -1:
+		return fmt.Errorf("%s = false requires %s to be set to a limit other than -1", dbUnlimitedConnsAttr, dbConnLimitAttr)
+	}
+	return nil
+}
+
+// checkDBSearchPathModeDiff checks that search_path is only set in the explicit mode.
+func checkDBSearchPathModeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	mode := d.Get(dbSearchPathModeAttr).(string)
+	if mode == dbSearchPathModeExplicit || !d.NewValueKnown(dbSearchPathAttr) {
+		return nil
+	}
+	if !d.GetRawConfig().GetAttr(dbSearchPathAttr).IsNull() && len(d.Get(dbSearchPathAttr).([]interface{})) > 0 {
+		return fmt.Errorf("%s cannot be set with %s = %q, only with %q", dbSearchPathAttr, dbSearchPathModeAttr, mode, dbSearchPathModeExplicit)
+	}
+	return nil
+}
+
+// checkDBDestructiveRecreateDiff refuses to recreate a database containing objects
+// because of a change of its encoding, collation or ctype, unless allow_destructive_recreate is set.
+func checkDBDestructiveRecreateDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || d.Get(dbAllowRecreateAttr).(bool) {
+		return nil
+	}
+
+	changed := []string{}
+	for _, attr := range []string{dbEncodingAttr, dbCollationAttr, dbCTypeAttr} {
+		if d.HasChange(attr) {
+			changed = append(changed, attr)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	client, ok := meta.(*Client)
+	if !ok || client == nil {
+		return nil
+	}
+
+	count, err := countDBObjects(client.withContext(ctx), d.Id())
+	if err != nil {
+		return fmt.Errorf("could not check if database %s is empty before recreating it: %w", d.Id(), err)
+	}
+	if count > 0 {
+		return fmt.Errorf(
+			"changing %s recreates database %s which contains %d objects that would be lost, "+
+				"set %s to true to allow it",
+			strings.Join(changed, ", "), d.Id(), count, dbAllowRecreateAttr,
+		)
+	}
+	return nil
+}
+
+var (
+	dbForceNewAttrsOnce sync.Once
+	dbForceNewAttrsList []string
+)
+
+// dbForceNewAttrs returns the sorted attributes of the database whose change recreates it,
+// read once from the schema.
+func dbForceNewAttrs() []string {
+	dbForceNewAttrsOnce.Do(func() {
+		for attr, s := range resourcePostgreSQLDatabase().Schema {
+			if s.ForceNew {
+				dbForceNewAttrsList = append(dbForceNewAttrsList, attr)
+			}
+		}
+		sort.Strings(dbForceNewAttrsList)
+	})
+	return dbForceNewAttrsList
+}
+
+// checkDBRecreateDiff fails the plan when a change of a ForceNew attribute recreates
+// the database and prevent_recreate is set, as the plan only reports that the change
+// forces a replacement.
+func checkDBRecreateDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || !d.Get(dbPreventRecreateAttr).(bool) {
+		return nil
+	}
+
+	changed := []string{}
+	for _, attr := range dbForceNewAttrs() {
+		if d.HasChange(attr) {
+			changed = append(changed, attr)
+		}
+	}
+	return checkDBRecreate(d.Id(), changed)
+}
+
+// checkDBRecreate returns an error explaining that changing the *changed* attributes
+// drops and recreates the database, losing its data.
+func checkDBRecreate(dbName string, changed []string) error {
+	if len(changed) == 0 {
+		return nil
+	}
+	return fmt.Errorf(
+		"changing %s recreates database %s: it is dropped and created again, all its data is lost. "+
+			"Back it up first (see pre_delete_command) and unset %s to allow it",
+		strings.Join(changed, ", "), dbName, dbPreventRecreateAttr,
+	)
+}
+
+// countDBObjects returns the number of relations of the database outside of the system schemas.
+func countDBObjects(client *Client, dbName string) (int, error) {
+	txn, err := startTransaction(client, dbName)
+	if err != nil {
+		return 0, err
+	}
+	defer deferredRollback(txn)
+
+	var count int
+	err = txn.QueryRow(
+		`SELECT count(*) FROM pg_catalog.pg_class c ` +
+			`JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+			`WHERE n.nspname !~ '^pg_' AND n.nspname <> 'information_schema'`,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("could not count the objects of database %s: %w", dbName, err)
+	}
+	return count, nil
+}
@@ -0,0 +1,188 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+
+	"github.com/lib/pq"
+)
+
+// orphanedOwnerRegexp matches the name returned by pg_get_userbyid
+// for the owner of a database whose role does not exist anymore.
+var orphanedOwnerRegexp = regexp.MustCompile(`^unknown \(OID=[0-9]+\)$`)
+
+// isOrphanedDBOwner returns true if *owner* is the owner read for a database
+// whose owner role was dropped, e.g. "unknown (OID=16390)".
+func isOrphanedDBOwner(owner string) bool {
+	return orphanedOwnerRegexp.MatchString(owner)
+}
+
+// changeDBOwner gives the databases *dbNames* to *owner*. Unless it is a superuser, the
+// connecting user must be a member of their current owners and of the new one: the
+// memberships it lacks are granted while the role is locked, and revoked after the change.
+func changeDBOwner(db *DBConnection, dbNames []string, owner string) (err error) {
+	currentUser := db.client.config.getDatabaseUsername()
+
+	changed := []string{}
+	roles := []string{}
+	if ownerMembershipNeeded(owner, currentUser) {
+		roles = append(roles, owner)
+	}
+	for _, dbName := range dbNames {
+		currentOwner, exists, err := readDBOwner(db, dbName)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("Error updating owner of database %q: it does not exist", dbName)
+		}
+		if currentOwner == owner {
+			continue
+		}
+		changed = append(changed, dbName)
+		if ownerMembershipNeeded(currentOwner, currentUser) && !sliceContainsStr(roles, currentOwner) {
+			roles = append(roles, currentOwner)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	if len(roles) > 0 {
+		release, grantErr := grantOwnerMemberships(db, roles, currentUser)
+		if grantErr != nil {
+			return grantErr
+		}
+		defer func() {
+			if releaseErr := release(); releaseErr != nil && err == nil {
+				err = releaseErr
+			}
+		}()
+	}
+
+	for _, dbName := range changed {
+		sql := fmt.Sprintf("ALTER DATABASE %s OWNER TO %s", pq.QuoteIdentifier(dbName), pq.QuoteIdentifier(owner))
+		if _, err := db.Exec(sql); err != nil {
+			return fmt.Errorf("Error updating owner of database %q: %w", dbName, err)
+		}
+	}
+
+	return nil
+}
+
+// readDBOwner returns the owner of *dbName*, or false if the database does not exist.
+// The owner of a database whose owner role was dropped is read as "unknown (OID=...)".
+func readDBOwner(db QueryAble, dbName string) (string, bool, error) {
+	var owner string
+	err := db.QueryRow("SELECT pg_catalog.pg_get_userbyid(datdba) FROM pg_catalog.pg_database WHERE datname = $1", dbName).Scan(&owner)
+	switch {
+	case err == sql.ErrNoRows:
+		return "", false, nil
+	case err != nil:
+		return "", false, fmt.Errorf("Error reading owner of database %q: %w", dbName, err)
+	}
+	return owner, true, nil
+}
+
+// ownerMembershipNeeded returns true if *currentUser* may need to be granted *owner*
+// to create, alter or drop a database owned by *owner*.
+// There is nothing to lock nor grant if the owner is the connected user itself,
+// or if it was dropped: only a superuser can then alter or drop the database.
+func ownerMembershipNeeded(owner, currentUser string) bool {
+	return owner != "" && owner != currentUser && !isOrphanedDBOwner(owner)
+}
+
+// withOwnerMembership executes *fn* while the connecting user is a member of *owner*,
+// which is needed to create or give databases to *owner* if it is not a superuser.
+// The membership is only granted once for all the databases, and shared with the
+// concurrent operations of the provider on the same owner (see ownerGrants). It is
+// always released after *fn*: if both fail, the error of *fn* is returned and the
+// other one logged.
+func withOwnerMembership(db *DBConnection, owner string, fn func() error) (err error) {
+	currentUser := db.client.config.getDatabaseUsername()
+	if !ownerMembershipNeeded(owner, currentUser) {
+		return fn()
+	}
+
+	release, err := db.client.config.ownerGrants.acquire(owner, currentUser, func() (func() error, error) {
+		return grantOwnerMemberships(db, []string{owner}, currentUser)
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if releaseErr := release(); releaseErr != nil {
+			if err != nil {
+				log.Printf("[ERROR] could not revoke %s from %s after a failure: %v", owner, currentUser, releaseErr)
+			} else {
+				err = releaseErr
+			}
+		}
+	}()
+
+	return fn()
+}
+
+// grantOwnerMemberships grants the *owners* to *currentUser*, and returns the function
+// revoking the ones granted. The role is only locked while they are granted and revoked, so
+// the other operations on it do not wait for the whole operation (the sharing of the
+// memberships is counted by ownerGrants). The memberships may be shared with other
+// operations and outlive the one granting them, so they do not use its context.
+func grantOwnerMemberships(db *DBConnection, owners []string, currentUser string) (func() error, error) {
+	db = db.WithContext(context.Background())
+
+	granted := []string{}
+	release := func() error {
+		if len(granted) == 0 {
+			return nil
+		}
+		return withRoleLock(db, currentUser, func() error {
+			var err error
+			for _, owner := range granted {
+				if _, revokeErr := revokeRoleMembership(db, owner, currentUser); revokeErr != nil && err == nil {
+					err = revokeErr
+				}
+			}
+			return err
+		})
+	}
+
+	err := withRoleLock(db, currentUser, func() error {
+		for _, owner := range owners {
+			ownerGranted, err := grantRoleMembership(db, owner, currentUser)
+			if err != nil {
+				return err
+			}
+			if ownerGranted {
+				granted = append(granted, owner)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if releaseErr := release(); releaseErr != nil {
+			log.Printf("[ERROR] could not revoke the memberships of %s after a failure: %v", currentUser, releaseErr)
+		}
+		return nil, err
+	}
+
+	return release, nil
+}
+
+// withRoleLock executes *fn* while *role* is locked on the provider database,
+// the lock is released once *fn* returns.
+func withRoleLock(db *DBConnection, role string, fn func() error) error {
+	lockTxn, err := startTransaction(db.client, "")
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(lockTxn)
+	if err := db.lockRole(lockTxn, role); err != nil {
+		return err
+	}
+
+	return fn()
+}
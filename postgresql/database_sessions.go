@@ -0,0 +1,232 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dbCancelGracePeriod is the time given to the sessions to end after their queries
+// are canceled, before they are terminated (see cancel_before_terminate).
+var dbCancelGracePeriod = 2 * time.Second
+
+// dbIdlePollInterval is the interval between two checks of the active queries
+// while waiting for a database to be idle.
+var dbIdlePollInterval = time.Second
+
+// waitForDBIdleBefore waits for the database to be idle before *operation*
+// if the resource opted in for it.
+func waitForDBIdleBefore(db *DBConnection, d *schema.ResourceData, operation, dbName string) error {
+	timeout := d.Get(dbWaitIdleTimeoutAttr).(int)
+	if timeout == 0 || !d.Get(dbWaitIdleOnAttr).(*schema.Set).Contains(operation) {
+		return nil
+	}
+
+	log.Printf("[DEBUG] waiting for database %s to be idle before %s", dbName, operation)
+	return waitForDBIdle(db, dbName, time.Duration(timeout)*time.Second)
+}
+
+// countDBConnections returns the number of sessions connected to the database.
+func countDBConnections(db QueryAble, dbName string) (int, error) {
+	var count int
+	if err := db.QueryRow("SELECT count(*) FROM pg_catalog.pg_stat_activity WHERE datname = $1", dbName).Scan(&count); err != nil {
+		return 0, fmt.Errorf("could not count the connections to database %s: %w", dbName, err)
+	}
+	return count, nil
+}
+
+// dbSession is a session connected to a database, from pg_stat_activity.
+type dbSession struct {
+	pid             int
+	usename         string
+	applicationName string
+	state           string
+}
+
+// readDBSessions returns the sessions connected to the database, except the current one.
+// Without pg_read_all_stats, the state of the sessions of the other roles is NULL:
+// it is read as an empty string.
+func readDBSessions(db *DBConnection, dbName string) ([]dbSession, error) {
+	pid, state := "procpid", "NULL"
+	if db.featureSupported(featurePid) {
+		pid, state = "pid", "state"
+	}
+	query := fmt.Sprintf(
+		"SELECT %[1]s, COALESCE(usename, ''), COALESCE(application_name, ''), COALESCE(%[2]s, '') "+
+			"FROM pg_catalog.pg_stat_activity WHERE datname = $1 AND %[1]s <> pg_backend_pid() ORDER BY %[1]s",
+		pid, state,
+	)
+
+	rows, err := db.Query(query, dbName)
+	if err != nil {
+		return nil, fmt.Errorf("could not list the sessions of database %s: %w", dbName, err)
+	}
+	defer rows.Close()
+
+	sessions := []dbSession{}
+	for rows.Next() {
+		var session dbSession
+		if err := rows.Scan(&session.pid, &session.usename, &session.applicationName, &session.state); err != nil {
+			return nil, fmt.Errorf("could not scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// dbSessionsState returns the value of active_sessions for the *sessions*.
+func dbSessionsState(sessions []dbSession) []map[string]interface{} {
+	state := make([]map[string]interface{}, 0, len(sessions))
+	for _, session := range sessions {
+		state = append(state, map[string]interface{}{
+			"pid":              session.pid,
+			"usename":          session.usename,
+			"application_name": session.applicationName,
+			"state":            session.state,
+		})
+	}
+	return state
+}
+
+// waitForDBIdle polls pg_stat_activity until no query is running on the database,
+// except the ones of the current session, or returns an error after *timeout*.
+// Unlike terminateBConnections, the running queries are left to finish.
+func waitForDBIdle(db *DBConnection, dbName string, timeout time.Duration) error {
+	// Before PostgreSQL 9.2, there is no state column: the idle sessions are reported
+	// by their current_query.
+	pid, activeFilter := "procpid", "current_query NOT IN ('<IDLE>', '<command string not enabled>')"
+	if db.featureSupported(featurePid) {
+		pid, activeFilter = "pid", "state NOT IN ('idle', 'disabled')"
+	}
+	query := fmt.Sprintf(
+		"SELECT count(*) FROM pg_catalog.pg_stat_activity "+
+			"WHERE datname = $1 AND %s <> pg_backend_pid() AND %s",
+		pid, activeFilter,
+	)
+
+	deadline := time.Now().Add(timeout)
+	for {
+		var active int
+		if err := db.QueryRow(query, dbName).Scan(&active); err != nil {
+			return fmt.Errorf("could not check the active queries on database %s: %w", dbName, err)
+		}
+		if active == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("database %s still has %d active queries after waiting %s", dbName, active, timeout)
+		}
+
+		select {
+		case <-db.client.context().Done():
+			return contextError(db.client.context(), fmt.Errorf("waiting for database %s to be idle", dbName))
+		case <-time.After(dbIdlePollInterval):
+		}
+	}
+}
+
+// terminateBConnections blocks the new connections to the database and terminates
+// the existing ones, after canceling their queries if *cancelFirst* is true.
+func terminateBConnections(db *DBConnection, dbName string, cancelFirst bool) error {
+	if db.featureSupported(featureDBAllowConnections) {
+		alterSql := buildSQL("ALTER DATABASE %s ALLOW_CONNECTIONS %t", sqlIdent(dbName), false)
+
+		if _, err := db.Exec(alterSql); err != nil {
+			return fmt.Errorf("Error blocking connections to database: %w", err)
+		}
+	}
+
+	return drainDBBackends(db, dbName, cancelFirst)
+}
+
+// drainDBBackends terminates the backends connected to the database. If *cancelFirst*
+// is true, their queries are canceled first, so the clients get a query error rather than
+// a connection reset, and only the sessions still connected after dbCancelGracePeriod
+// are terminated.
+func drainDBBackends(db *DBConnection, dbName string, cancelFirst bool) error {
+	return drainBackends(db.client.context(), func(function string) ([]int, error) {
+		return signalDBBackends(db, dbName, function, false)
+	}, cancelFirst, dbCancelGracePeriod)
+}
+
+// drainBackends sends pg_cancel_backend then, after *gracePeriod*, pg_terminate_backend
+// through *signal* if *cancelFirst* is true, only pg_terminate_backend otherwise.
+func drainBackends(ctx context.Context, signal func(function string) ([]int, error), cancelFirst bool, gracePeriod time.Duration) error {
+	if cancelFirst {
+		pids, err := signal("pg_cancel_backend")
+		if err != nil {
+			return err
+		}
+		if len(pids) > 0 {
+			log.Printf("[DEBUG] canceled the queries of %d backends, waiting %s before terminating them", len(pids), gracePeriod)
+			select {
+			case <-ctx.Done():
+				return contextError(ctx, errors.New("waiting for the canceled backends to end"))
+			case <-time.After(gracePeriod):
+			}
+		}
+	}
+
+	pids, err := signal("pg_terminate_backend")
+	if err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] terminated %d backends", len(pids))
+	return nil
+}
+
+// terminateDBBackends terminates the backends connected to the database, except the current one,
+// and returns the pids of the terminated backends.
+// If excludeProvider is true, the other sessions opened by the provider are kept too.
+func terminateDBBackends(db *DBConnection, dbName string, excludeProvider bool) ([]int, error) {
+	return signalDBBackends(db, dbName, "pg_terminate_backend", excludeProvider)
+}
+
+// signalDBBackends calls *function* (pg_cancel_backend or pg_terminate_backend) for the
+// backends connected to the database, except the current one, and returns the pids of
+// the backends signaled successfully.
+func signalDBBackends(db *DBConnection, dbName, function string, excludeProvider bool) ([]int, error) {
+	pid := "procpid"
+	if db.featureSupported(featurePid) {
+		pid = "pid"
+	}
+
+	filter := "datname = $1 AND %[1]s <> pg_backend_pid()"
+	args := []interface{}{dbName}
+	if excludeProvider {
+		filter += " AND application_name <> $2"
+		args = append(args, db.client.config.ApplicationName)
+	}
+
+	// The backends are filtered in the subquery so the function is never
+	// evaluated for sessions of other databases.
+	// The values are bound, only the pid column and the function are formatted.
+	query := buildSQL(
+		"SELECT %[1]s FROM (SELECT %[1]s, pg_catalog.%[2]s(%[1]s) AS signaled FROM pg_stat_activity WHERE "+filter+") AS backends WHERE signaled",
+		sqlIdent(pid), sqlIdent(function),
+	)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Error signaling database connections with %s: %w", function, err)
+	}
+	defer rows.Close()
+
+	pids := []int{}
+	for rows.Next() {
+		var pid int
+		if err := rows.Scan(&pid); err != nil {
+			return nil, fmt.Errorf("could not scan signaled backend pid: %w", err)
+		}
+		pids = append(pids, pid)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Error signaling database connections with %s: %w", function, err)
+	}
+
+	return pids, nil
+}
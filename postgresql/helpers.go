@@ -1,27 +1,66 @@
 package postgresql
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"math/rand"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/lib/pq"
 )
 
-func PGResourceFunc(fn func(*DBConnection, *schema.ResourceData) error) func(*schema.ResourceData, interface{}) error {
-	return func(d *schema.ResourceData, meta interface{}) error {
+func PGResourceFunc(fn func(*DBConnection, *schema.ResourceData) error) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 		client := meta.(*Client)
 
 		db, err := client.Connect()
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
 
-		return fn(db, d)
+		// Bind the statements to the operation so they are canceled with it.
+		return diag.FromErr(contextError(ctx, fn(db.WithContext(ctx), d)))
+	}
+}
+
+// PGResourceDiagFunc is the same as PGResourceFunc for the functions
+// returning diagnostics, e.g. to report warnings.
+func PGResourceDiagFunc(fn func(*DBConnection, *schema.ResourceData) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		client := meta.(*Client)
+
+		db, err := client.Connect()
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		diags := fn(db.WithContext(ctx), d)
+		if ctx.Err() != nil {
+			for i := range diags {
+				if diags[i].Severity == diag.Error {
+					diags[i].Summary = fmt.Sprintf("%v: %s", ctx.Err(), diags[i].Summary)
+				}
+			}
+		}
+		return diags
+	}
+}
+
+// contextError reports the reason why the context is done, e.g. the timeout of the operation
+// is exceeded, as the statement errors returned by the server do not mention it.
+func contextError(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
 	}
+	return fmt.Errorf("%w: %v", ctx.Err(), err)
 }
 
 func PGResourceExistsFunc(fn func(*DBConnection, *schema.ResourceData) (bool, error)) func(*schema.ResourceData, interface{}) (bool, error) {
@@ -54,6 +93,35 @@ func pqQuoteLiteral(in string) string {
 	return in
 }
 
+// sqlIdent is an identifier (e.g. a database name) quoted by buildSQL.
+type sqlIdent string
+
+// sqlLiteral is a string value quoted by buildSQL, for the statements which
+// cannot take bind parameters ($n) such as ALTER DATABASE.
+type sqlLiteral string
+
+// buildSQL formats the statement *format* with the fmt verbs: the identifiers and
+// the literals are quoted, the integers and booleans are formatted as is.
+// Any other argument, e.g. a raw string, is a programming error and panics,
+// the values of the queries which accept bind parameters must be bound instead.
+func buildSQL(format string, args ...interface{}) string {
+	formatted := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case sqlIdent:
+			formatted[i] = pq.QuoteIdentifier(string(v))
+		case sqlLiteral:
+			// QuoteLiteral prefixes the escaped literals with a space.
+			formatted[i] = strings.TrimPrefix(pq.QuoteLiteral(string(v)), " ")
+		case int, bool:
+			formatted[i] = v
+		default:
+			panic(fmt.Sprintf("buildSQL: unsupported argument %#v of type %T", arg, arg))
+		}
+	}
+	return fmt.Sprintf(format, formatted...)
+}
+
 func isMemberOfRole(db QueryAble, role, member string) (bool, error) {
 	var _rez int
 	setOption := true
@@ -139,7 +207,7 @@ func revokeRoleMembership(db QueryAble, role, member string) (bool, error) {
 // withRolesGranted temporarily grants, if needed, the roles specified to connected user
 // (i.e.: the admin configure in the provider) and revoke them as soon as the
 // callback func has finished.
-func withRolesGranted(txn *sql.Tx, roles []string, fn func() error) error {
+func withRolesGranted(txn *DBTransaction, roles []string, fn func() error) error {
 	// No roles asked, execute the function directly
 	if len(roles) == 0 {
 		return fn()
@@ -321,6 +389,16 @@ func stringSliceToSet(slice []string) *schema.Set {
 	return schema.NewSet(schema.HashString, s)
 }
 
+// setToStringSlice returns the elements of a set of strings, sorted.
+func setToStringSlice(set *schema.Set) []string {
+	slice := make([]string, set.Len())
+	for i, v := range set.List() {
+		slice[i] = v.(string)
+	}
+	sort.Strings(slice)
+	return slice
+}
+
 func quoteIdentifyIdent(ident string) string {
 	// When passing a function with arguments like "test(text, char)" this will correctly parse it to "test"(text, char).
 	// If we were to add quotes around the whole ident postgres would not be able to find the function.
@@ -371,21 +449,24 @@ func setToPgIdentSimpleList(idents *schema.Set) string {
 // startTransaction starts a new DB transaction on the specified database.
 // If the database is specified and different from the one configured in the provider,
 // it will create a new connection pool if needed.
-func startTransaction(client *Client, database string) (*sql.Tx, error) {
+func startTransaction(client *Client, database string) (*DBTransaction, error) {
 	if database != "" && database != client.databaseName {
+		ctx := client.ctx
 		client = client.config.NewClient(database)
+		client.ctx = ctx
 	}
 	db, err := client.Connect()
 	if err != nil {
 		return nil, err
 	}
 
-	txn, err := db.Begin()
+	// The transaction is rolled back if the context of the client is done.
+	txn, err := db.BeginTx(client.context(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("could not start transaction: %w", err)
 	}
 
-	return txn, nil
+	return &DBTransaction{txn, client}, nil
 }
 
 func dbExists(db QueryAble, dbname string) (bool, error) {
@@ -400,7 +481,19 @@ func dbExists(db QueryAble, dbname string) (bool, error) {
 	return true, nil
 }
 
-func roleExists(txn *sql.Tx, rolname string) (bool, error) {
+func tablespaceExists(db QueryAble, spcname string) (bool, error) {
+	err := db.QueryRow("SELECT 1 FROM pg_tablespace WHERE spcname=$1", spcname).Scan(&spcname)
+	switch {
+	case err == sql.ErrNoRows:
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("could not check if tablespace exists: %w", err)
+	}
+
+	return true, nil
+}
+
+func roleExists(txn *DBTransaction, rolname string) (bool, error) {
 	err := txn.QueryRow("SELECT 1 FROM pg_roles WHERE rolname=$1", rolname).Scan(&rolname)
 	switch {
 	case err == sql.ErrNoRows:
@@ -412,7 +505,7 @@ func roleExists(txn *sql.Tx, rolname string) (bool, error) {
 	return true, nil
 }
 
-func schemaExists(txn *sql.Tx, schemaname string) (bool, error) {
+func schemaExists(txn *DBTransaction, schemaname string) (bool, error) {
 	err := txn.QueryRow("SELECT 1 FROM pg_namespace WHERE nspname=$1", schemaname).Scan(&schemaname)
 	switch {
 	case err == sql.ErrNoRows:
@@ -438,7 +531,7 @@ func getCurrentUser(db QueryAble) (string, error) {
 
 // deferredRollback can be used to rollback a transaction in a defer.
 // It will log an error if it fails
-func deferredRollback(txn *sql.Tx) {
+func deferredRollback(txn *DBTransaction) {
 	err := txn.Rollback()
 	switch {
 	case err == sql.ErrTxDone:
@@ -554,6 +647,21 @@ func isSuperuser(db QueryAble, role string) (bool, error) {
 
 const publicRole = "public"
 
+// PostgreSQL error codes (SQLSTATE) handled by the provider.
+// See https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pqErrorCodeDuplicateDatabase pq.ErrorCode = "42P04"
+	pqErrorCodeUndefinedTable    pq.ErrorCode = "42P01"
+	pqErrorCodeUndefinedDatabase pq.ErrorCode = "3D000"
+	pqErrorCodeObjectInUse       pq.ErrorCode = "55006"
+)
+
+// isPQErrorCode returns true if err is a PostgreSQL error with the specified SQLSTATE code.
+func isPQErrorCode(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
+
 func getRoleOID(db QueryAble, role string) (uint32, error) {
 	if role == publicRole {
 		return 0, nil
@@ -566,12 +674,21 @@ func getRoleOID(db QueryAble, role string) (uint32, error) {
 	return oid, nil
 }
 
-// Lock a role and all his members to avoid concurrent updates on some resources
-func pgLockRole(txn *sql.Tx, role string) error {
-	// Disable statement timeout for this connection otherwise the lock could fail
-	if _, err := txn.Exec("SET statement_timeout = 0"); err != nil {
+// disableLockStatementTimeout disables the statement timeout for the rest of the
+// transaction, otherwise waiting for a lock could fail. SET LOCAL does not leak to
+// the next transactions of the connection, which PgBouncer may give to another client.
+func disableLockStatementTimeout(txn *DBTransaction) error {
+	if _, err := txn.Exec("SET LOCAL statement_timeout = 0"); err != nil {
 		return fmt.Errorf("could not disable statement_timeout: %w", err)
 	}
+	return nil
+}
+
+// Lock a role and all his members to avoid concurrent updates on some resources
+func pgLockRole(txn *DBTransaction, role string) error {
+	if err := disableLockStatementTimeout(txn); err != nil {
+		return err
+	}
 	if _, err := txn.Exec("SELECT pg_advisory_xact_lock(oid::bigint) FROM pg_roles WHERE rolname = $1", role); err != nil {
 		return fmt.Errorf("could not get advisory lock for role %s: %w", role, err)
 	}
@@ -586,11 +703,79 @@ func pgLockRole(txn *sql.Tx, role string) error {
 	return nil
 }
 
+const (
+	lockRetryBaseDelay = 100 * time.Millisecond
+	lockRetryMaxDelay  = 5 * time.Second
+)
+
+// lockRetryDelay returns the delay to wait before retrying to acquire a lock
+// after the failed attempt number *attempt* (starting at 0).
+// The delay grows exponentially up to lockRetryMaxDelay and is jittered
+// between half and the full delay so concurrent callers don't retry in lockstep.
+func lockRetryDelay(attempt int) time.Duration {
+	delay := lockRetryMaxDelay
+	if attempt < 16 {
+		delay = lockRetryBaseDelay << attempt
+		if delay > lockRetryMaxDelay {
+			delay = lockRetryMaxDelay
+		}
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// pgTryLockRole locks a role and all his members like pgLockRole but without
+// blocking: if the lock is already held it retries up to *maxRetries* times
+// with a jittered exponential backoff before giving up, or until *ctx* is done.
+// Each attempt runs in a savepoint, so the locks it could get are released when it
+// fails instead of being held while waiting, which could starve the other callers.
+func pgTryLockRole(ctx context.Context, txn *DBTransaction, role string, maxRetries int) error {
+	if err := disableLockStatementTimeout(txn); err != nil {
+		return err
+	}
+
+	query := `
+SELECT COALESCE(bool_and(locked), true) FROM (
+  SELECT pg_try_advisory_xact_lock(oid::bigint) AS locked FROM pg_roles WHERE rolname = $1
+  UNION ALL
+  SELECT pg_try_advisory_xact_lock(member::bigint) FROM pg_auth_members JOIN pg_roles ON roleid = pg_roles.oid WHERE rolname = $1
+) AS locks
+`
+	for attempt := 0; ; attempt++ {
+		if _, err := txn.Exec("SAVEPOINT try_lock_role"); err != nil {
+			return fmt.Errorf("could not create savepoint to lock role %s: %w", role, err)
+		}
+		var locked bool
+		if err := txn.QueryRow(query, role).Scan(&locked); err != nil {
+			return fmt.Errorf("could not get advisory lock for role %s: %w", role, err)
+		}
+		if locked {
+			// The locks are kept by the transaction once the savepoint is released.
+			if _, err := txn.Exec("RELEASE SAVEPOINT try_lock_role"); err != nil {
+				return fmt.Errorf("could not release savepoint after locking role %s: %w", role, err)
+			}
+			return nil
+		}
+		if _, err := txn.Exec("ROLLBACK TO SAVEPOINT try_lock_role"); err != nil {
+			return fmt.Errorf("could not release the partial advisory locks for role %s: %w", role, err)
+		}
+		if attempt >= maxRetries {
+			return fmt.Errorf("could not get advisory lock for role %s after %d retries", role, maxRetries)
+		}
+
+		delay := lockRetryDelay(attempt)
+		log.Printf("[DEBUG] advisory lock for role %s is busy, retrying in %s", role, delay)
+		select {
+		case <-ctx.Done():
+			return contextError(ctx, fmt.Errorf("waiting for the advisory lock for role %s", role))
+		case <-time.After(delay):
+		}
+	}
+}
+
 // Lock a database and all his members to avoid concurrent updates on some resources
-func pgLockDatabase(txn *sql.Tx, database string) error {
-	// Disable statement timeout for this connection otherwise the lock could fail
-	if _, err := txn.Exec("SET statement_timeout = 0"); err != nil {
-		return fmt.Errorf("could not disable statement_timeout: %w", err)
+func pgLockDatabase(txn *DBTransaction, database string) error {
+	if err := disableLockStatementTimeout(txn); err != nil {
+		return err
 	}
 	if _, err := txn.Exec("SELECT pg_advisory_xact_lock(oid::bigint) FROM pg_database WHERE datname = $1", database); err != nil {
 		return fmt.Errorf("could not get advisory lock for database %s: %w", database, err)
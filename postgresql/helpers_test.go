@@ -1,8 +1,12 @@
 package postgresql
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -47,6 +51,54 @@ func TestQuoteTableName(t *testing.T) {
 	}
 }
 
+func TestBuildSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		args     []interface{}
+		expected string
+	}{
+		{
+			name:     "simple name",
+			format:   "ALTER DATABASE %s CONNECTION LIMIT = %d",
+			args:     []interface{}{sqlIdent("mydb"), 10},
+			expected: `ALTER DATABASE "mydb" CONNECTION LIMIT = 10`,
+		},
+		{
+			name:     "tricky names",
+			format:   "ALTER DATABASE %s RENAME TO %s",
+			args:     []interface{}{sqlIdent(`My"DB`), sqlIdent(`x"; DROP DATABASE postgres; --`)},
+			expected: `ALTER DATABASE "My""DB" RENAME TO "x""; DROP DATABASE postgres; --"`,
+		},
+		{
+			name:     "boolean",
+			format:   "ALTER DATABASE %s IS_TEMPLATE %t",
+			args:     []interface{}{sqlIdent("Mixed Case"), true},
+			expected: `ALTER DATABASE "Mixed Case" IS_TEMPLATE true`,
+		},
+		{
+			name:     "literal",
+			format:   "COMMENT ON DATABASE %s IS %s",
+			args:     []interface{}{sqlIdent("mydb"), sqlLiteral(`it's a \ test`)},
+			expected: `COMMENT ON DATABASE "mydb" IS E'it''s a \\ test'`,
+		},
+		{
+			name:     "indexed verbs",
+			format:   "SELECT %[1]s FROM pg_stat_activity WHERE %[1]s <> pg_backend_pid()",
+			args:     []interface{}{sqlIdent("pid")},
+			expected: `SELECT "pid" FROM pg_stat_activity WHERE "pid" <> pg_backend_pid()`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, buildSQL(tt.format, tt.args...))
+		})
+	}
+
+	assert.Panics(t, func() { buildSQL("ALTER DATABASE %s", "raw") })
+}
+
 func TestArePrivilegesEqual(t *testing.T) {
 
 	type PrivilegesTestObject struct {
@@ -121,3 +173,180 @@ func buildResourceData(objectType string, t *testing.T) *schema.ResourceData {
 	m["object_type"] = objectType
 	return schema.TestResourceDataRaw(t, testSchema, m)
 }
+
+func TestContextError(t *testing.T) {
+	stmtErr := errors.New("pq: canceling statement due to user request")
+
+	assert.NoError(t, contextError(context.Background(), nil))
+	assert.Equal(t, stmtErr, contextError(context.Background(), stmtErr))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	assert.NoError(t, contextError(ctx, nil))
+	err := contextError(ctx, stmtErr)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.EqualError(t, err, "context deadline exceeded: pq: canceling statement due to user request")
+}
+
+func TestLockRetryDelay(t *testing.T) {
+	for attempt := 0; attempt < 100; attempt++ {
+		expected := lockRetryMaxDelay
+		if attempt < 16 && lockRetryBaseDelay<<attempt < lockRetryMaxDelay {
+			expected = lockRetryBaseDelay << attempt
+		}
+
+		delay := lockRetryDelay(attempt)
+		if delay < expected/2 || delay > expected {
+			t.Errorf("lockRetryDelay(%d) = %s, want between %s and %s", attempt, delay, expected/2, expected)
+		}
+	}
+}
+
+func TestAccPgTryLockRole(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+	username := config.getDatabaseUsername()
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("could not create connection pool: %v", err)
+	}
+	defer db.Close()
+	conn := &DBConnection{DB: db, client: &Client{}}
+
+	// Simulate a concurrent apply holding the lock on the role.
+	holderTxn, err := conn.Begin()
+	if err != nil {
+		t.Fatalf("could not start transaction: %v", err)
+	}
+	defer deferredRollback(holderTxn)
+	if err := pgLockRole(holderTxn, username); err != nil {
+		t.Fatalf("could not lock role: %v", err)
+	}
+
+	txn, err := conn.Begin()
+	if err != nil {
+		t.Fatalf("could not start transaction: %v", err)
+	}
+	if err := pgTryLockRole(context.Background(), txn, username, 0); err == nil {
+		t.Fatalf("pgTryLockRole should fail without retries while the lock is held")
+	}
+	deferredRollback(txn)
+
+	// Release the lock while the second transaction is retrying.
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		deferredRollback(holderTxn)
+	}()
+
+	txn, err = conn.Begin()
+	if err != nil {
+		t.Fatalf("could not start transaction: %v", err)
+	}
+	defer deferredRollback(txn)
+	if err := pgTryLockRole(context.Background(), txn, username, 10); err != nil {
+		t.Fatalf("pgTryLockRole should succeed within the retry budget: %v", err)
+	}
+}
+
+func TestAccPgTryLockRoleReleasesPartialLocks(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	for _, role := range []string{"lock_role", "lock_member"} {
+		teardown := createTestRole(t, role)
+		defer teardown()
+	}
+	dbExecute(t, dsn, "GRANT lock_role TO lock_member")
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("could not create connection pool: %v", err)
+	}
+	defer db.Close()
+	conn := &DBConnection{DB: db, client: &Client{}}
+
+	// Only the role itself is locked by the concurrent apply, its member is free.
+	holderTxn, err := conn.Begin()
+	if err != nil {
+		t.Fatalf("could not start transaction: %v", err)
+	}
+	defer deferredRollback(holderTxn)
+	if _, err := holderTxn.Exec("SELECT pg_advisory_xact_lock(oid::bigint) FROM pg_roles WHERE rolname = 'lock_role'"); err != nil {
+		t.Fatalf("could not lock role: %v", err)
+	}
+
+	txn, err := conn.Begin()
+	if err != nil {
+		t.Fatalf("could not start transaction: %v", err)
+	}
+	defer deferredRollback(txn)
+	if err := pgTryLockRole(context.Background(), txn, "lock_role", 0); err == nil {
+		t.Fatalf("pgTryLockRole should fail while the lock is held")
+	}
+
+	// The lock got on the member by the failed attempt is not kept.
+	var locks int
+	if err := txn.QueryRow("SELECT count(*) FROM pg_locks WHERE locktype = 'advisory' AND pid = pg_backend_pid()").Scan(&locks); err != nil {
+		t.Fatalf("could not count advisory locks: %v", err)
+	}
+	if locks != 0 {
+		t.Errorf("%d advisory locks are held after the failed attempt, expected none", locks)
+	}
+
+	// The retries stop with the operation.
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := pgTryLockRole(ctx, txn, "lock_role", 100); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("pgTryLockRole returned %v, expected context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("pgTryLockRole kept retrying %s after the operation timed out", elapsed)
+	}
+}
+
+func TestAccPgLockRoleKeepsStatementTimeout(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	username := config.getDatabaseUsername()
+
+	db, err := sql.Open("postgres", config.connStr("postgres"))
+	if err != nil {
+		t.Fatalf("could not create connection pool: %v", err)
+	}
+	defer db.Close()
+	conn := &DBConnection{DB: db, client: &Client{}}
+	// A single connection, like a PgBouncer server connection shared by the transactions.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec("SET statement_timeout = '5s'"); err != nil {
+		t.Fatalf("could not set statement_timeout: %v", err)
+	}
+
+	txn, err := conn.Begin()
+	if err != nil {
+		t.Fatalf("could not start transaction: %v", err)
+	}
+	if err := pgLockRole(txn, username); err != nil {
+		t.Fatalf("could not lock role: %v", err)
+	}
+	if err := txn.Commit(); err != nil {
+		t.Fatalf("could not commit transaction: %v", err)
+	}
+
+	var timeout string
+	if err := db.QueryRow("SHOW statement_timeout").Scan(&timeout); err != nil {
+		t.Fatalf("could not read statement_timeout: %v", err)
+	}
+	if timeout != "5s" {
+		t.Errorf("statement_timeout is %s after the lock transaction, want 5s", timeout)
+	}
+}
@@ -0,0 +1,134 @@
+package postgresql
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// operationWaiter is an operation waiting for room in an operationLimiter.
+type operationWaiter struct {
+	weight int
+	ready  chan struct{}
+}
+
+// operationLimiter is a weighted semaphore capping the number of database operations
+// run at the same time by the provider (max_concurrent_operations), whatever the
+// parallelism of Terraform. The operations get room in the order they asked for it,
+// so a heavy operation is not starved by lighter ones.
+// A nil *operationLimiter does not limit the operations.
+type operationLimiter struct {
+	size    int
+	lock    sync.Mutex
+	used    int
+	waiters list.List
+}
+
+// newOperationLimiter returns a limiter running up to *size* operations at the same time,
+// nil if *size* is 0 (unlimited).
+func newOperationLimiter(size int) *operationLimiter {
+	if size <= 0 {
+		return nil
+	}
+	return &operationLimiter{size: size}
+}
+
+// acquire waits until there is room for an operation of *weight*, clamped to the
+// size of the limiter, or until *ctx* is done. It returns the function releasing the room.
+func (l *operationLimiter) acquire(ctx context.Context, weight int) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	weight = min(max(weight, 1), l.size)
+
+	l.lock.Lock()
+	if l.size-l.used >= weight && l.waiters.Len() == 0 {
+		l.used += weight
+		l.lock.Unlock()
+		return l.releaser(weight), nil
+	}
+
+	waiter := &operationWaiter{weight: weight, ready: make(chan struct{})}
+	elem := l.waiters.PushBack(waiter)
+	l.lock.Unlock()
+
+	log.Printf("[DEBUG] waiting for room to run an operation of weight %d (max_concurrent_operations = %d)", weight, l.size)
+	select {
+	case <-waiter.ready:
+		return l.releaser(weight), nil
+	case <-ctx.Done():
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		select {
+		case <-waiter.ready:
+			// The room was given while the context was done: it is kept.
+			return l.releaser(weight), nil
+		default:
+		}
+		// The next waiters may fit now that this one does not wait anymore.
+		isFront := l.waiters.Front() == elem
+		l.waiters.Remove(elem)
+		if isFront {
+			l.notifyWaiters()
+		}
+		return nil, contextError(ctx, fmt.Errorf("waiting for room to run the operation (max_concurrent_operations = %d)", l.size))
+	}
+}
+
+// releaser returns the function releasing the room of an operation of *weight*, once.
+func (l *operationLimiter) releaser(weight int) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.lock.Lock()
+			defer l.lock.Unlock()
+			l.used -= weight
+			l.notifyWaiters()
+		})
+	}
+}
+
+// notifyWaiters gives the room to the waiters in order, while the first one fits.
+// It must be called with the lock held.
+func (l *operationLimiter) notifyWaiters() {
+	for {
+		front := l.waiters.Front()
+		if front == nil {
+			return
+		}
+		waiter := front.Value.(*operationWaiter)
+		if l.size-l.used < waiter.weight {
+			return
+		}
+		l.used += waiter.weight
+		l.waiters.Remove(front)
+		close(waiter.ready)
+	}
+}
+
+// limitOperation wraps a CRUD function so it only runs when the provider has room for
+// an operation of *weight*, which returns the weight of the operation on *d*.
+func limitOperation(
+	weight func(*schema.ResourceData) int,
+	fn func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics,
+) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		release, err := meta.(*Client).config.operations.acquire(ctx, weight(d))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		defer release()
+
+		return fn(ctx, d, meta)
+	}
+}
+
+// singleOperation is the weight of the operations on a single database.
+func singleOperation(*schema.ResourceData) int {
+	return 1
+}
@@ -0,0 +1,171 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestOperationLimiterCap(t *testing.T) {
+	l := newOperationLimiter(3)
+
+	// Many operations run concurrently, at most 3 of them at the same time.
+	const operations = 30
+	var running, maxRunning int32
+	var wg sync.WaitGroup
+	wg.Add(operations)
+	for i := 0; i < operations; i++ {
+		go func() {
+			defer wg.Done()
+			release, err := l.acquire(context.Background(), 1)
+			if err != nil {
+				t.Errorf("could not acquire room for the operation: %v", err)
+				return
+			}
+			defer release()
+
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}()
+	}
+	wg.Wait()
+
+	if maxRunning != 3 {
+		t.Errorf("%d operations ran at the same time, expected 3", maxRunning)
+	}
+	if l.used != 0 || l.waiters.Len() != 0 {
+		t.Errorf("the limiter is not empty after the operations: %d used, %d waiting", l.used, l.waiters.Len())
+	}
+}
+
+func TestOperationLimiterWeight(t *testing.T) {
+	l := newOperationLimiter(4)
+
+	heavy, err := l.acquire(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("could not acquire room for the operation: %v", err)
+	}
+
+	// A weight larger than the limiter is clamped, so the operation can run alone.
+	acquired := make(chan func())
+	go func() {
+		release, err := l.acquire(context.Background(), 10)
+		if err != nil {
+			t.Errorf("could not acquire room for the operation: %v", err)
+		}
+		acquired <- release
+	}()
+	waitForWaiters(t, l, 1)
+
+	// The operations are run in order: a light one does not overtake the waiting one.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("the light operation acquired room before the waiting one: %v", err)
+	}
+
+	heavy()
+	// Releasing twice does not free the room twice.
+	heavy()
+	release := <-acquired
+	if l.used != 4 {
+		t.Errorf("the clamped operation uses %d, expected 4", l.used)
+	}
+	release()
+	if l.used != 0 || l.waiters.Len() != 0 {
+		t.Errorf("the limiter is not empty after the operations: %d used, %d waiting", l.used, l.waiters.Len())
+	}
+}
+
+func TestOperationLimiterCanceled(t *testing.T) {
+	l := newOperationLimiter(1)
+
+	release, err := l.acquire(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("could not acquire room for the operation: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() {
+		_, err := l.acquire(ctx, 1)
+		errs <- err
+	}()
+	waitForWaiters(t, l, 1)
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("acquire returned %v for a canceled operation, expected context.Canceled", err)
+	}
+
+	// The canceled operation does not hold any room.
+	release()
+	release, err = l.acquire(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("could not acquire room for the operation: %v", err)
+	}
+	release()
+}
+
+func TestOperationLimiterNil(t *testing.T) {
+	// max_concurrent_operations = 0 does not limit the operations.
+	l := newOperationLimiter(0)
+	if l != nil {
+		t.Fatalf("newOperationLimiter(0) returned %v, expected nil", l)
+	}
+	for i := 0; i < 100; i++ {
+		if _, err := l.acquire(context.Background(), 1); err != nil {
+			t.Fatalf("a nil limiter returned an error: %v", err)
+		}
+	}
+}
+
+func TestLimitOperation(t *testing.T) {
+	client := &Client{config: Config{operations: newOperationLimiter(2)}}
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLDatabases().Schema, map[string]interface{}{
+		"names": []interface{}{"db_1", "db_2", "db_3"},
+	})
+
+	// The batch of 3 databases is clamped to the whole limiter while it runs.
+	fn := limitOperation(batchDatabasesWeight, func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		if used := client.config.operations.used; used != 2 {
+			t.Errorf("the operation runs with %d used, expected 2", used)
+		}
+		return nil
+	})
+	if diags := fn(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("the limited operation failed: %v", diags)
+	}
+	if used := client.config.operations.used; used != 0 {
+		t.Errorf("%d is still used after the operation", used)
+	}
+}
+
+// waitForWaiters waits until *n* operations are waiting for room in *l*.
+func waitForWaiters(t *testing.T, l *operationLimiter, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		l.lock.Lock()
+		waiting := l.waiters.Len()
+		l.lock.Unlock()
+		if waiting == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d operations are waiting, expected %d", waiting, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,76 @@
+package postgresql
+
+import (
+	"sync"
+)
+
+// ownerGrantKey identifies the membership of *member* in *role*.
+type ownerGrantKey struct {
+	role   string
+	member string
+}
+
+// ownerGrant is a membership granted for the operations using it,
+// revoked when the last one releases it.
+type ownerGrant struct {
+	sync.Mutex
+	refs    int
+	release func() error
+}
+
+// ownerGrants counts the references to the owner memberships granted to the connecting user,
+// so the concurrent operations of the provider on databases of the same owner (e.g. the
+// databases of a group role created in parallel) grant it once and revoke it once.
+// A nil *ownerGrants grants and revokes the membership for each operation.
+type ownerGrants struct {
+	lock   sync.Mutex
+	grants map[ownerGrantKey]*ownerGrant
+}
+
+func newOwnerGrants() *ownerGrants {
+	return &ownerGrants{grants: map[ownerGrantKey]*ownerGrant{}}
+}
+
+// acquire calls *grant* if *member* is not already granted *role* by another operation,
+// and returns the function releasing the membership: the release function returned by
+// *grant* is called once all the operations released it.
+func (m *ownerGrants) acquire(role, member string, grant func() (func() error, error)) (func() error, error) {
+	if m == nil {
+		return grant()
+	}
+
+	key := ownerGrantKey{role, member}
+	m.lock.Lock()
+	g, ok := m.grants[key]
+	if !ok {
+		g = &ownerGrant{}
+		m.grants[key] = g
+	}
+	m.lock.Unlock()
+
+	// The operations acquiring the membership while it is granted wait for the grant.
+	g.Lock()
+	defer g.Unlock()
+	if g.refs == 0 {
+		release, err := grant()
+		if err != nil {
+			return nil, err
+		}
+		g.release = release
+	}
+	g.refs++
+
+	var once sync.Once
+	return func() (err error) {
+		once.Do(func() {
+			g.Lock()
+			defer g.Unlock()
+			g.refs--
+			if g.refs == 0 {
+				err = g.release()
+				g.release = nil
+			}
+		})
+		return err
+	}, nil
+}
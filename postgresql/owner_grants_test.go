@@ -0,0 +1,157 @@
+package postgresql
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingGrant returns a grant function counting the grants and revokes.
+func countingGrant(grants, revokes *int32) func() (func() error, error) {
+	return func() (func() error, error) {
+		atomic.AddInt32(grants, 1)
+		return func() error {
+			atomic.AddInt32(revokes, 1)
+			return nil
+		}, nil
+	}
+}
+
+func TestOwnerGrantsConcurrent(t *testing.T) {
+	m := newOwnerGrants()
+	var grants, revokes int32
+
+	// The databases of the same owner are created concurrently: the membership
+	// is granted by the first operation and revoked by the last one.
+	const operations = 20
+	var acquired, done sync.WaitGroup
+	acquired.Add(operations)
+	done.Add(operations)
+	hold := make(chan struct{})
+	for i := 0; i < operations; i++ {
+		go func() {
+			defer done.Done()
+			release, err := m.acquire("app_owner", "admin", countingGrant(&grants, &revokes))
+			acquired.Done()
+			if err != nil {
+				t.Errorf("could not acquire the membership: %v", err)
+				return
+			}
+			<-hold
+			if err := release(); err != nil {
+				t.Errorf("could not release the membership: %v", err)
+			}
+		}()
+	}
+	acquired.Wait()
+	if got := atomic.LoadInt32(&revokes); got != 0 {
+		t.Fatalf("the membership has been revoked %d times while it is used", got)
+	}
+	close(hold)
+	done.Wait()
+
+	if grants != 1 || revokes != 1 {
+		t.Errorf("the membership has been granted %d times and revoked %d times, expected once", grants, revokes)
+	}
+
+	// Once released, the membership is granted again by the next operation.
+	release, err := m.acquire("app_owner", "admin", countingGrant(&grants, &revokes))
+	if err != nil {
+		t.Fatalf("could not acquire the membership: %v", err)
+	}
+	if err := release(); err != nil {
+		t.Fatalf("could not release the membership: %v", err)
+	}
+	// Releasing twice does not revoke twice.
+	if err := release(); err != nil {
+		t.Fatalf("could not release the membership: %v", err)
+	}
+	if grants != 2 || revokes != 2 {
+		t.Errorf("the membership has been granted %d times and revoked %d times, expected twice", grants, revokes)
+	}
+}
+
+func TestOwnerGrantsRoles(t *testing.T) {
+	m := newOwnerGrants()
+	var grants, revokes int32
+
+	// The memberships of different roles are counted separately.
+	releaseA, _ := m.acquire("owner_a", "admin", countingGrant(&grants, &revokes))
+	releaseB, _ := m.acquire("owner_b", "admin", countingGrant(&grants, &revokes))
+	if grants != 2 {
+		t.Fatalf("expected 2 grants, got %d", grants)
+	}
+	releaseA()
+	if revokes != 1 {
+		t.Fatalf("expected owner_a to be revoked, got %d revokes", revokes)
+	}
+	releaseB()
+	if revokes != 2 {
+		t.Fatalf("expected owner_b to be revoked, got %d revokes", revokes)
+	}
+}
+
+func TestOwnerGrantsError(t *testing.T) {
+	m := newOwnerGrants()
+	grantErr := errors.New("permission denied")
+
+	if _, err := m.acquire("app_owner", "admin", func() (func() error, error) { return nil, grantErr }); err != grantErr {
+		t.Fatalf("expected the grant error, got %v", err)
+	}
+
+	// The failed grant is not counted: the next operation grants the membership.
+	var grants, revokes int32
+	release, err := m.acquire("app_owner", "admin", countingGrant(&grants, &revokes))
+	if err != nil {
+		t.Fatalf("could not acquire the membership: %v", err)
+	}
+	release()
+	if grants != 1 || revokes != 1 {
+		t.Errorf("the membership has been granted %d times and revoked %d times, expected once", grants, revokes)
+	}
+}
+
+func TestOwnerGrantsNil(t *testing.T) {
+	var m *ownerGrants
+	var grants, revokes int32
+
+	// Without a manager, each operation grants and revokes the membership.
+	for i := 0; i < 2; i++ {
+		release, err := m.acquire("app_owner", "admin", countingGrant(&grants, &revokes))
+		if err != nil {
+			t.Fatalf("could not acquire the membership: %v", err)
+		}
+		release()
+	}
+	if grants != 2 || revokes != 2 {
+		t.Errorf("the membership has been granted %d times and revoked %d times, expected twice", grants, revokes)
+	}
+}
+
+// BenchmarkOwnerGrantsAcquire creates databases of the same owner concurrently,
+// the grant and the creation taking some time like on a server: the grants/op
+// metric is the catalog churn saved by sharing the membership.
+func BenchmarkOwnerGrantsAcquire(b *testing.B) {
+	m := newOwnerGrants()
+	var grants, revokes int32
+	grant := func() (func() error, error) {
+		time.Sleep(100 * time.Microsecond)
+		return countingGrant(&grants, &revokes)()
+	}
+
+	b.SetParallelism(8)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			release, err := m.acquire("app_owner", "admin", grant)
+			if err != nil {
+				b.Error(err)
+				return
+			}
+			time.Sleep(100 * time.Microsecond)
+			release()
+		}
+	})
+	b.ReportMetric(float64(grants)/float64(b.N), "grants/op")
+}
@@ -183,7 +183,28 @@ func Provider() *schema.Provider {
 				Optional:     true,
 				DefaultFunc:  schema.EnvDefaultFunc("PGCONNECT_TIMEOUT", 180),
 				Description:  "Maximum wait for connection, in seconds. Zero or not specified means wait indefinitely.",
-				ValidateFunc: validation.IntAtLeast(-1),
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"tcp_keepalives_idle": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Seconds of inactivity after which the server sends a TCP keepalive. Zero means the system default.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"tcp_keepalives_interval": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Seconds after which an unacknowledged TCP keepalive is retransmitted. Zero means the system default.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"tcp_keepalives_count": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Number of lost TCP keepalives before the connection is considered dead. Zero means the system default.",
+				ValidateFunc: validation.IntAtLeast(0),
 			},
 			"max_connections": {
 				Type:         schema.TypeInt,
@@ -192,6 +213,66 @@ func Provider() *schema.Provider {
 				Description:  "Maximum number of connections to establish to the database. Zero means unlimited.",
 				ValidateFunc: validation.IntAtLeast(-1),
 			},
+			"max_concurrent_operations": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Maximum number of database create, update and delete operations run at the same time. Zero means unlimited.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"default_owner": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "Role used as owner of the databases which do not specify an owner (defaults to the connecting user)",
+			},
+			"default_encoding": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "Encoding of the databases which do not specify an encoding (defaults to UTF8)",
+			},
+			"lock_retry_max": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Maximum number of retries when a role lock is already held. Zero means wait for the lock to be released.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"reassign_via_set_role": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Run REASSIGN OWNED as the previous owner with SET ROLE instead of granting it to the connecting user",
+			},
+			"protect_system_databases": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Prevent the system databases (postgres, template0 and template1) from being dropped",
+			},
+			"pgbouncer_mode": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "The provider connects through PgBouncer in transaction pooling mode",
+			},
+			"pgbouncer_direct_host": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Host of the server behind PgBouncer, connected to directly to create and drop the databases in pgbouncer_mode (defaults to host)",
+			},
+			"pgbouncer_direct_port": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "Port of the server behind PgBouncer, connected to directly to create and drop the databases in pgbouncer_mode (defaults to port)",
+				ValidateFunc: validation.IntBetween(1, 65535),
+			},
+			"sql_log_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Path of a file the executed statements are appended to, with their duration and the passwords redacted",
+			},
 			"expected_version": {
 				Type:         schema.TypeString,
 				Optional:     true,
@@ -217,12 +298,22 @@ func Provider() *schema.Provider {
 			"postgresql_server":                    resourcePostgreSQLServer(),
 			"postgresql_user_mapping":              resourcePostgreSQLUserMapping(),
 			"postgresql_security_label":            resourcePostgreSQLSecurityLabel(),
+			"postgresql_terminate_connections":     resourcePostgreSQLTerminateConnections(),
+			"postgresql_stat_statements_reset":     resourcePostgreSQLStatStatementsReset(),
+			"postgresql_databases":                 resourcePostgreSQLDatabases(),
+			"postgresql_hba_rule":                  resourcePostgreSQLHBARule(),
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"postgresql_schemas":   dataSourcePostgreSQLDatabaseSchemas(),
-			"postgresql_tables":    dataSourcePostgreSQLDatabaseTables(),
-			"postgresql_sequences": dataSourcePostgreSQLDatabaseSequences(),
+			"postgresql_database":               dataSourcePostgreSQLDatabase(),
+			"postgresql_databases":              dataSourcePostgreSQLDatabases(),
+			"postgresql_database_owned_objects": dataSourcePostgreSQLDatabaseOwnedObjects(),
+			"postgresql_locales":                dataSourcePostgreSQLLocales(),
+			"postgresql_role_members":           dataSourcePostgreSQLRoleMembers(),
+			"postgresql_schemas":                dataSourcePostgreSQLDatabaseSchemas(),
+			"postgresql_server_features":        dataSourcePostgreSQLServerFeatures(),
+			"postgresql_tables":                 dataSourcePostgreSQLDatabaseTables(),
+			"postgresql_sequences":              dataSourcePostgreSQLDatabaseSequences(),
 		},
 
 		ConfigureFunc: providerConfigure,
@@ -378,10 +469,23 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		SSLMode:                         sslMode,
 		ApplicationName:                 "Terraform provider",
 		ConnectTimeoutSec:               d.Get("connect_timeout").(int),
+		TCPKeepalivesIdle:               d.Get("tcp_keepalives_idle").(int),
+		TCPKeepalivesInterval:           d.Get("tcp_keepalives_interval").(int),
+		TCPKeepalivesCount:              d.Get("tcp_keepalives_count").(int),
 		MaxConns:                        d.Get("max_connections").(int),
 		ExpectedVersion:                 version,
 		SSLRootCertPath:                 d.Get("sslrootcert").(string),
 		GCPIAMImpersonateServiceAccount: d.Get("gcp_iam_impersonate_service_account").(string),
+		DefaultOwner:                    d.Get("default_owner").(string),
+		DefaultEncoding:                 d.Get("default_encoding").(string),
+		LockRetryMax:                    d.Get("lock_retry_max").(int),
+		ReassignViaSetRole:              d.Get("reassign_via_set_role").(bool),
+		ProtectSystemDatabases:          d.Get("protect_system_databases").(bool),
+		PgBouncerMode:                   d.Get("pgbouncer_mode").(bool),
+		PgBouncerDirectHost:             d.Get("pgbouncer_direct_host").(string),
+		PgBouncerDirectPort:             d.Get("pgbouncer_direct_port").(int),
+		ownerGrants:                     newOwnerGrants(),
+		operations:                      newOperationLimiter(d.Get("max_concurrent_operations").(int)),
 	}
 
 	if value, ok := d.GetOk("clientcert"); ok {
@@ -394,6 +498,14 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		}
 	}
 
+	if path := d.Get("sql_log_file").(string); path != "" {
+		sqlLog, err := openSQLLog(path, password)
+		if err != nil {
+			return nil, err
+		}
+		config.sqlLog = sqlLog
+	}
+
 	if config.Scheme == "gcppostgres" {
 		if err := createGoogleCredsFileIfNeeded(); err != nil {
 			return nil, err
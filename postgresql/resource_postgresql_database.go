@@ -2,12 +2,22 @@ package postgresql
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"log"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/lib/pq"
@@ -17,39 +27,176 @@ const (
 	dbAllowConnsAttr       = "allow_connections"
 	dbCTypeAttr            = "lc_ctype"
 	dbCollationAttr        = "lc_collate"
+	dbLocaleAttr           = "locale"
 	dbConnLimitAttr        = "connection_limit"
+	dbUnlimitedConnsAttr   = "unlimited_connections"
 	dbEncodingAttr         = "encoding"
 	dbIsTemplateAttr       = "is_template"
 	dbNameAttr             = "name"
 	dbOwnerAttr            = "owner"
+	dbReassignOrphanAttr   = "reassign_orphaned_owner"
+	dbCreateOwnerAttr      = "create_owner_if_missing"
+	dbOwnerPasswordAttr    = "owner_password"
+	dbPrivPreflightAttr    = "privilege_preflight"
 	dbTablespaceAttr       = "tablespace_name"
+	dbTablespaceDrainAttr  = "tablespace_drain_connections"
+	dbTablespaceCheckAttr  = "tablespace_check_exists"
+	dbPlacementTbspAttr    = "placement_tablespace"
+	dbDefaultTablespace    = "default_tablespace"
+	dbDefaultRoleAttr      = "default_role"
 	dbTemplateAttr         = "template"
+	dbTemplatesAttr        = "templates"
 	dbAlterObjectOwnership = "alter_object_ownership"
+	dbGrantNewOwnerAttr    = "grant_new_owner_on_existing"
+	dbAllowRecreateAttr    = "allow_destructive_recreate"
+	dbPreventRecreateAttr  = "prevent_recreate"
+	dbWaitIdleTimeoutAttr  = "wait_for_idle_timeout"
+	dbWaitIdleOnAttr       = "wait_for_idle_on"
 	dbColocationAttr       = "colocation"
+	dbColocationEffAttr    = "colocation_effective"
+	dbSearchPathAttr       = "search_path"
+	dbSearchPathModeAttr   = "search_path_mode"
+	dbPublicSchemaCreate   = "public_schema_create"
+	dbRoleConnLimitsAttr   = "role_connection_limits"
+	dbVerifyCreateAttr     = "verify_create"
+	dbCreatedAtAttr        = "created_at"
+	dbCreatedAtTrackerAttr = "created_at_tracker"
+	dbPrototypeAttr        = "prototype"
+	dbBootstrapSQLAttr     = "bootstrap_sql"
+	dbPreDestroySQLAttr    = "pre_destroy_sql"
+	dbPostCreateSQLAttr    = "post_create_sql"
+	dbPostCreateMaintAttr  = "post_create_maintenance"
+	dbPreDeleteCmdAttr     = "pre_delete_command"
+	dbIgnoreBackupFailAttr = "ignore_backup_failure"
+	dbCancelFirstAttr      = "cancel_before_terminate"
+	dbAdoptExistingAttr    = "adopt_existing"
+	dbOIDAttr              = "oid"
+	dbCollVersionMismatch  = "collation_version_mismatch"
+	dbActiveConnsAttr      = "active_connection_count"
+	dbActiveSessionsAttr   = "active_sessions"
+	dbDataChecksumsAttr    = "data_checksums"
+	dbSettingsAttr         = "settings"
+	dbRawSettingsAttr      = "raw_settings"
+	dbEffectiveSetsAttr    = "effective_settings"
+	dbConnectRolesAttr     = "connect_roles"
+	dbTempRolesAttr        = "temp_roles"
+	dbIsSystemAttr         = "is_system_database"
+	dbLogStatementAttr     = "log_statement"
+	dbLogMinDurationAttr   = "log_min_duration_statement"
+	dbSessionPreloadAttr   = "session_preload_libraries"
+	dbStatementTimeoutAttr = "statement_timeout"
+	dbLockTimeoutAttr      = "lock_timeout"
+	dbToastCompressionAttr = "default_toast_compression"
 )
 
+// The modes of search_path_mode.
+const (
+	// dbSearchPathModeExplicit sets search_path, or resets it if empty.
+	dbSearchPathModeExplicit = "explicit"
+	// dbSearchPathModeInherit pins the default search path of the server with SET FROM CURRENT.
+	dbSearchPathModeInherit = "inherit"
+	// dbSearchPathModeReset resets the search path to the built-in default.
+	dbSearchPathModeReset = "reset"
+)
+
+// dbCommandRunner executes the external command of pre_delete_command,
+// it is replaced in the tests.
+var dbCommandRunner = func(ctx context.Context, name string, args []string, env []string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = env
+	return cmd.CombinedOutput()
+}
+
+// systemDatabases are the databases created by initdb.
+var systemDatabases = []string{"postgres", "template0", "template1"}
+
+// dbPrivilegeRolesAttrs maps the attributes listing the roles granted
+// a privilege on the database to this privilege.
+var dbPrivilegeRolesAttrs = map[string]string{
+	dbConnectRolesAttr: "CONNECT",
+	dbTempRolesAttr:    "TEMPORARY",
+}
+
+// dbMemorySettings are the memory parameters which can be set on the database,
+// the attributes are named after them.
+var dbMemorySettings = []string{"maintenance_work_mem", "work_mem", "temp_buffers"}
+
+// durationUnits are the units of a time parameter, in milliseconds (us is handled apart).
+var durationUnits = map[string]int{"ms": 1, "s": 1000, "min": 60 * 1000, "h": 60 * 60 * 1000, "d": 24 * 60 * 60 * 1000}
+
+// durationRegexp matches a PostgreSQL time value, e.g. 500ms.
+var durationRegexp = regexp.MustCompile(`^(-?[0-9]+)\s*(us|ms|s|min|h|d)?$`)
+
+// settingNameRegexp matches a parameter name, custom ones included (e.g. app.tenant).
+// The names are lowercase as PostgreSQL stores them this way.
+var settingNameRegexp = regexp.MustCompile(`^[a-z_][a-z0-9_]*(\.[a-z_][a-z0-9_]*)*$`)
+
+// dbSettingFromCurrent is the value of settings and raw_settings pinning the current value
+// of a parameter with SET FROM CURRENT. Any value read back is then considered matching.
+const dbSettingFromCurrent = "__FROM_CURRENT__"
+
+// memorySizeRegexp matches a PostgreSQL memory size, e.g. 64MB.
+// A value without unit is expressed in the default unit of the parameter.
+var memorySizeRegexp = regexp.MustCompile(`^[0-9]+(kB|MB|GB|TB)?$`)
+
 func resourcePostgreSQLDatabase() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLDatabaseCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLDatabaseRead),
-		Update: PGResourceFunc(resourcePostgreSQLDatabaseUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLDatabaseDelete),
-		Exists: PGResourceExistsFunc(resourcePostgreSQLDatabaseExists),
+		CreateContext: limitOperation(singleOperation, PGResourceDiagFunc(resourcePostgreSQLDatabaseCreate)),
+		ReadContext:   PGResourceDiagFunc(resourcePostgreSQLDatabaseRead),
+		UpdateContext: limitOperation(singleOperation, PGResourceDiagFunc(resourcePostgreSQLDatabaseUpdate)),
+		DeleteContext: limitOperation(singleOperation, PGResourceDiagFunc(resourcePostgreSQLDatabaseDelete)),
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourcePostgreSQLDatabaseImport,
+		},
+		CustomizeDiff: customdiff.All(
+			setDBLocaleDiff,
+			checkDBEncodingLocaleDiff,
+			checkDBDestructiveRecreateDiff,
+			checkDBUnlimitedConnectionsDiff,
+			checkDBSearchPathModeDiff,
+			checkDBTemplateEncodingDiff,
+			checkDBRecreateDiff,
+		),
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Update: schema.DefaultTimeout(20 * time.Minute),
+			Delete: schema.DefaultTimeout(20 * time.Minute),
 		},
 
-		Schema: map[string]*schema.Schema{
+		Schema: dbSchemaWithMemorySettings(map[string]*schema.Schema{
 			dbNameAttr: {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "The PostgreSQL database name to connect to",
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "The PostgreSQL database name to connect to",
+				ValidateFunc: validateDBName,
 			},
 			dbOwnerAttr: {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Computed:    true,
 				Description: "The ROLE which owns the database",
+				// The database of a dropped owner is only reassigned if requested.
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return isOrphanedDBOwner(old) && !d.Get(dbReassignOrphanAttr).(bool)
+				},
+			},
+			dbReassignOrphanAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true and the owner role of the database was dropped outside of Terraform, the database is reassigned to owner",
+			},
+			dbCreateOwnerAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, the owner role is created before the database if it does not exist",
+			},
+			dbOwnerPasswordAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Password of the owner role created by create_owner_if_missing, which can then login",
 			},
 			dbTemplateAttr: {
 				Type:        schema.TypeString,
@@ -58,6 +205,15 @@ func resourcePostgreSQLDatabase() *schema.Resource {
 				Computed:    true,
 				Description: "The name of the template from which to create the new database",
 			},
+			dbTemplatesAttr: {
+				Type:          schema.TypeList,
+				Optional:      true,
+				ForceNew:      true,
+				MinItems:      1,
+				Elem:          &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.NoZeroValues},
+				ConflictsWith: []string{dbTemplateAttr},
+				Description:   "The templates to try in order to create the new database, the first one which exists is used",
+			},
 			dbEncodingAttr: {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -79,12 +235,76 @@ func resourcePostgreSQLDatabase() *schema.Resource {
 				ForceNew:    true,
 				Description: "Character classification (LC_CTYPE) to use in the new database",
 			},
+			dbLocaleAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				Description:  "Sets both lc_collate and lc_ctype of the new database",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			dbPrivPreflightAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "If true, checks that the connecting user can create databases before creating the database",
+			},
 			dbTablespaceAttr: {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Computed:    true,
 				Description: "The name of the tablespace that will be associated with the new database",
 			},
+			dbTablespaceDrainAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, the connections to the database are terminated before changing its tablespace",
+			},
+			dbAdoptExistingAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true and the database already exists, it is adopted as is on creation: only the attributes set in the configuration are applied. Otherwise the creation fails",
+			},
+			dbCancelFirstAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, the running queries are canceled before the connections to the database are terminated, which are then only terminated if still connected",
+			},
+			dbTablespaceCheckAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "If true, check that the tablespace exists before creating the database",
+			},
+			dbDefaultTablespace: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Sets the default_tablespace parameter of the database, where the objects are created when no tablespace is specified",
+			},
+			dbDefaultRoleAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Sets the role parameter of the database, the role the sessions connected to the database assume",
+			},
+			dbWaitIdleTimeoutAttr: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Number of seconds to wait for the active queries on the database to finish before the operations of wait_for_idle_on. Zero disables waiting",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			dbWaitIdleOnAttr: {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice([]string{"rename", "tablespace", "drop"}, false),
+				},
+				Description: "The operations waiting for the database to be idle: rename, tablespace and drop",
+			},
 			dbConnLimitAttr: {
 				Type:         schema.TypeInt,
 				Optional:     true,
@@ -92,6 +312,12 @@ func resourcePostgreSQLDatabase() *schema.Resource {
 				Description:  "How many concurrent connections can be made to this database",
 				ValidateFunc: validation.IntAtLeast(-1),
 			},
+			dbUnlimitedConnsAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether the number of concurrent connections to this database is unlimited (connection_limit = -1)",
+			},
 			dbAllowConnsAttr: {
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -110,547 +336,2942 @@ func resourcePostgreSQLDatabase() *schema.Resource {
 				Default:     false,
 				Description: "If true, the owner of already existing objects will change if the owner changes",
 			},
+			dbGrantNewOwnerAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true and alter_object_ownership is false, the new owner is granted all privileges on the existing objects when the owner changes",
+			},
+			dbPlacementTbspAttr: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{dbTablespaceAttr},
+				Description:   "YugabyteDB only. The name of a tablespace with a placement policy to create the database in",
+			},
 			dbColocationAttr: {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Default:     false,
 				Description: "Specifies whether colocation is enabled for the database",
 			},
-		},
+			dbColocationEffAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the database is actually colocated on the server (YugabyteDB only)",
+			},
+			dbSearchPathAttr: {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				MinItems:    0,
+				Description: "Sets the database's search path",
+			},
+			dbSearchPathModeAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      dbSearchPathModeExplicit,
+				ValidateFunc: validation.StringInSlice([]string{dbSearchPathModeExplicit, dbSearchPathModeInherit, dbSearchPathModeReset}, false),
+				Description:  "How the search path of the database is set: explicit (from search_path), inherit (pins the current default) or reset",
+			},
+			dbPublicSchemaCreate: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "If set, grants (true) or revokes (false) the CREATE privilege on the public schema to PUBLIC",
+			},
+			dbRoleConnLimitsAttr: {
+				Type:         schema.TypeMap,
+				Optional:     true,
+				Elem:         &schema.Schema{Type: schema.TypeInt},
+				Description:  "How many concurrent connections can be made by each role (role name -> limit)",
+				ValidateFunc: validateRoleConnLimits,
+			},
+			dbVerifyCreateAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, fails the creation if the encoding, collation or ctype of the created database differ from the requested ones",
+			},
+			dbAllowRecreateAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, allows to recreate a database containing objects when its encoding, collation or ctype changes",
+			},
+			dbPreventRecreateAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, fails the plan when a change drops and recreates the database",
+			},
+			dbCreatedAtTrackerAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "none",
+				Description:  "How the creation time of the database is recorded: none, comment or table",
+				ValidateFunc: validation.StringInSlice([]string{"none", "comment", "table"}, false),
+			},
+			dbCreatedAtAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The creation time of the database (RFC3339), if recorded",
+			},
+			dbCollVersionMismatch: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "True if the collation version recorded for the database differs from the one of the operating system",
+			},
+			dbEffectiveSetsAttr: {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "All the parameters set for the database, including the ones set outside of Terraform",
+			},
+			dbActiveConnsAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of sessions connected to the database when it was last read",
+			},
+			dbActiveSessionsAttr: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The sessions connected to the database when it was last read, which would prevent dropping it",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"pid": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The process ID of the session",
+						},
+						"usename": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The role of the session",
+						},
+						"application_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The application_name of the session",
+						},
+						"state": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The state of the session, empty if it is not visible to the connecting user",
+						},
+					},
+				},
+			},
+			dbIsSystemAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "True for the system databases: postgres, template0 and template1",
+			},
+			dbOIDAttr: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntAtLeast(16384),
+				Description:  "The object identifier of the database, only settable on creation (PostgreSQL 15+)",
+			},
+			dbDataChecksumsAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether data checksums are enabled on the cluster (data_checksums parameter)",
+			},
+			dbLogStatementAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Sets the log_statement parameter of the database: none, ddl, mod or all",
+				ValidateFunc: validation.StringInSlice([]string{"none", "ddl", "mod", "all"}, false),
+			},
+			dbLogMinDurationAttr: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      -1,
+				Description:  "Sets the log_min_duration_statement parameter of the database, in milliseconds. -1 leaves it unset",
+				ValidateFunc: validation.IntAtLeast(-1),
+			},
+			dbStatementTimeoutAttr: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "Sets the statement_timeout parameter of the database, in milliseconds or with a unit (e.g. 30s)",
+				ValidateFunc:     validateDBDuration,
+				DiffSuppressFunc: suppressEquivalentDBDuration,
+			},
+			dbLockTimeoutAttr: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "Sets the lock_timeout parameter of the database, in milliseconds or with a unit (e.g. 30s)",
+				ValidateFunc:     validateDBDuration,
+				DiffSuppressFunc: suppressEquivalentDBDuration,
+			},
+			dbToastCompressionAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Sets the default_toast_compression parameter of the database: pglz or lz4 (PostgreSQL 14+)",
+				ValidateFunc: validation.StringInSlice([]string{"pglz", "lz4"}, false),
+			},
+			dbSessionPreloadAttr: {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.All(validation.NoZeroValues, validation.StringDoesNotContainAny(",")),
+				},
+				Description: "Sets the session_preload_libraries parameter of the database: the libraries loaded by the sessions of the database",
+			},
+			dbSettingsAttr: {
+				Type:         schema.TypeMap,
+				Optional:     true,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				Description:  "Parameters set on the database (parameter name -> value)",
+				ValidateFunc: validateDBSettings,
+			},
+			dbRawSettingsAttr: {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateDBRawSetting,
+				},
+				Description: "Parameters set on the database, each one formatted as name=value as in pg_database.datconfig",
+			},
+			dbConnectRolesAttr: {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "The roles granted the CONNECT privilege on the database",
+			},
+			dbTempRolesAttr: {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "The roles granted the TEMPORARY privilege on the database",
+			},
+			dbPreDestroySQLAttr: {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The SQL statements to execute in the database before it is dropped",
+			},
+			dbPostCreateSQLAttr: {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The SQL statements to execute in the database after it has been created",
+			},
+			dbPostCreateMaintAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "The maintenance command to run in the database after it has been created: analyze, vacuum or vacuum_analyze",
+				ValidateFunc: validation.StringInSlice([]string{"analyze", "vacuum", "vacuum_analyze"}, false),
+			},
+			dbPreDeleteCmdAttr: {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The command (program and arguments) to execute before the database is dropped, e.g. pg_dump. {host}, {port}, {dbname} and {username} are replaced in each argument",
+			},
+			dbIgnoreBackupFailAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, the database is dropped even if pre_delete_command fails",
+			},
+			dbPrototypeAttr: {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Bootstrap applied inside the database once it has been created",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						dbBootstrapSQLAttr: {
+							Type:        schema.TypeList,
+							Required:    true,
+							MinItems:    1,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The SQL statements to execute in the new database",
+						},
+					},
+				},
+			},
+		}),
 	}
 }
 
-func resourcePostgreSQLDatabaseCreate(db *DBConnection, d *schema.ResourceData) error {
-	if err := createDatabase(db, d); err != nil {
-		return err
+// defaultDBEncoding returns the encoding of the databases created without encoding:
+// the provider default_encoding if set, UTF8 otherwise.
+func defaultDBEncoding(config Config) string {
+	if config.DefaultEncoding != "" {
+		return config.DefaultEncoding
 	}
+	return "UTF8"
+}
 
-	d.SetId(d.Get(dbNameAttr).(string))
+// readMaxConnections returns the max_connections parameter of the server.
+func readMaxConnections(db QueryAble) (int, error) {
+	var maxConnections int
+	if err := db.QueryRow("SELECT pg_catalog.current_setting('max_connections')::integer").Scan(&maxConnections); err != nil {
+		return 0, fmt.Errorf("could not read max_connections: %w", err)
+	}
+	return maxConnections, nil
+}
 
-	return resourcePostgreSQLDatabaseReadImpl(db, d)
+// dbConnLimitDiags returns a warning if the connection limit of the database exceeds
+// the max_connections of the server, which makes the limit meaningless.
+func dbConnLimitDiags(dbName string, connLimit, maxConnections int) diag.Diagnostics {
+	if connLimit <= maxConnections {
+		return nil
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("Connection limit of database %q exceeds max_connections", dbName),
+		Detail: fmt.Sprintf(
+			"%s is %d but the server accepts at most %d connections (max_connections): the limit is never reached.",
+			dbConnLimitAttr, connLimit, maxConnections,
+		),
+		AttributePath: cty.GetAttrPath(dbConnLimitAttr),
+	}}
 }
 
-func createDatabase(db *DBConnection, d *schema.ResourceData) error {
-	currentUser := db.client.config.getDatabaseUsername()
-	owner := d.Get(dbOwnerAttr).(string)
+// dbOrphanedOwnerDiags returns a warning for a database whose owner role was dropped
+// outside of Terraform: only a superuser can then change its owner.
+func dbOrphanedOwnerDiags(dbName, owner string) diag.Diagnostics {
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("Owner of database %q does not exist", dbName),
+		Detail: fmt.Sprintf(
+			"The owner role of the database was dropped outside of Terraform, it is read as %q. "+
+				"Set %s and %s to reassign the database as a superuser, "+
+				"or run ALTER DATABASE %s OWNER TO the new owner.",
+			owner, dbOwnerAttr, dbReassignOrphanAttr, pq.QuoteIdentifier(dbName),
+		),
+		AttributePath: cty.GetAttrPath(dbOwnerAttr),
+	}}
+}
 
-	var err error
-	if owner != "" {
-		// Take a lock on db currentUser to avoid multiple database creation at the same time
-		// It can fail if they grant the same owner to current at the same time as it's not done in transaction.
-		lockTxn, err := startTransaction(db.client, "")
+// validateDBName warns when the name contains uppercase letters: the name is always
+// quoted, so it is case-sensitive, while unquoted in SQL it would be folded to lowercase.
+func validateDBName(v interface{}, key string) (warnings []string, errors []error) {
+	name := v.(string)
+	if folded := strings.ToLower(name); folded != name {
+		warnings = append(warnings, fmt.Sprintf(
+			"%s: database names are case-sensitive, %q is a different database than %q, "+
+				"which is the one an unquoted %s refers to in SQL",
+			key, name, folded, name,
+		))
+	}
+	return
+}
+
+// dbSchemaWithMemorySettings adds the attributes of the memory parameters to the schema.
+func dbSchemaWithMemorySettings(s map[string]*schema.Schema) map[string]*schema.Schema {
+	for _, name := range dbMemorySettings {
+		s[name] = &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  fmt.Sprintf("Sets the %s parameter of the database (e.g. 64MB)", name),
+			ValidateFunc: validation.StringMatch(memorySizeRegexp, "must be a memory size, e.g. 64MB or 1GB"),
+		}
+	}
+	return s
+}
+
+func resourcePostgreSQLDatabaseCreate(db *DBConnection, d *schema.ResourceData) diag.Diagnostics {
+	if d.Get(dbAdoptExistingAttr).(bool) {
+		exists, err := dbExists(db, d.Get(dbNameAttr).(string))
 		if err != nil {
-			return err
+			return diag.FromErr(err)
 		}
-		if err := pgLockRole(lockTxn, currentUser); err != nil {
-			return err
+		if exists {
+			return adoptExistingDatabase(db, d)
 		}
-		defer deferredRollback(lockTxn)
+	}
 
-		// Needed in order to set the owner of the db if the connection user is not a
-		// superuser
-		ownerGranted, err := grantRoleMembership(db, owner, currentUser)
-		if err != nil {
-			return err
+	if err := createDatabase(db, d); err != nil {
+		if !isPQErrorCode(err, pqErrorCodeDuplicateDatabase) {
+			return diag.FromErr(err)
 		}
-		if ownerGranted {
-			defer func() {
-				_, err = revokeRoleMembership(db, owner, currentUser)
-			}()
+		// adopt_existing is the only way to take over a database, which may also
+		// have been created since it was checked.
+		if d.Get(dbAdoptExistingAttr).(bool) {
+			return adoptExistingDatabase(db, d)
 		}
+		return diag.FromErr(fmt.Errorf("%w (set %s or import the database to manage it)", err, dbAdoptExistingAttr))
 	}
 
-	dbName := d.Get(dbNameAttr).(string)
-	b := bytes.NewBufferString("CREATE DATABASE ")
-	fmt.Fprint(b, pq.QuoteIdentifier(dbName))
+	d.SetId(d.Get(dbNameAttr).(string))
 
-	if v, ok := d.GetOk(dbColocationAttr); ok && v.(bool) {
-		fmt.Fprint(b, " WITH COLOCATION = true")
+	if tracker, ok := dbCreatedAtTrackers[d.Get(dbCreatedAtTrackerAttr).(string)]; ok {
+		if err := tracker.record(db, d.Id(), time.Now().UTC()); err != nil {
+			return diag.FromErr(fmt.Errorf("Error recording creation time of database %q: %w", d.Id(), err))
+		}
 	}
 
-	// Handle each option individually and stream results into the query
-	// buffer.
-	switch v, ok := d.GetOk(dbOwnerAttr); {
-	case ok:
-		fmt.Fprint(b, " OWNER ", pq.QuoteIdentifier(v.(string)))
-	default:
-		// No owner specified in the config, default to using
-		// the connecting username.
-		fmt.Fprint(b, " OWNER ", pq.QuoteIdentifier(currentUser))
+	// The ID is already set so if the verification fails,
+	// the database will be tainted and recreated on the next apply.
+	if d.Get(dbVerifyCreateAttr).(bool) {
+		if err := verifyCreatedDatabase(db, d); err != nil {
+			return diag.FromErr(err)
+		}
 	}
 
-	switch v, ok := d.GetOk(dbTemplateAttr); {
-	case ok && strings.ToUpper(v.(string)) == "DEFAULT":
-		fmt.Fprint(b, " TEMPLATE DEFAULT")
-	case ok:
-		fmt.Fprint(b, " TEMPLATE ", pq.QuoteIdentifier(v.(string)))
-	case v.(string) == "":
-		fmt.Fprint(b, " TEMPLATE template0")
+	if len(d.Get(dbSearchPathAttr).([]interface{})) > 0 || d.Get(dbSearchPathModeAttr).(string) != dbSearchPathModeExplicit {
+		if err := doSetDBSearchPath(db, d); err != nil {
+			return diag.FromErr(err)
+		}
 	}
 
-	switch v, ok := d.GetOk(dbEncodingAttr); {
-	case ok && strings.ToUpper(v.(string)) == "DEFAULT":
-		fmt.Fprintf(b, " ENCODING DEFAULT")
-	case ok:
-		fmt.Fprintf(b, " ENCODING '%s' ", pqQuoteLiteral(v.(string)))
-	case v.(string) == "":
-		fmt.Fprint(b, ` ENCODING 'UTF8'`)
+	if err := doSetDBPublicSchemaCreate(db, d); err != nil {
+		return diag.FromErr(err)
 	}
 
-	// Don't specify LC_COLLATE if user didn't specify it
-	// This will use the default one (usually the one defined in the template database)
-	switch v, ok := d.GetOk(dbCollationAttr); {
-	case ok && strings.ToUpper(v.(string)) == "DEFAULT":
-		fmt.Fprintf(b, " LC_COLLATE DEFAULT")
-	case ok:
-		fmt.Fprintf(b, " LC_COLLATE '%s' ", pqQuoteLiteral(v.(string)))
+	if err := setDBRoleConnLimits(db, d); err != nil {
+		return diag.FromErr(err)
 	}
 
-	// Don't specify LC_CTYPE if user didn't specify it
-	// This will use the default one (usually the one defined in the template database)
-	switch v, ok := d.GetOk(dbCTypeAttr); {
-	case ok && strings.ToUpper(v.(string)) == "DEFAULT":
-		fmt.Fprintf(b, " LC_CTYPE DEFAULT")
-	case ok:
-		fmt.Fprintf(b, " LC_CTYPE '%s' ", pqQuoteLiteral(v.(string)))
+	if err := setDBDefaultRole(db, d); err != nil {
+		return diag.FromErr(err)
 	}
 
-	switch v, ok := d.GetOk(dbTablespaceAttr); {
-	case ok && strings.ToUpper(v.(string)) == "DEFAULT":
-		fmt.Fprint(b, " TABLESPACE DEFAULT")
-	case ok:
-		fmt.Fprint(b, " TABLESPACE ", pq.QuoteIdentifier(v.(string)))
+	if err := reconcileDBSettings(db, d); err != nil {
+		return diag.FromErr(err)
 	}
 
-	if db.featureSupported(featureDBAllowConnections) {
-		val := d.Get(dbAllowConnsAttr).(bool)
-		fmt.Fprint(b, " ALLOW_CONNECTIONS ", val)
+	if err := setDBPrivilegeRoles(db, d); err != nil {
+		return diag.FromErr(err)
 	}
 
-	{
-		val := d.Get(dbConnLimitAttr).(int)
-		fmt.Fprint(b, " CONNECTION LIMIT ", val)
+	if err := applyDBPrototype(db, d); err != nil {
+		return diag.FromErr(err)
 	}
 
-	if db.featureSupported(featureDBIsTemplate) {
-		val := d.Get(dbIsTemplateAttr).(bool)
-		fmt.Fprint(b, " IS_TEMPLATE ", val)
+	if err := execDBHookSQL(db, d, dbPostCreateSQLAttr); err != nil {
+		return diag.FromErr(err)
 	}
 
-	sql := b.String()
-	if _, err := db.Exec(sql); err != nil {
-		return fmt.Errorf("Error creating database %q: %w", dbName, err)
+	if err := execDBMaintenance(db, d); err != nil {
+		return diag.FromErr(err)
 	}
 
-	// Set err outside of the return so that the deferred revoke can override err
-	// if necessary.
-	return err
+	return resourcePostgreSQLDatabaseReadImpl(db, d)
 }
 
-func resourcePostgreSQLDatabaseDelete(db *DBConnection, d *schema.ResourceData) error {
-	currentUser := db.client.config.getDatabaseUsername()
-	owner := d.Get(dbOwnerAttr).(string)
+// applyDBPrototype executes the bootstrap statements of the prototype block
+// in the newly created database, all of them or none are applied.
+func applyDBPrototype(db *DBConnection, d *schema.ResourceData) error {
+	prototypes := d.Get(dbPrototypeAttr).([]interface{})
+	if len(prototypes) == 0 || prototypes[0] == nil {
+		return nil
+	}
+	prototype := prototypes[0].(map[string]interface{})
+	dbName := d.Get(dbNameAttr).(string)
 
-	var dropWithForce string
-	var err error
-	if owner != "" {
-		lockTxn, err := startTransaction(db.client, "")
-		if err := pgLockRole(lockTxn, currentUser); err != nil {
-			return err
-		}
-		defer deferredRollback(lockTxn)
+	txn, err := startTransaction(db.client, dbName)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
 
-		// Needed in order to set the owner of the db if the connection user is not a
-		// superuser
-		ownerGranted, err := grantRoleMembership(db, owner, currentUser)
-		if err != nil {
-			return err
-		}
-		if ownerGranted {
-			defer func() {
-				_, err = revokeRoleMembership(db, owner, currentUser)
-			}()
+	for i, stmt := range prototype[dbBootstrapSQLAttr].([]interface{}) {
+		if _, err := txn.Exec(stmt.(string)); err != nil {
+			return fmt.Errorf("Error executing bootstrap statement %d in database %q: %w", i, dbName, err)
 		}
 	}
 
-	dbName := d.Get(dbNameAttr).(string)
-	if db.featureSupported(featureDBIsTemplate) {
-		if isTemplate := d.Get(dbIsTemplateAttr).(bool); isTemplate {
-			// Template databases must have this attribute cleared before
-			// they can be dropped.
-			if err := doSetDBIsTemplate(db, dbName, false); err != nil {
-				return fmt.Errorf("Error updating database IS_TEMPLATE during DROP DATABASE: %w", err)
-			}
-		}
+	if err := txn.Commit(); err != nil {
+		return fmt.Errorf("Error committing bootstrap of database %q: %w", dbName, err)
 	}
 
-	if err := setDBIsTemplate(db, d); err != nil {
+	return nil
+}
+
+// execDBHookSQL executes the statements of the *hook* attribute (pre_destroy_sql
+// or post_create_sql) in the database, in a single transaction.
+func execDBHookSQL(db *DBConnection, d *schema.ResourceData, hook string) error {
+	stmts := d.Get(hook).([]interface{})
+	if len(stmts) == 0 {
+		return nil
+	}
+	dbName := d.Get(dbNameAttr).(string)
+
+	txn, err := startTransaction(db.client, dbName)
+	if err != nil {
 		return err
 	}
+	defer deferredRollback(txn)
 
-	// Terminate all active connections and block new one
-	if err := terminateBConnections(db, dbName); err != nil {
+	for i, stmt := range stmts {
+		if _, err := txn.Exec(stmt.(string)); err != nil {
+			return fmt.Errorf("Error executing %s statement %d in database %q: %w", hook, i, dbName, err)
+		}
+	}
+
+	if err := txn.Commit(); err != nil {
+		return fmt.Errorf("Error committing %s in database %q: %w", hook, dbName, err)
+	}
+
+	return nil
+}
+
+// dbMaintenanceCommands maps the values of post_create_maintenance to their command.
+var dbMaintenanceCommands = map[string]string{
+	"analyze":        "ANALYZE",
+	"vacuum":         "VACUUM",
+	"vacuum_analyze": "VACUUM ANALYZE",
+}
+
+// execDBMaintenance runs the post_create_maintenance command in the database.
+// VACUUM cannot run in a transaction block, so it is executed on a plain connection,
+// and canceled on the server if the context of the operation is done.
+func execDBMaintenance(db *DBConnection, d *schema.ResourceData) error {
+	command, ok := dbMaintenanceCommands[d.Get(dbPostCreateMaintAttr).(string)]
+	if !ok {
+		return nil
+	}
+	dbName := d.Get(dbNameAttr).(string)
+
+	conn, err := db.client.config.NewClient(dbName).Connect()
+	if err != nil {
+		return err
+	}
+	// The maintenance commands run for long on a session of their own, they bypass PgBouncer.
+	ddlDB, err := conn.WithContext(db.client.context()).directConnection()
+	if err != nil {
 		return err
 	}
 
-	// Drop with force only for psql 13+
-	if db.featureSupported(featureForceDropDatabase) {
-		dropWithForce = "WITH ( FORCE )"
+	log.Printf("[INFO] running %s in PostgreSQL database (%q)", command, dbName)
+	if _, err := ddlDB.Exec(command); err != nil {
+		return fmt.Errorf("Error running %s in database %q: %w", command, dbName, err)
 	}
+	return nil
+}
 
-	sql := fmt.Sprintf("DROP DATABASE %s %s", pq.QuoteIdentifier(dbName), dropWithForce)
-	if _, err := db.Exec(sql); err != nil {
-		return fmt.Errorf("Error dropping database: %w", err)
+// expandDBCommand replaces the placeholders of each argument of *command* with *values*.
+// The arguments are never split or interpreted by a shell, so a value cannot inject arguments.
+func expandDBCommand(command []string, values map[string]string) []string {
+	pairs := make([]string, 0, 2*len(values))
+	for name, value := range values {
+		pairs = append(pairs, "{"+name+"}", value)
 	}
+	replacer := strings.NewReplacer(pairs...)
 
-	d.SetId("")
+	expanded := make([]string, len(command))
+	for i, arg := range command {
+		expanded[i] = replacer.Replace(arg)
+	}
+	return expanded
+}
+
+// runPreDeleteCommand executes pre_delete_command, e.g. a pg_dump of the database,
+// before it is dropped. The password is passed in PGPASSWORD rather than in the arguments.
+// If the command fails, the drop is aborted unless ignore_backup_failure is set.
+func runPreDeleteCommand(db *DBConnection, d *schema.ResourceData) error {
+	raw := d.Get(dbPreDeleteCmdAttr).([]interface{})
+	if len(raw) == 0 {
+		return nil
+	}
+	dbName := d.Get(dbNameAttr).(string)
+	config := db.client.config
+
+	command := make([]string, len(raw))
+	for i, arg := range raw {
+		command[i], _ = arg.(string)
+	}
+	command = expandDBCommand(command, map[string]string{
+		"host":     config.Host,
+		"port":     strconv.Itoa(config.Port),
+		"dbname":   dbName,
+		"username": config.Username,
+	})
+
+	env := os.Environ()
+	if config.Password != "" {
+		env = append(env, "PGPASSWORD="+config.Password)
+	}
+
+	log.Printf("[INFO] executing %s %q before dropping PostgreSQL database (%q)", dbPreDeleteCmdAttr, command[0], dbName)
+	output, err := dbCommandRunner(db.client.context(), command[0], command[1:], env)
+	if err == nil {
+		return nil
+	}
 
-	// Returning err even if it's nil so defer func can modify it.
+	err = fmt.Errorf("Error executing %s before dropping database %q: %w: %s", dbPreDeleteCmdAttr, dbName, err, strings.TrimSpace(string(output)))
+	if d.Get(dbIgnoreBackupFailAttr).(bool) {
+		log.Printf("[WARN] %v, dropping the database as %s is set", err, dbIgnoreBackupFailAttr)
+		return nil
+	}
 	return err
 }
 
-func resourcePostgreSQLDatabaseExists(db *DBConnection, d *schema.ResourceData) (bool, error) {
+// checkCreateDBPrivilege returns a clear error if the connecting user cannot create
+// databases, instead of the one returned by CREATE DATABASE. pg_roles is read rather
+// than pg_authid, which is only readable by superusers.
+func checkCreateDBPrivilege(db QueryAble) error {
+	var user string
+	var canCreateDB bool
+	err := db.QueryRow(
+		"SELECT rolname, rolcreatedb OR rolsuper FROM pg_catalog.pg_roles WHERE rolname = CURRENT_USER",
+	).Scan(&user, &canCreateDB)
+	if err != nil {
+		return fmt.Errorf("could not check if the connecting user can create databases: %w", err)
+	}
+	if !canCreateDB {
+		return fmt.Errorf(
+			"role %q cannot create databases: it needs the CREATEDB privilege (ALTER ROLE %s CREATEDB) or to be a superuser "+
+				"(set %s to false to skip this check)",
+			user, pq.QuoteIdentifier(user), dbPrivPreflightAttr,
+		)
+	}
+	return nil
+}
+
+// checkDBTablespaceExists returns a clear error if the tablespace of the database
+// does not exist, instead of the one returned by CREATE DATABASE.
+func checkDBTablespaceExists(db QueryAble, d *schema.ResourceData) error {
+	if !d.Get(dbTablespaceCheckAttr).(bool) {
+		return nil
+	}
+
+	tbspName, ok := d.GetOk(dbTablespaceAttr)
+	if !ok || strings.ToUpper(tbspName.(string)) == "DEFAULT" {
+		return nil
+	}
+
+	exists, err := tablespaceExists(db, tbspName.(string))
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf(
+			"tablespace %q does not exist: it has to be created before the database, "+
+				"e.g. with a postgresql_tablespace resource referenced in %s "+
+				"(set %s to false if it is created outside of Terraform during the apply)",
+			tbspName, dbTablespaceAttr, dbTablespaceCheckAttr,
+		)
+	}
+	return nil
+}
+
+// checkDBPlacementTablespace checks that the placement tablespace of the database
+// exists and has a YugabyteDB placement policy (the replica_placement option).
+func checkDBPlacementTablespace(db QueryAble, d *schema.ResourceData) error {
+	tbspName, ok := d.GetOk(dbPlacementTbspAttr)
+	if !ok {
+		return nil
+	}
+
+	var hasPlacement bool
+	err := db.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM unnest(spcoptions) AS o WHERE o LIKE 'replica_placement=%') "+
+			"FROM pg_catalog.pg_tablespace WHERE spcname = $1",
+		tbspName,
+	).Scan(&hasPlacement)
+	switch {
+	case err == sql.ErrNoRows:
+		return fmt.Errorf("placement tablespace %q does not exist", tbspName)
+	case err != nil:
+		return fmt.Errorf("could not read placement policy of tablespace %q: %w", tbspName, err)
+	case !hasPlacement:
+		return fmt.Errorf(
+			"tablespace %q has no placement policy: it must be created WITH (replica_placement = '...') to be used as %s",
+			tbspName, dbPlacementTbspAttr,
+		)
+	}
+	return nil
+}
+
+// createDBOwnerIfMissing creates the *owner* role if it does not exist yet.
+// The role is not managed by the resource: it is kept when the database is dropped.
+func createDBOwnerIfMissing(db *DBConnection, owner, password string) error {
 	txn, err := startTransaction(db.client, "")
 	if err != nil {
-		return false, err
+		return err
 	}
 	defer deferredRollback(txn)
 
-	return dbExists(txn, d.Id())
+	exists, err := roleExists(txn, owner)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	sql := fmt.Sprintf("CREATE ROLE %s", pq.QuoteIdentifier(owner))
+	if password != "" {
+		sql += fmt.Sprintf(" WITH LOGIN PASSWORD '%s'", pqQuoteLiteral(password))
+	}
+	if _, err := txn.Exec(sql); err != nil {
+		return fmt.Errorf("Error creating owner role %s: %w", owner, err)
+	}
+
+	if err := txn.Commit(); err != nil {
+		return fmt.Errorf("error committing owner role creation: %w", err)
+	}
+	log.Printf("[INFO] Owner role %s created for the database", owner)
+
+	return nil
 }
 
-func resourcePostgreSQLDatabaseRead(db *DBConnection, d *schema.ResourceData) error {
-	return resourcePostgreSQLDatabaseReadImpl(db, d)
+func createDatabase(db *DBConnection, d *schema.ResourceData) error {
+	currentUser := db.client.config.getDatabaseUsername()
+	owner := d.Get(dbOwnerAttr).(string)
+	if owner == "" {
+		// Fallback on the provider default owner if any,
+		// the connecting user is used otherwise.
+		owner = db.client.config.DefaultOwner
+	}
+
+	if d.Get(dbPrivPreflightAttr).(bool) {
+		if err := checkCreateDBPrivilege(db); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := d.GetOk(dbOIDAttr); ok && !db.featureSupported(featureDatabaseOID) {
+		return fmt.Errorf(
+			"setting the oid of the database requires PostgreSQL 15 or YugabyteDB 2.25 and later (server version %s)",
+			db.version,
+		)
+	}
+
+	if err := checkDBTablespaceExists(db, d); err != nil {
+		return err
+	}
+	if err := checkDBPlacementTablespace(db, d); err != nil {
+		return err
+	}
+
+	if d.Get(dbCreateOwnerAttr).(bool) && owner != "" && owner != currentUser {
+		if err := createDBOwnerIfMissing(db, owner, d.Get(dbOwnerPasswordAttr).(string)); err != nil {
+			return err
+		}
+	}
+
+	dbName := d.Get(dbNameAttr).(string)
+
+	// The version chooses the syntax of the colocation and whether the connection limit is set.
+	pgVersion, err := db.serverVersion()
+	if err != nil {
+		return err
+	}
+
+	// CREATE DATABASE cannot run in a transaction, so the owner is granted to the
+	// connecting user (if it is not a superuser) on its own while the role is locked,
+	// and revoked once the database is created, even if the creation failed.
+	return withOwnerMembership(db, owner, func() error {
+		createOwner := owner
+		if createOwner == "" {
+			// No owner specified in the config nor in the provider,
+			// default to using the connecting username.
+			createOwner = currentUser
+		}
+
+		ddlDB, err := db.directConnection()
+		if err != nil {
+			return err
+		}
+		if err := execCreateDatabase(ddlDB, d, createOwner, pgVersion); err != nil {
+			return fmt.Errorf("Error creating database %q: %w", dbName, err)
+		}
+		return nil
+	})
+}
+
+// execCreateDatabase creates the database. If templates is set, each template
+// is tried in order until one exists, and the one used is recorded in template.
+func execCreateDatabase(db *DBConnection, d *schema.ResourceData, owner, pgVersion string) error {
+	templates := d.Get(dbTemplatesAttr).([]interface{})
+	if len(templates) == 0 {
+		_, err := db.Exec(createDatabaseQuery(db, d, owner, pgVersion))
+		return err
+	}
+
+	dbName := d.Get(dbNameAttr).(string)
+	for i, template := range templates {
+		d.Set(dbTemplateAttr, template.(string))
+		_, err := db.Exec(createDatabaseQuery(db, d, owner, pgVersion))
+		switch {
+		case err == nil:
+			log.Printf("[INFO] PostgreSQL database (%q) created from template %q", dbName, template)
+			return nil
+		case !isPQErrorCode(err, pqErrorCodeUndefinedDatabase) || i == len(templates)-1:
+			return err
+		}
+		log.Printf("[WARN] template %q of PostgreSQL database (%q) does not exist, trying the next one", template, dbName)
+	}
+	return nil
+}
+
+// createDatabaseQuery returns the CREATE DATABASE statement of the resource,
+// the database being owned by *owner*. *pgVersion* is the output of `SELECT VERSION()`,
+// used to choose the syntax of the colocation on YugabyteDB.
+func createDatabaseQuery(db *DBConnection, d *schema.ResourceData, owner, pgVersion string) string {
+	dbName := d.Get(dbNameAttr).(string)
+	colocated := d.Get(dbColocationAttr).(bool)
+	colocationParens := colocated && ybFeatureSupportedBy(featureColocationParenSyntax, pgVersion)
+
+	// The options follow a single WITH, COLOCATION is emitted last. With the
+	// parenthesized syntax, the WITH introduces the colocation instead.
+	b := bytes.NewBufferString("CREATE DATABASE ")
+	fmt.Fprint(b, pq.QuoteIdentifier(dbName))
+	if !colocationParens {
+		fmt.Fprint(b, " WITH")
+	}
+
+	// Handle each option individually and stream results into the query
+	// buffer.
+	fmt.Fprint(b, " OWNER ", pq.QuoteIdentifier(owner))
+
+	switch v, ok := d.GetOk(dbTemplateAttr); {
+	case ok && strings.ToUpper(v.(string)) == "DEFAULT":
+		fmt.Fprint(b, " TEMPLATE DEFAULT")
+	case ok:
+		fmt.Fprint(b, " TEMPLATE ", pq.QuoteIdentifier(v.(string)))
+	case v.(string) == "" && !colocated:
+		// Some YugabyteDB versions refuse to create a colocated database from template0,
+		// the server default template is used for them.
+		fmt.Fprint(b, " TEMPLATE template0")
+	}
+
+	switch v, ok := d.GetOk(dbEncodingAttr); {
+	case ok && strings.ToUpper(v.(string)) == "DEFAULT":
+		fmt.Fprintf(b, " ENCODING DEFAULT")
+	case ok:
+		fmt.Fprintf(b, " ENCODING '%s' ", pqQuoteLiteral(v.(string)))
+	case v.(string) == "":
+		fmt.Fprintf(b, " ENCODING '%s' ", pqQuoteLiteral(defaultDBEncoding(db.client.config)))
+	}
+
+	// Don't specify LC_COLLATE if user didn't specify it
+	// This will use the default one (usually the one defined in the template database)
+	switch v, ok := d.GetOk(dbCollationAttr); {
+	case ok && strings.ToUpper(v.(string)) == "DEFAULT":
+		fmt.Fprintf(b, " LC_COLLATE DEFAULT")
+	case ok:
+		fmt.Fprintf(b, " LC_COLLATE '%s' ", pqQuoteLiteral(v.(string)))
+	}
+
+	// Don't specify LC_CTYPE if user didn't specify it
+	// This will use the default one (usually the one defined in the template database)
+	switch v, ok := d.GetOk(dbCTypeAttr); {
+	case ok && strings.ToUpper(v.(string)) == "DEFAULT":
+		fmt.Fprintf(b, " LC_CTYPE DEFAULT")
+	case ok:
+		fmt.Fprintf(b, " LC_CTYPE '%s' ", pqQuoteLiteral(v.(string)))
+	}
+
+	if v, ok := d.GetOk(dbPlacementTbspAttr); ok {
+		fmt.Fprint(b, " TABLESPACE ", pq.QuoteIdentifier(v.(string)))
+	}
+
+	switch v, ok := d.GetOk(dbTablespaceAttr); {
+	case ok && strings.ToUpper(v.(string)) == "DEFAULT":
+		fmt.Fprint(b, " TABLESPACE DEFAULT")
+	case ok:
+		fmt.Fprint(b, " TABLESPACE ", pq.QuoteIdentifier(v.(string)))
+	}
+
+	if db.featureSupported(featureDBAllowConnections) {
+		val := d.Get(dbAllowConnsAttr).(bool)
+		fmt.Fprint(b, " ALLOW_CONNECTIONS ", val)
+	}
+
+	if val := d.Get(dbConnLimitAttr).(int); connLimitSupportedBy(pgVersion) {
+		fmt.Fprint(b, " CONNECTION LIMIT ", val)
+	} else if val != -1 {
+		log.Printf("[WARN] the connection limit of PostgreSQL database (%q) is not set, the server does not enforce it: %s", dbName, pgVersion)
+	}
+
+	// IS_TEMPLATE is only set if configured, the server default (false) applies otherwise.
+	if val, ok := d.GetOkExists(dbIsTemplateAttr); ok && db.featureSupported(featureDBIsTemplate) { //nolint:staticcheck
+		fmt.Fprint(b, " IS_TEMPLATE ", val.(bool))
+	}
+
+	if v, ok := d.GetOk(dbOIDAttr); ok {
+		fmt.Fprint(b, " OID ", v.(int))
+	}
+
+	switch {
+	case colocationParens:
+		fmt.Fprint(b, " WITH (COLOCATION = true)")
+	case colocated:
+		fmt.Fprint(b, " COLOCATION = true")
+	}
+
+	return b.String()
+}
+
+// verifyCreatedDatabase checks that the server honored the encoding, collation and ctype
+// explicitly requested, e.g. they could differ if they are forced by the template.
+func verifyCreatedDatabase(db *DBConnection, d *schema.ResourceData) error {
+	dbName := d.Get(dbNameAttr).(string)
+
+	var encoding, collation, ctype string
+	err := db.QueryRow(
+		"SELECT pg_catalog.pg_encoding_to_char(d.encoding), d.datcollate, d.datctype FROM pg_catalog.pg_database AS d WHERE d.datname = $1",
+		dbName,
+	).Scan(&encoding, &collation, &ctype)
+	if err != nil {
+		return fmt.Errorf("Error verifying database %q: %w", dbName, err)
+	}
+
+	requestedEncoding := d.Get(dbEncodingAttr).(string)
+	if requestedEncoding == "" {
+		requestedEncoding = defaultDBEncoding(db.client.config)
+	}
+
+	checks := []struct {
+		attr      string
+		requested string
+		actual    string
+	}{
+		{dbEncodingAttr, requestedEncoding, encoding},
+		{dbCollationAttr, d.Get(dbCollationAttr).(string), collation},
+		{dbCTypeAttr, d.Get(dbCTypeAttr).(string), ctype},
+	}
+	for _, c := range checks {
+		// Nothing to verify if the value comes from the template.
+		if c.requested == "" || strings.ToUpper(c.requested) == "DEFAULT" {
+			continue
+		}
+		if !strings.EqualFold(c.requested, c.actual) {
+			return fmt.Errorf(
+				"database %q has been created with %s %q instead of the requested %q",
+				dbName, c.attr, c.actual, c.requested,
+			)
+		}
+	}
+
+	return nil
+}
+
+func isSystemDatabase(dbName string) bool {
+	for _, name := range systemDatabases {
+		if dbName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func resourcePostgreSQLDatabaseDelete(db *DBConnection, d *schema.ResourceData) (diags diag.Diagnostics) {
+	dbName := d.Get(dbNameAttr).(string)
+	if err := checkDBDroppable(db, dbName); err != nil {
+		return diag.Errorf("%v, remove it from the state instead (terraform state rm)", err)
+	}
+
+	// A database cannot be dropped from a connection to itself.
+	db, err := maintenanceDBConnection(db, dbName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// The backup is taken first, before the database is modified (is_template cleared,
+	// pre_destroy_sql executed): if it fails, the database is left untouched.
+	if err := runPreDeleteCommand(db, d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	currentUser := db.client.config.getDatabaseUsername()
+	owner := d.Get(dbOwnerAttr).(string)
+
+	if ownerMembershipNeeded(owner, currentUser) {
+		lockTxn, err := startTransaction(db.client, "")
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err := db.lockRole(lockTxn, currentUser); err != nil {
+			return diag.FromErr(err)
+		}
+		defer deferredRollback(lockTxn)
+
+		// Needed in order to set the owner of the db if the connection user is not a
+		// superuser
+		ownerGranted, err := grantRoleMembership(db, owner, currentUser)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if ownerGranted {
+			defer func() {
+				if _, err := revokeRoleMembership(db, owner, currentUser); err != nil {
+					diags = append(diags, diag.FromErr(err)...)
+				}
+			}()
+		}
+	}
+
+	if err := clearDBIsTemplate(db, dbName); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Executed before waiting for the database to be idle,
+	// its connections are terminated before the drop.
+	if err := execDBHookSQL(db, d, dbPreDestroySQLAttr); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := waitForDBIdleBefore(db, d, "drop", dbName); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Terminate all active connections and block new one
+	if err := terminateBConnections(db, dbName, d.Get(dbCancelFirstAttr).(bool)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Reset the connection limits of the roles managed by this database.
+	for role := range d.Get(dbRoleConnLimitsAttr).(map[string]interface{}) {
+		if err := resetRoleConnLimit(db, role); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if tracker, ok := dbCreatedAtTrackers[d.Get(dbCreatedAtTrackerAttr).(string)]; ok {
+		if err := tracker.forget(db, dbName); err != nil {
+			return diag.FromErr(fmt.Errorf("Error removing creation time of database %q: %w", dbName, err))
+		}
+	}
+
+	if err := dropDatabase(db, dbName, " or set wait_for_idle_on"); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// checkDBDroppable returns an error if *dbName* is a system database
+// protected by protect_system_databases.
+func checkDBDroppable(db *DBConnection, dbName string) error {
+	if db.client.config.ProtectSystemDatabases && isSystemDatabase(dbName) {
+		return fmt.Errorf("database %q is a system database and cannot be dropped while protect_system_databases is enabled", dbName)
+	}
+	return nil
+}
+
+// clearDBIsTemplate clears IS_TEMPLATE on *dbName*: template databases must have it
+// cleared before they can be dropped. The catalog is checked instead of the state
+// as the flag could have been changed outside of Terraform.
+func clearDBIsTemplate(db *DBConnection, dbName string) error {
+	if !db.featureSupported(featureDBIsTemplate) {
+		return nil
+	}
+
+	isTemplate, err := getDBIsTemplate(db, dbName)
+	if err != nil {
+		return err
+	}
+	if !isTemplate {
+		return nil
+	}
+	if err := doSetDBIsTemplate(db, dbName, false); err != nil {
+		return fmt.Errorf("Error updating database IS_TEMPLATE during DROP DATABASE: %w", err)
+	}
+	return nil
+}
+
+// dropDatabase drops *dbName*, whose connections must have been terminated, bypassing
+// PgBouncer if configured (see directConnection). The drop is forced from PostgreSQL 13.
+// *inUseHint* completes the error returned if the database is still in use.
+func dropDatabase(db *DBConnection, dbName, inUseHint string) error {
+	dropWithForce := ""
+	if db.featureSupported(featureForceDropDatabase) {
+		dropWithForce = "WITH ( FORCE )"
+	}
+
+	ddlDB, err := db.directConnection()
+	if err != nil {
+		return err
+	}
+	sql := fmt.Sprintf("DROP DATABASE %s %s", pq.QuoteIdentifier(dbName), dropWithForce)
+	if _, err := ddlDB.Exec(sql); err != nil {
+		if isPQErrorCode(err, pqErrorCodeObjectInUse) {
+			return fmt.Errorf(
+				"Error dropping database %q: it is still in use, e.g. by sessions reconnecting to it "+
+					"or by a connection pooler the provider connects through, "+
+					"point the provider database to another database%s: %w",
+				dbName, inUseHint, err,
+			)
+		}
+		return fmt.Errorf("Error dropping database %q: %w", dbName, err)
+	}
+	return nil
+}
+
+// maintenanceDBConnection returns a connection to postgres (template1 to drop postgres)
+// if the provider is connected to *dbName*, db otherwise.
+func maintenanceDBConnection(db *DBConnection, dbName string) (*DBConnection, error) {
+	if db.client.databaseName != dbName {
+		return db, nil
+	}
+
+	maintenanceDB := "postgres"
+	if dbName == maintenanceDB {
+		maintenanceDB = "template1"
+	}
+	log.Printf("[DEBUG] the provider is connected to PostgreSQL database (%q), using %q instead", dbName, maintenanceDB)
+
+	client := db.client.config.NewClient(maintenanceDB)
+	conn, err := client.Connect()
+	if err != nil {
+		return nil, fmt.Errorf("Error connecting to database %q to drop database %q: %w", maintenanceDB, dbName, err)
+	}
+	return conn.WithContext(db.client.ctx), nil
+}
+
+func resourcePostgreSQLDatabaseRead(db *DBConnection, d *schema.ResourceData) diag.Diagnostics {
+	return resourcePostgreSQLDatabaseReadImpl(db, d)
+}
+
+// dbCommonLocale returns the locale of a database, if its *collation* and *ctype* are the same.
+func dbCommonLocale(collation, ctype string) string {
+	if collation != ctype {
+		return ""
+	}
+	return collation
+}
+
+// dbLocale returns the libc *locale* (datcollate or datctype) of a database.
+// On ICU databases, it can be empty: the ICU locale is returned instead.
+// A libc locale which is set is kept, as it is the one given to CREATE DATABASE.
+func dbLocale(locale, localeProvider, icuLocale sql.NullString) string {
+	if (!locale.Valid || locale.String == "") && localeProvider.String == "i" && icuLocale.Valid {
+		return icuLocale.String
+	}
+	return locale.String
+}
+
+// dbCatalogRow is a row of pg_database, read by resourcePostgreSQLDatabaseReadImpl.
+// All the columns are nullable: unusual servers or YugabyteDB variants can
+// return NULLs which must not make the read fail.
+type dbCatalogRow struct {
+	name           sql.NullString
+	owner          sql.NullString
+	encoding       sql.NullString
+	collation      sql.NullString
+	ctype          sql.NullString
+	tablespace     sql.NullString
+	connLimit      sql.NullInt64
+	localeProvider sql.NullString
+	icuLocale      sql.NullString
+	oid            sql.NullInt64
+}
+
+// setDBCatalogState sets the attributes read from pg_database in the state,
+// falling back on sensible defaults for the NULL columns.
+func setDBCatalogState(d *schema.ResourceData, dbId string, row dbCatalogRow) {
+	dbName := dbId
+	if row.name.Valid {
+		dbName = row.name.String
+	}
+	if !row.tablespace.Valid {
+		// Fall back on the default tablespace rather than planning a change.
+		log.Printf("[WARN] could not resolve the tablespace of PostgreSQL database (%q), assuming pg_default", dbId)
+		row.tablespace.String = "pg_default"
+	}
+	connLimit := -1
+	if row.connLimit.Valid {
+		connLimit = int(row.connLimit.Int64)
+	}
+
+	d.Set(dbNameAttr, dbName)
+	d.Set(dbIsSystemAttr, isSystemDatabase(dbName))
+	d.Set(dbOwnerAttr, row.owner.String)
+	d.Set(dbEncodingAttr, row.encoding.String)
+	d.Set(dbCollationAttr, dbLocale(row.collation, row.localeProvider, row.icuLocale))
+	d.Set(dbCTypeAttr, dbLocale(row.ctype, row.localeProvider, row.icuLocale))
+	d.Set(dbLocaleAttr, dbCommonLocale(d.Get(dbCollationAttr).(string), d.Get(dbCTypeAttr).(string)))
+	d.Set(dbTablespaceAttr, row.tablespace.String)
+	d.Set(dbConnLimitAttr, connLimit)
+	d.Set(dbUnlimitedConnsAttr, connLimit == -1)
+	d.Set(dbOIDAttr, int(row.oid.Int64))
+}
+
+// resourcePostgreSQLDatabaseImport imports the database named by the ID.
+// The attributes which cannot be read from the server are set to their default,
+// so that the plan following the import is empty when they are not configured.
+func resourcePostgreSQLDatabaseImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	for name, s := range resourcePostgreSQLDatabase().Schema {
+		if s.Default != nil {
+			if err := d.Set(name, s.Default); err != nil {
+				return nil, fmt.Errorf("Error setting %s of the imported database: %w", name, err)
+			}
+		}
+	}
+
+	db, err := meta.(*Client).Connect()
+	if err != nil {
+		return nil, err
+	}
+	db = db.WithContext(ctx)
+
+	// The read following the import would remove a missing database from the state
+	// without error, the import fails instead.
+	exists, err := dbExists(db, d.Id())
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("PostgreSQL database %q does not exist", d.Id())
+	}
+
+	// colocation is only used on creation, it is imported as it is on the server.
+	colocated, err := getDBColocationEffective(db, d.Id())
+	if err != nil {
+		log.Printf("[WARN] could not read the colocation of PostgreSQL database (%q): %v", d.Id(), err)
+	} else {
+		d.Set(dbColocationAttr, colocated)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourcePostgreSQLDatabaseReadImpl(db *DBConnection, d *schema.ResourceData) diag.Diagnostics {
+	dbId := d.Id()
+
+	columns := []string{
+		"d.datname",
+		"pg_catalog.pg_get_userbyid(d.datdba)",
+		"pg_catalog.pg_encoding_to_char(d.encoding)",
+		"d.datcollate",
+		"d.datctype",
+		"ts.spcname",
+		"d.datconnlimit",
+		"NULL",
+		"NULL",
+		"d.oid",
+	}
+	switch {
+	case db.featureSupported(featureDatabaseLocale):
+		columns[7], columns[8] = "d.datlocprovider::text", "d.datlocale"
+	case db.featureSupported(featureDatabaseLocaleProvider):
+		columns[7], columns[8] = "d.datlocprovider::text", "d.daticulocale"
+	}
+
+	// The tablespace is LEFT JOINed: a tablespace which cannot be resolved
+	// must not make the database look like it does not exist.
+	dbSQLFmt := `SELECT %s ` +
+		`FROM pg_catalog.pg_database AS d ` +
+		`LEFT JOIN pg_catalog.pg_tablespace AS ts ON ts.oid = d.dattablespace ` +
+		`WHERE d.datname = $1`
+	dbSQL := fmt.Sprintf(dbSQLFmt, strings.Join(columns, ", "))
+	var row dbCatalogRow
+	err := db.QueryRow(dbSQL, dbId).
+		Scan(
+			&row.name,
+			&row.owner,
+			&row.encoding,
+			&row.collation,
+			&row.ctype,
+			&row.tablespace,
+			&row.connLimit,
+			&row.localeProvider,
+			&row.icuLocale,
+			&row.oid,
+		)
+	switch {
+	case err == sql.ErrNoRows:
+		log.Printf("[WARN] PostgreSQL database (%q) not found", dbId)
+		d.SetId("")
+		return nil
+	case err != nil:
+		return diag.FromErr(fmt.Errorf("Error reading database: %w", err))
+	}
+
+	pgVersion, err := db.serverVersion()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	connLimitSupported := connLimitSupportedBy(pgVersion)
+	stateConnLimit := d.Get(dbConnLimitAttr).(int)
+
+	setDBCatalogState(d, dbId, row)
+	dbName := d.Get(dbNameAttr).(string)
+	if !connLimitSupported {
+		// The connection limit is kept as planned on the servers which do not enforce it,
+		// rather than planning a change which would never be applied.
+		d.Set(dbConnLimitAttr, stateConnLimit)
+		d.Set(dbUnlimitedConnsAttr, stateConnLimit == -1)
+	}
+	// A colocated database created without template uses the server default template,
+	// which is not recorded (see createDatabaseQuery).
+	dbTemplate := d.Get(dbTemplateAttr).(string)
+	if dbTemplate == "" && !d.Get(dbColocationAttr).(bool) {
+		dbTemplate = "template0"
+	}
+	d.Set(dbTemplateAttr, dbTemplate)
+
+	dbConfig, err := readDBConfig(db, dbId)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	// The search path pinned by the inherit mode is not managed as search_path.
+	if d.Get(dbSearchPathModeAttr).(string) != dbSearchPathModeInherit {
+		d.Set(dbSearchPathAttr, readDBListSetting(dbConfig, "search_path"))
+	}
+	// As for settings, the parameters of the typed attributes are only read if managed
+	// by Terraform: the ones set outside of it are not reported, so they are not reset.
+	for _, name := range append([]string{dbDefaultTablespace, dbLogStatementAttr, dbStatementTimeoutAttr, dbLockTimeoutAttr, dbToastCompressionAttr}, dbMemorySettings...) {
+		if dbSettingManaged(d, name) {
+			d.Set(name, readDBSetting(dbConfig, name))
+		}
+	}
+	if dbSettingManaged(d, dbDefaultRoleAttr) {
+		d.Set(dbDefaultRoleAttr, readDBSetting(dbConfig, "role"))
+	}
+	if dbSettingManaged(d, dbLogMinDurationAttr) {
+		logMinDuration, err := readDBDurationSetting(dbConfig, dbLogMinDurationAttr)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		d.Set(dbLogMinDurationAttr, logMinDuration)
+	}
+	if dbSettingManaged(d, dbSessionPreloadAttr) {
+		d.Set(dbSessionPreloadAttr, readDBListSetting(dbConfig, dbSessionPreloadAttr))
+	}
+	d.Set(dbEffectiveSetsAttr, readDBSettings(dbConfig))
+
+	// Only the parameters managed by Terraform are read,
+	// the ones set outside of it are left untouched.
+	settings := map[string]string{}
+	for name, configured := range d.Get(dbSettingsAttr).(map[string]interface{}) {
+		if value := readDBSetting(dbConfig, name); value != "" {
+			settings[name] = pinnedDBSettingValue(configured.(string), value)
+		}
+	}
+	d.Set(dbSettingsAttr, settings)
+
+	rawSettings := []string{}
+	for _, raw := range d.Get(dbRawSettingsAttr).([]interface{}) {
+		name, configured, _ := strings.Cut(raw.(string), "=")
+		for _, config := range dbConfig {
+			if value, ok := strings.CutPrefix(string(config), name+"="); ok {
+				rawSettings = append(rawSettings, name+"="+pinnedDBSettingValue(configured, value))
+			}
+		}
+	}
+	d.Set(dbRawSettingsAttr, rawSettings)
+
+	if tracker, ok := dbCreatedAtTrackers[d.Get(dbCreatedAtTrackerAttr).(string)]; ok {
+		createdAt, err := tracker.read(db, dbId)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("Error reading creation time of database %q: %w", dbId, err))
+		}
+		d.Set(dbCreatedAtAttr, createdAt)
+	}
+
+	// The privileges are only read if managed by Terraform,
+	// so they can be granted with postgresql_grant instead.
+	for attr, privilege := range dbPrivilegeRolesAttrs {
+		if d.Get(attr).(*schema.Set).Len() == 0 {
+			continue
+		}
+		roles, err := readDBPrivilegeRoles(db, dbName, privilege)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		d.Set(attr, stringSliceToSet(roles))
+	}
+
+	roleConnLimits, err := readDBRoleConnLimits(db, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set(dbRoleConnLimitsAttr, roleConnLimits)
+
+	// Only read the public schema privileges if managed by Terraform
+	// as it requires to connect to the database itself.
+	if _, ok := d.GetOkExists(dbPublicSchemaCreate); ok { //nolint:staticcheck
+		publicSchemaCreate, err := getDBPublicSchemaCreate(db, dbName)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		d.Set(dbPublicSchemaCreate, publicSchemaCreate)
+	}
+
+	var dbAllowConns = true
+	if db.featureSupported(featureDBAllowConnections) {
+		var allowConns sql.NullBool
+		dbSQL := fmt.Sprintf(dbSQLFmt, "d.datallowconn")
+		err = db.QueryRow(dbSQL, dbId).Scan(&allowConns)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("Error reading ALLOW_CONNECTIONS property for DATABASE: %w", err))
+		}
+		if allowConns.Valid {
+			dbAllowConns = allowConns.Bool
+		}
+
+		d.Set(dbAllowConnsAttr, dbAllowConns)
+	}
+
+	// The colocation can only be read from the database itself,
+	// the previous value is kept if connections are not allowed.
+	if dbAllowConns {
+		colocationEffective, err := getDBColocationEffective(db, dbName)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		d.Set(dbColocationEffAttr, colocationEffective)
+	}
+
+	if db.featureSupported(featureDBIsTemplate) {
+		dbIsTemplate, err := getDBIsTemplate(db, dbId)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		d.Set(dbIsTemplateAttr, dbIsTemplate)
+	}
+
+	// The count is informative, the read must not fail if it cannot be done.
+	if count, err := countDBConnections(db, dbId); err != nil {
+		log.Printf("[WARN] could not count the connections to PostgreSQL database (%q): %v", dbId, err)
+	} else {
+		d.Set(dbActiveConnsAttr, count)
+	}
+	if sessions, err := readDBSessions(db, dbId); err != nil {
+		log.Printf("[WARN] could not list the sessions of PostgreSQL database (%q): %v", dbId, err)
+	} else {
+		d.Set(dbActiveSessionsAttr, dbSessionsState(sessions))
+	}
+
+	// data_checksums is a cluster setting, reported for auditing only.
+	if checksums, err := readDataChecksums(db); err != nil {
+		log.Printf("[WARN] could not read data_checksums for PostgreSQL database (%q): %v", dbId, err)
+		d.Set(dbDataChecksumsAttr, false)
+	} else {
+		d.Set(dbDataChecksumsAttr, checksums)
+	}
+
+	var diags diag.Diagnostics
+	if connLimit := d.Get(dbConnLimitAttr).(int); !connLimitSupported && connLimit != -1 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Connection limit of database %q is not enforced", dbName),
+			Detail: fmt.Sprintf(
+				"%s is %d but the server does not enforce the connection limit of the databases (%s): it is not set.",
+				dbConnLimitAttr, connLimit, pgVersion,
+			),
+			AttributePath: cty.GetAttrPath(dbConnLimitAttr),
+		})
+	} else if connLimit > 0 {
+		maxConnections, err := readMaxConnections(db)
+		if err != nil {
+			log.Printf("[WARN] could not read max_connections to check the connection limit of PostgreSQL database (%q): %v", dbId, err)
+		} else {
+			diags = append(diags, dbConnLimitDiags(dbName, connLimit, maxConnections)...)
+		}
+	}
+
+	if owner := d.Get(dbOwnerAttr).(string); isOrphanedDBOwner(owner) {
+		diags = append(diags, dbOrphanedOwnerDiags(dbName, owner)...)
+	}
+
+	if db.featureSupported(featureDatabaseCollationVersion) {
+		var recordedVersion, actualVersion sql.NullString
+		err := db.QueryRow(
+			"SELECT d.datcollversion, pg_catalog.pg_database_collation_actual_version(d.oid) "+
+				"FROM pg_catalog.pg_database AS d WHERE d.datname = $1",
+			dbId,
+		).Scan(&recordedVersion, &actualVersion)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("Error reading collation version of database %q: %w", dbId, err))
+		}
+		mismatch := recordedVersion != actualVersion
+		d.Set(dbCollVersionMismatch, mismatch)
+
+		if mismatch {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("Collation version mismatch for database %q", dbName),
+				Detail: fmt.Sprintf(
+					"The database was created with collation version %q but the operating system provides version %q. "+
+						"Indexes depending on the collation may be corrupted: rebuild them, "+
+						"then run ALTER DATABASE %s REFRESH COLLATION VERSION.",
+					recordedVersion.String, actualVersion.String, pq.QuoteIdentifier(dbName),
+				),
+				AttributePath: cty.GetAttrPath(dbCollVersionMismatch),
+			})
+		}
+	}
+
+	return diags
+}
+
+func resourcePostgreSQLDatabaseUpdate(db *DBConnection, d *schema.ResourceData) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if d.HasChange(dbNameAttr) {
+		previousName, _ := d.GetChange(dbNameAttr)
+		if err := waitForDBIdleBefore(db, d, "rename", previousName.(string)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChange(dbNameAttr) && d.HasChange(dbOwnerAttr) && d.Get(dbOwnerAttr).(string) != "" {
+		// Rename the database and change its owner atomically,
+		// the objects are reassigned afterward from the previous owner.
+		previousOwner, err := setDBNameAndOwner(db, d)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		if d.Get(dbAlterObjectOwnership).(bool) && previousOwner != "" {
+			if err := reassignOwnedObjects(db, d, previousOwner); err != nil {
+				return diag.FromErr(err)
+			}
+			diags = append(diags, remainingOwnedObjectsDiags(db, d, previousOwner)...)
+		}
+	} else {
+		if err := setDBName(db, d); err != nil {
+			return diag.FromErr(err)
+		}
+
+		previousOwner, err := setAlterOwnership(db, d)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if previousOwner != "" {
+			diags = append(diags, remainingOwnedObjectsDiags(db, d, previousOwner)...)
+		}
+
+		if err := setDBOwner(db, d); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if err := grantNewOwnerOnExisting(db, d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// The database has been renamed first: the following setters read the planned
+	// name with d.Get(dbNameAttr), which is the new name, never the previous one.
+	if err := setDBTablespace(db, d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setDBConnLimit(db, d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setDBAllowConns(db, d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setDBIsTemplate(db, d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setDBSearchPath(db, d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setDBPublicSchemaCreate(db, d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setDBRoleConnLimits(db, d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setDBDefaultRole(db, d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := reconcileDBSettings(db, d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setDBPrivilegeRoles(db, d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return append(diags, resourcePostgreSQLDatabaseReadImpl(db, d)...)
+}
+
+func setDBName(db QueryAble, d *schema.ResourceData) error {
+	if !d.HasChange(dbNameAttr) {
+		return nil
+	}
+
+	oraw, nraw := d.GetChange(dbNameAttr)
+	o := oraw.(string)
+	n := nraw.(string)
+	if n == "" {
+		return errors.New("Error setting database name to an empty string")
+	}
+
+	sql := buildSQL("ALTER DATABASE %s RENAME TO %s", sqlIdent(o), sqlIdent(n))
+	if _, err := db.Exec(sql); err != nil {
+		return fmt.Errorf("Error updating database name: %w", err)
+	}
+	// The ID and the name are updated together, so the state is consistent
+	// even if a following setter fails.
+	d.SetId(n)
+	d.Set(dbNameAttr, n)
+
+	return nil
+}
+
+// setDBNameAndOwner renames the database and changes its owner in a single transaction,
+// so the renamed database is never visible with its previous owner.
+// It returns the previous owner of the database.
+func setDBNameAndOwner(db *DBConnection, d *schema.ResourceData) (string, error) {
+	oraw, nraw := d.GetChange(dbNameAttr)
+	o := oraw.(string)
+	n := nraw.(string)
+	if n == "" {
+		return "", errors.New("Error setting database name to an empty string")
+	}
+	owner := d.Get(dbOwnerAttr).(string)
+	currentUser := db.client.config.getDatabaseUsername()
+
+	lockTxn, err := startTransaction(db.client, "")
+	if err != nil {
+		return "", err
+	}
+	defer deferredRollback(lockTxn)
+
+	// There is nothing to reassign from an owner which was dropped.
+	var previousOwner string
+	if oldOwner, _ := d.GetChange(dbOwnerAttr); !isOrphanedDBOwner(oldOwner.(string)) {
+		if previousOwner, err = getDatabaseOwner(lockTxn, o); err != nil {
+			return "", fmt.Errorf("Error getting current database OWNER: %w", err)
+		}
+	}
+
+	// Needed in order to set the owner of the db if the connection user is not a superuser.
+	// The membership is granted and revoked in the same transaction.
+	var ownerGranted bool
+	if ownerMembershipNeeded(owner, currentUser) {
+		if err := db.lockRole(lockTxn, currentUser); err != nil {
+			return "", err
+		}
+		if ownerGranted, err = grantRoleMembership(lockTxn, owner, currentUser); err != nil {
+			return "", err
+		}
+	}
+
+	sql := buildSQL("ALTER DATABASE %s RENAME TO %s", sqlIdent(o), sqlIdent(n))
+	if _, err := lockTxn.Exec(sql); err != nil {
+		return "", fmt.Errorf("Error updating database name: %w", err)
+	}
+
+	// The owner is not changed if it is unknown or was dropped.
+	if owner != "" && !isOrphanedDBOwner(owner) {
+		sql = fmt.Sprintf("ALTER DATABASE %s OWNER TO %s", pq.QuoteIdentifier(n), pq.QuoteIdentifier(owner))
+		if _, err := lockTxn.Exec(sql); err != nil {
+			return "", fmt.Errorf("Error updating database OWNER: %w", err)
+		}
+	}
+
+	if ownerGranted {
+		if _, err := revokeRoleMembership(lockTxn, owner, currentUser); err != nil {
+			return "", err
+		}
+	}
+
+	if err := lockTxn.Commit(); err != nil {
+		return "", fmt.Errorf("Error committing database name and OWNER: %w", err)
+	}
+	d.SetId(n)
+	d.Set(dbNameAttr, n)
+
+	return previousOwner, nil
+}
+
+func setDBOwner(db *DBConnection, d *schema.ResourceData) error {
+	if !d.HasChange(dbOwnerAttr) {
+		return nil
+	}
+
+	owner := d.Get(dbOwnerAttr).(string)
+	if owner == "" {
+		return nil
+	}
+
+	return changeDBOwner(db, []string{d.Get(dbNameAttr).(string)}, owner)
+}
+
+// setAlterOwnership reassigns the objects of the database to the new owner
+// and returns the previous owner, or an empty string if nothing was reassigned.
+// The reassignment is committed on its own (see reassignOwnedObjects).
+func setAlterOwnership(db *DBConnection, d *schema.ResourceData) (string, error) {
+	if !d.HasChange(dbOwnerAttr) && !d.HasChange(dbAlterObjectOwnership) {
+		return "", nil
+	}
+	owner := d.Get(dbOwnerAttr).(string)
+	if owner == "" {
+		return "", nil
+	}
+
+	alterOwnership := d.Get(dbAlterObjectOwnership).(bool)
+	if !alterOwnership {
+		return "", nil
+	}
+
+	// There is nothing to reassign from an owner which was dropped.
+	if oldOwner, _ := d.GetChange(dbOwnerAttr); isOrphanedDBOwner(oldOwner.(string)) {
+		return "", nil
+	}
+
+	dbName := d.Get(dbNameAttr).(string)
+	currentOwner, err := getDatabaseOwner(db, dbName)
+	if err != nil {
+		return "", fmt.Errorf("Error getting current database OWNER: %w", err)
+	}
+	if currentOwner == owner {
+		return "", nil
+	}
+
+	return currentOwner, reassignOwnedObjects(db, d, currentOwner)
+}
+
+// grantNewOwnerOnExisting grants all privileges on the existing schemas and objects
+// of the database to its new owner, when they keep their previous owner
+// (i.e. alter_object_ownership is false).
+func grantNewOwnerOnExisting(db *DBConnection, d *schema.ResourceData) error {
+	if !d.HasChange(dbOwnerAttr) || !d.Get(dbGrantNewOwnerAttr).(bool) || d.Get(dbAlterObjectOwnership).(bool) {
+		return nil
+	}
+
+	oraw, nraw := d.GetChange(dbOwnerAttr)
+	previousOwner := oraw.(string)
+	newOwner := nraw.(string)
+	if previousOwner == "" || newOwner == "" || previousOwner == newOwner || isOrphanedDBOwner(previousOwner) {
+		return nil
+	}
+
+	dbName := d.Get(dbNameAttr).(string)
+
+	// The privileges are granted as a member of the previous owner, which owns the
+	// objects. As for the other memberships, the role is locked on the provider database.
+	return withOwnerMembership(db, previousOwner, func() error {
+		txn, err := startTransaction(db.client, dbName)
+		if err != nil {
+			return err
+		}
+		defer deferredRollback(txn)
+
+		schemas, err := getNonSystemSchemas(txn)
+		if err != nil {
+			return err
+		}
+
+		for _, schemaName := range schemas {
+			for _, sql := range []string{
+				"GRANT ALL ON SCHEMA %s TO %s",
+				"GRANT ALL ON ALL TABLES IN SCHEMA %s TO %s",
+				"GRANT ALL ON ALL SEQUENCES IN SCHEMA %s TO %s",
+				"GRANT ALL ON ALL FUNCTIONS IN SCHEMA %s TO %s",
+			} {
+				sql = fmt.Sprintf(sql, pq.QuoteIdentifier(schemaName), pq.QuoteIdentifier(newOwner))
+				if _, err := txn.Exec(sql); err != nil {
+					return fmt.Errorf("Error granting privileges on schema %s to the new owner %s: %w", schemaName, newOwner, err)
+				}
+			}
+		}
+
+		if err := txn.Commit(); err != nil {
+			return fmt.Errorf("error committing grants to the new owner: %w", err)
+		}
+		return nil
+	})
+}
+
+// getNonSystemSchemas returns the schemas of the database the transaction is connected to,
+// except the system ones.
+func getNonSystemSchemas(txn *DBTransaction) ([]string, error) {
+	rows, err := txn.Query(
+		`SELECT nspname FROM pg_catalog.pg_namespace ` +
+			`WHERE nspname !~ '^pg_' AND nspname <> 'information_schema' ORDER BY nspname`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not list schemas: %w", err)
+	}
+	defer rows.Close()
+
+	schemas := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("could not scan schema name: %w", err)
+		}
+		schemas = append(schemas, name)
+	}
+	return schemas, rows.Err()
+}
+
+// remainingOwnedObjectsDiags returns a warning listing the other databases in which
+// *previousOwner* still owns objects, as REASSIGN OWNED only affects the current database.
+func remainingOwnedObjectsDiags(db *DBConnection, d *schema.ResourceData, previousOwner string) diag.Diagnostics {
+	dbName := d.Get(dbNameAttr).(string)
+
+	databases, err := getRoleOwnedObjectsDatabases(db, previousOwner, dbName)
+	if err != nil {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Could not check objects still owned by role %q", previousOwner),
+			Detail:   err.Error(),
+		}}
+	}
+	if len(databases) == 0 {
+		return nil
+	}
+
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("Role %q still owns objects in other databases", previousOwner),
+		Detail: fmt.Sprintf(
+			"REASSIGN OWNED only reassigned the objects of database %q. "+
+				"Role %q still owns objects in the following databases: %s. "+
+				"These objects have to be reassigned in each database before the role can be dropped.",
+			dbName, previousOwner, strings.Join(databases, ", "),
+		),
+		AttributePath: cty.GetAttrPath(dbAlterObjectOwnership),
+	}}
+}
+
+// getRoleOwnedObjectsDatabases returns the databases, other than *excludedDB*,
+// containing objects owned by *role*.
+func getRoleOwnedObjectsDatabases(db QueryAble, role, excludedDB string) ([]string, error) {
+	query := `SELECT DISTINCT d.datname ` +
+		`FROM pg_catalog.pg_shdepend s ` +
+		`JOIN pg_catalog.pg_database d ON d.oid = s.dbid ` +
+		`JOIN pg_catalog.pg_roles r ON r.oid = s.refobjid ` +
+		`WHERE s.refclassid = 'pg_catalog.pg_authid'::regclass AND s.deptype = 'o' ` +
+		`AND r.rolname = $1 AND d.datname <> $2 ` +
+		`ORDER BY d.datname`
+
+	rows, err := db.Query(query, role, excludedDB)
+	if err != nil {
+		return nil, fmt.Errorf("could not list databases with objects owned by %s: %w", role, err)
+	}
+	defer rows.Close()
+
+	databases := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("could not scan database name: %w", err)
+		}
+		databases = append(databases, name)
+	}
+	return databases, rows.Err()
+}
+
+// reassignOwnedObjects reassigns the objects of the database owned by *currentOwner*
+// to the owner of the resource.
+//
+// REASSIGN OWNED must run in a transaction connected to the database, so it cannot be
+// part of the other changes of the update: it is committed on its own, and is not
+// rolled back if a following setter fails. The next apply reads the actual owner and
+// runs it again if needed, which is harmless as only the objects still owned by
+// *currentOwner* are reassigned.
+//
+// The comment and the security labels of the database are attached to the database
+// itself (pg_shdescription and pg_shseclabel), not to its owner: they are kept.
+func reassignOwnedObjects(db *DBConnection, d *schema.ResourceData, currentOwner string) (err error) {
+	currentUser := db.client.config.getDatabaseUsername()
+	dbName := d.Get(dbNameAttr).(string)
+	newOwner := d.Get(dbOwnerAttr).(string)
+
+	lockTxn, err := startTransaction(db.client, dbName)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(lockTxn)
+	if err := db.lockRole(lockTxn, currentUser); err != nil {
+		return err
+	}
+
+	if currentOwner == newOwner {
+		return nil
+	}
+
+	if db.client.config.ReassignViaSetRole {
+		// Run REASSIGN as the current owner for the rest of the transaction only,
+		// instead of granting it to the connected user.
+		sql := fmt.Sprintf("SET LOCAL ROLE %s", pq.QuoteIdentifier(currentOwner))
+		if _, err := lockTxn.Exec(sql); err != nil {
+			return fmt.Errorf("could not set role %s: %w", currentOwner, err)
+		}
+	} else {
+		// The revoke error is set on the returned err, which must not be shadowed here.
+		currentOwnerGranted, grantErr := grantRoleMembership(db, currentOwner, currentUser)
+		if grantErr != nil {
+			return grantErr
+		}
+		if currentOwnerGranted {
+			defer func() {
+				if _, revokeErr := revokeRoleMembership(db, currentOwner, currentUser); revokeErr != nil && err == nil {
+					err = revokeErr
+				}
+			}()
+		}
+	}
+	sql := fmt.Sprintf("REASSIGN OWNED BY %s TO %s", pq.QuoteIdentifier(currentOwner), pq.QuoteIdentifier(newOwner))
+	if _, err := lockTxn.Exec(sql); err != nil {
+		return fmt.Errorf("Error reassigning objects owned by '%s': %w", currentOwner, err)
+	}
+
+	// The large objects are per database: they are only reassigned as the transaction
+	// runs in the database itself, which is checked before committing.
+	count, err := countOwnedLargeObjects(lockTxn, currentOwner)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("Error reassigning objects owned by '%s': %d large objects of database %s are still owned by it", currentOwner, count, dbName)
+	}
+
+	if err := lockTxn.Commit(); err != nil {
+		return fmt.Errorf("error committing reassign: %w", err)
+	}
+	return nil
+}
+
+// countOwnedLargeObjects returns the number of large objects of the current database owned by *role*.
+func countOwnedLargeObjects(db QueryAble, role string) (int, error) {
+	var count int
+	err := db.QueryRow(
+		"SELECT count(*) FROM pg_catalog.pg_largeobject_metadata WHERE pg_catalog.pg_get_userbyid(lomowner) = $1",
+		role,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("could not count the large objects owned by %s: %w", role, err)
+	}
+	return count, nil
+}
+
+func setDBTablespace(db *DBConnection, d *schema.ResourceData) error {
+	if !d.HasChange(dbTablespaceAttr) {
+		return nil
+	}
+
+	tbspName := d.Get(dbTablespaceAttr).(string)
+	dbName := d.Get(dbNameAttr).(string)
+	if tbspName == "" || strings.ToUpper(tbspName) == "DEFAULT" {
+		// Resetting means moving the database back to the default tablespace,
+		// nothing to do if it is already there.
+		var currentTbspName string
+		err := db.QueryRow(
+			"SELECT ts.spcname FROM pg_catalog.pg_database AS d, pg_catalog.pg_tablespace AS ts "+
+				"WHERE d.datname = $1 AND d.dattablespace = ts.oid",
+			dbName,
+		).Scan(&currentTbspName)
+		if err != nil {
+			return fmt.Errorf("Error reading database TABLESPACE: %w", err)
+		}
+		if currentTbspName == "pg_default" {
+			return nil
+		}
+		tbspName = "pg_default"
+	}
+
+	if err := waitForDBIdleBefore(db, d, "tablespace", dbName); err != nil {
+		return err
+	}
+
+	// The database cannot be moved while sessions are connected to it.
+	if d.Get(dbTablespaceDrainAttr).(bool) {
+		if err := drainDBBackends(db, dbName, d.Get(dbCancelFirstAttr).(bool)); err != nil {
+			return err
+		}
+		log.Printf("[DEBUG] terminated the connections to database %s before changing its tablespace", dbName)
+	}
+
+	sql := fmt.Sprintf("ALTER DATABASE %s SET TABLESPACE %s", pq.QuoteIdentifier(dbName), pq.QuoteIdentifier(tbspName))
+	if _, err := db.Exec(sql); err != nil {
+		return fmt.Errorf("Error updating database TABLESPACE: %w", err)
+	}
+
+	return nil
+}
+
+func setDBConnLimit(db *DBConnection, d *schema.ResourceData) error {
+	if !d.HasChange(dbConnLimitAttr) {
+		return nil
+	}
+
+	connLimit := d.Get(dbConnLimitAttr).(int)
+	dbName := d.Get(dbNameAttr).(string)
+
+	pgVersion, err := db.serverVersion()
+	if err != nil {
+		return err
+	}
+	if !connLimitSupportedBy(pgVersion) {
+		log.Printf("[WARN] the connection limit of PostgreSQL database (%q) is not set, the server does not enforce it: %s", dbName, pgVersion)
+		return nil
+	}
+
+	// ALTER DATABASE does not accept bind parameters, the limit is formatted as an integer.
+	sql := buildSQL("ALTER DATABASE %s CONNECTION LIMIT = %d", sqlIdent(dbName), connLimit)
+	if _, err := db.Exec(sql); err != nil {
+		return fmt.Errorf("Error updating database CONNECTION LIMIT: %w", err)
+	}
+
+	return nil
+}
+
+func setDBAllowConns(db *DBConnection, d *schema.ResourceData) error {
+	if !d.HasChange(dbAllowConnsAttr) {
+		return nil
+	}
+
+	if !db.featureSupported(featureDBAllowConnections) {
+		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support database ALLOW_CONNECTIONS", db.version.String())
+	}
+
+	allowConns := d.Get(dbAllowConnsAttr).(bool)
+	dbName := d.Get(dbNameAttr).(string)
+	sql := buildSQL("ALTER DATABASE %s ALLOW_CONNECTIONS %t", sqlIdent(dbName), allowConns)
+	if _, err := db.Exec(sql); err != nil {
+		return fmt.Errorf("Error updating database ALLOW_CONNECTIONS: %w", err)
+	}
+
+	return nil
+}
+
+func setDBIsTemplate(db *DBConnection, d *schema.ResourceData) error {
+	if !d.HasChange(dbIsTemplateAttr) {
+		return nil
+	}
+
+	if err := doSetDBIsTemplate(db, d.Get(dbNameAttr).(string), d.Get(dbIsTemplateAttr).(bool)); err != nil {
+		return fmt.Errorf("Error updating database IS_TEMPLATE: %w", err)
+	}
+
+	return nil
+}
+
+func getDBIsTemplate(db QueryAble, dbName string) (bool, error) {
+	var isTemplate bool
+	err := db.QueryRow("SELECT datistemplate FROM pg_catalog.pg_database WHERE datname = $1", dbName).Scan(&isTemplate)
+	switch {
+	case err == sql.ErrNoRows:
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("Error reading IS_TEMPLATE property for DATABASE: %w", err)
+	}
+	return isTemplate, nil
+}
+
+func doSetDBIsTemplate(db *DBConnection, dbName string, isTemplate bool) error {
+	if !db.featureSupported(featureDBIsTemplate) {
+		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support database IS_TEMPLATE", db.version.String())
+	}
+
+	sql := buildSQL("ALTER DATABASE %s IS_TEMPLATE %t", sqlIdent(dbName), isTemplate)
+	if _, err := db.Exec(sql); err != nil {
+		return fmt.Errorf("Error updating database IS_TEMPLATE: %w", err)
+	}
+
+	return nil
+}
+
+// readDBConfig returns the configuration parameters set for the database
+// (for all roles) as stored in pg_db_role_setting.
+func readDBConfig(db QueryAble, dbName string) (pq.ByteaArray, error) {
+	var dbConfig pq.ByteaArray
+	err := db.QueryRow(
+		"SELECT s.setconfig FROM pg_catalog.pg_db_role_setting AS s "+
+			"JOIN pg_catalog.pg_database AS d ON d.oid = s.setdatabase "+
+			"WHERE d.datname = $1 AND s.setrole = 0",
+		dbName,
+	).Scan(&dbConfig)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("Error reading database configuration: %w", err)
+	}
+	return dbConfig, nil
+}
+
+func setDBSearchPath(db QueryAble, d *schema.ResourceData) error {
+	if !d.HasChange(dbSearchPathAttr) && !d.HasChange(dbSearchPathModeAttr) {
+		return nil
+	}
+
+	return doSetDBSearchPath(db, d)
+}
+
+func doSetDBSearchPath(db QueryAble, d *schema.ResourceData) error {
+	sql, err := createDBSearchPathQuery(d)
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(sql); err != nil {
+		return fmt.Errorf("Error updating database search_path: %w", err)
+	}
+
+	return nil
+}
+
+// createDBSearchPathQuery returns the query to set the search_path of the database
+// according to search_path_mode, or to reset it to the server default if no search
+// path is configured.
+func createDBSearchPathQuery(d *schema.ResourceData) (string, error) {
+	dbName := d.Get(dbNameAttr).(string)
+	searchPathInterface := d.Get(dbSearchPathAttr).([]interface{})
+
+	switch d.Get(dbSearchPathModeAttr).(string) {
+	case dbSearchPathModeInherit:
+		return fmt.Sprintf("ALTER DATABASE %s SET search_path FROM CURRENT", pq.QuoteIdentifier(dbName)), nil
+	case dbSearchPathModeReset:
+		return fmt.Sprintf("ALTER DATABASE %s RESET search_path", pq.QuoteIdentifier(dbName)), nil
+	}
+
+	if len(searchPathInterface) == 0 {
+		return fmt.Sprintf("ALTER DATABASE %s RESET search_path", pq.QuoteIdentifier(dbName)), nil
+	}
+
+	searchPath := make([]string, len(searchPathInterface))
+	for i, searchPathPart := range searchPathInterface {
+		searchPath[i] = pq.QuoteIdentifier(searchPathPart.(string))
+	}
+
+	return fmt.Sprintf(
+		"ALTER DATABASE %s SET search_path TO %s", pq.QuoteIdentifier(dbName), strings.Join(searchPath, ", "),
+	), nil
+}
+
+// readDBSetting searches for the parameter in the setconfig array of the database.
+// In case no such value is present, it returns an empty string.
+func readDBSetting(dbConfig pq.ByteaArray, name string) string {
+	for _, v := range dbConfig {
+		config := string(v)
+		if strings.HasPrefix(config, name+"=") {
+			return strings.TrimPrefix(config, name+"=")
+		}
+	}
+	return ""
+}
+
+// readDBSettings returns all the parameters of the setconfig array of the database.
+func readDBSettings(dbConfig pq.ByteaArray) map[string]string {
+	settings := make(map[string]string, len(dbConfig))
+	for _, v := range dbConfig {
+		if name, value, ok := strings.Cut(string(v), "="); ok {
+			settings[name] = value
+		}
+	}
+	return settings
+}
+
+// pinnedDBSettingValue returns the value to store for a parameter *configured* in settings
+// or raw_settings and set to *value* on the database: the value pinned with FROM CURRENT
+// is only known when it is set, so it matches whatever has been pinned.
+func pinnedDBSettingValue(configured, value string) string {
+	if configured == dbSettingFromCurrent {
+		return dbSettingFromCurrent
+	}
+	return value
+}
+
+// readDBListSetting returns the elements of a list parameter of the database
+// (e.g. search_path, session_preload_libraries).
+func readDBListSetting(dbConfig pq.ByteaArray, name string) []string {
+	setting := readDBSetting(dbConfig, name)
+	if setting == "" {
+		return nil
+	}
+	return parseDBIdentifierList(setting)
+}
+
+// parseDBIdentifierList splits a list parameter as stored by the server: a comma
+// separated list whose elements are double quoted when needed, e.g.
+// `"$user", public, "Mixed Case", "with""quote", "a, b"`. Unlike the unquoted
+// elements, the quoted ones keep their spaces and commas, and "" is a double quote.
+func parseDBIdentifierList(value string) []string {
+	values := []string{}
+	i := 0
+	for {
+		for i < len(value) && value[i] == ' ' {
+			i++
+		}
+
+		var element strings.Builder
+		if i < len(value) && value[i] == '"' {
+			for i++; i < len(value); i++ {
+				if value[i] == '"' {
+					if i+1 < len(value) && value[i+1] == '"' {
+						i++
+					} else {
+						i++
+						break
+					}
+				}
+				element.WriteByte(value[i])
+			}
+			for i < len(value) && value[i] != ',' {
+				i++
+			}
+		} else {
+			end := strings.IndexByte(value[i:], ',')
+			if end < 0 {
+				end = len(value) - i
+			}
+			element.WriteString(strings.TrimRight(value[i:i+end], " "))
+			i += end
+		}
+		values = append(values, element.String())
+
+		if i >= len(value) {
+			return values
+		}
+		// Skip the comma.
+		i++
+	}
+}
+
+// dbSettingManaged returns true if the parameter of the typed attribute *attr* is
+// managed by Terraform, i.e. the attribute is set in the configuration or the state:
+// -1 leaves log_min_duration_statement unset, as an empty value the other ones.
+func dbSettingManaged(d *schema.ResourceData, attr string) bool {
+	switch v := d.Get(attr).(type) {
+	case int:
+		return v >= 0
+	case []interface{}:
+		return len(v) > 0
+	case string:
+		return v != ""
+	}
+	return false
+}
+
+// dbTypedSetting is a parameter of the database set by its own attribute.
+// *value* returns the value of the parameter for the attribute value, or "" to reset it.
+type dbTypedSetting struct {
+	attr  string
+	name  string
+	value func(v interface{}) interface{}
+}
+
+// dbListSetting is the value of a list parameter (e.g. session_preload_libraries),
+// its elements already quoted as literals: 'auto_explain', 'pg_hint_plan'.
+// The server quotes each element of these parameters, so the list cannot be
+// set as a single literal.
+type dbListSetting string
+
+func stringSettingValue(v interface{}) interface{} {
+	s, _ := v.(string)
+	return s
+}
+
+// durationSettingValue normalizes a time value in milliseconds, so 30s and 30000
+// are the same value.
+func durationSettingValue(v interface{}) interface{} {
+	s, _ := v.(string)
+	if s == "" {
+		return ""
+	}
+	if n, err := parseDBDuration(s); err == nil {
+		return strconv.Itoa(n)
+	}
+	return s
+}
+
+// listSettingValue quotes each element of a list attribute as its own literal.
+func listSettingValue(v interface{}) interface{} {
+	list, _ := v.([]interface{})
+	if len(list) == 0 {
+		return ""
+	}
+	values := make([]string, 0, len(list))
+	for _, value := range list {
+		values = append(values, pq.QuoteLiteral(value.(string)))
+	}
+	return dbListSetting(strings.Join(values, ", "))
+}
+
+// dbTypedSettings are the parameters reconciled by reconcileDBSettings,
+// search_path and role have their own setters.
+var dbTypedSettings = []dbTypedSetting{
+	{attr: "maintenance_work_mem", name: "maintenance_work_mem", value: stringSettingValue},
+	{attr: "work_mem", name: "work_mem", value: stringSettingValue},
+	{attr: "temp_buffers", name: "temp_buffers", value: stringSettingValue},
+	{attr: dbDefaultTablespace, name: "default_tablespace", value: stringSettingValue},
+	{attr: dbLogStatementAttr, name: "log_statement", value: stringSettingValue},
+	{attr: dbSessionPreloadAttr, name: "session_preload_libraries", value: listSettingValue},
+	{attr: dbStatementTimeoutAttr, name: "statement_timeout", value: durationSettingValue},
+	{attr: dbLockTimeoutAttr, name: "lock_timeout", value: durationSettingValue},
+	{attr: dbToastCompressionAttr, name: "default_toast_compression", value: stringSettingValue},
+	{attr: dbLogMinDurationAttr, name: "log_min_duration_statement", value: func(v interface{}) interface{} {
+		// -1 leaves the parameter unset.
+		if n, _ := v.(int); n >= 0 {
+			return strconv.Itoa(n)
+		}
+		return ""
+	}},
+}
+
+// dbTypedSettingValues returns the parameters set by the typed attributes,
+// *get* returning the value of an attribute.
+func dbTypedSettingValues(get func(attr string) interface{}) map[string]interface{} {
+	values := map[string]interface{}{}
+	for _, setting := range dbTypedSettings {
+		if value := setting.value(get(setting.attr)); value != "" {
+			values[setting.name] = value
+		}
+	}
+	return values
+}
+
+// reconcileDBSettings sets the parameters of the database whose attribute changed,
+// and resets the ones whose attribute has been cleared: the typed attributes
+// (e.g. work_mem), then settings and raw_settings.
+func reconcileDBSettings(db *DBConnection, d *schema.ResourceData) error {
+	if err := checkDBToastCompression(db, d); err != nil {
+		return err
+	}
+
+	// On creation, the previous values are the zero values of the attributes,
+	// e.g. 0 for log_min_duration_statement: no parameter is set yet.
+	o := map[string]interface{}{}
+	if !d.IsNewResource() {
+		o = dbTypedSettingValues(func(attr string) interface{} {
+			v, _ := d.GetChange(attr)
+			return v
+		})
+	}
+	n := dbTypedSettingValues(d.Get)
+
+	for _, sql := range dbSettingsQueries(d.Get(dbNameAttr).(string), o, n) {
+		if _, err := db.Exec(sql); err != nil {
+			return fmt.Errorf("Error updating database parameters: %w", err)
+		}
+	}
+
+	if err := setDBSettings(db, d); err != nil {
+		return err
+	}
+
+	return setDBRawSettings(db, d)
+}
+
+// checkDBToastCompression checks that the server supports the default_toast_compression
+// set, as the server only reports an invalid value if lz4 is not available.
+func checkDBToastCompression(db *DBConnection, d *schema.ResourceData) error {
+	compression := d.Get(dbToastCompressionAttr).(string)
+	if compression == "" || !d.HasChange(dbToastCompressionAttr) {
+		return nil
+	}
+	if !db.featureSupported(featureToastCompression) {
+		return fmt.Errorf("%s requires PostgreSQL 14 or later (server version %s)", dbToastCompressionAttr, db.version)
+	}
+
+	// The values of the parameter only include the methods the server is built with.
+	var available bool
+	err := db.QueryRow(
+		"SELECT $1 = ANY(enumvals) FROM pg_catalog.pg_settings WHERE name = 'default_toast_compression'",
+		compression,
+	).Scan(&available)
+	if err != nil {
+		return fmt.Errorf("could not read the values of default_toast_compression: %w", err)
+	}
+	if !available {
+		return fmt.Errorf("%s: the server does not support the %s compression, it is not built with it (e.g. --with-lz4)", dbToastCompressionAttr, compression)
+	}
+	return nil
+}
+
+// setDBDefaultRole sets the role parameter of the database, checking first that
+// the role exists as the server only checks it when a session is opened.
+func setDBDefaultRole(db QueryAble, d *schema.ResourceData) error {
+	if !d.HasChange(dbDefaultRoleAttr) {
+		return nil
+	}
+
+	dbName := d.Get(dbNameAttr).(string)
+	role := d.Get(dbDefaultRoleAttr).(string)
+	sql := fmt.Sprintf("ALTER DATABASE %s RESET role", pq.QuoteIdentifier(dbName))
+	if role != "" {
+		var exists bool
+		if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = $1)", role).Scan(&exists); err != nil {
+			return fmt.Errorf("could not check if role %s exists: %w", role, err)
+		}
+		if !exists {
+			return fmt.Errorf("%s: role %q does not exist", dbDefaultRoleAttr, role)
+		}
+		sql = fmt.Sprintf("ALTER DATABASE %s SET role TO %s", pq.QuoteIdentifier(dbName), pq.QuoteLiteral(role))
+	}
+	if _, err := db.Exec(sql); err != nil {
+		return fmt.Errorf("Error updating database role: %w", err)
+	}
+
+	return nil
 }
 
-func resourcePostgreSQLDatabaseReadImpl(db *DBConnection, d *schema.ResourceData) error {
-	dbId := d.Id()
-	var dbName, ownerName string
-	err := db.QueryRow("SELECT d.datname, pg_catalog.pg_get_userbyid(d.datdba) from pg_database d WHERE datname=$1", dbId).Scan(&dbName, &ownerName)
-	switch {
-	case err == sql.ErrNoRows:
-		log.Printf("[WARN] PostgreSQL database (%q) not found", dbId)
-		d.SetId("")
-		return nil
-	case err != nil:
-		return fmt.Errorf("Error reading database: %w", err)
+// readDBDurationSetting returns the time parameter *name* of the database config
+// in milliseconds, or -1 if it is not set.
+func readDBDurationSetting(dbConfig pq.ByteaArray, name string) (int, error) {
+	value := readDBSetting(dbConfig, name)
+	if value == "" {
+		return -1, nil
 	}
 
-	var dbEncoding, dbCollation, dbCType, dbTablespaceName string
-	var dbConnLimit int
+	n, err := parseDBDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse %s value: %w", name, err)
+	}
+	return n, nil
+}
 
-	columns := []string{
-		"pg_catalog.pg_encoding_to_char(d.encoding)",
-		"d.datcollate",
-		"d.datctype",
-		"ts.spcname",
-		"d.datconnlimit",
+// parseDBDuration returns the time *value* in milliseconds, a value without unit
+// being in milliseconds.
+func parseDBDuration(value string) (int, error) {
+	m := durationRegexp.FindStringSubmatch(value)
+	if m == nil {
+		return 0, fmt.Errorf("invalid time value %q", value)
 	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid time value %q: %w", value, err)
+	}
+	if n < 0 || m[2] == "" {
+		return n, nil
+	}
+	if m[2] == "us" {
+		return n / 1000, nil
+	}
+	return n * durationUnits[m[2]], nil
+}
 
-	dbSQLFmt := `SELECT %s ` +
-		`FROM pg_catalog.pg_database AS d, pg_catalog.pg_tablespace AS ts ` +
-		`WHERE d.datname = $1 AND d.dattablespace = ts.oid`
-	dbSQL := fmt.Sprintf(dbSQLFmt, strings.Join(columns, ", "))
-	err = db.QueryRow(dbSQL, dbId).
-		Scan(
-			&dbEncoding,
-			&dbCollation,
-			&dbCType,
-			&dbTablespaceName,
-			&dbConnLimit,
-		)
+// validateDBDuration validates a non-negative time value, e.g. 30s or 30000.
+func validateDBDuration(v interface{}, key string) (warnings []string, errors []error) {
+	n, err := parseDBDuration(v.(string))
 	switch {
-	case err == sql.ErrNoRows:
-		log.Printf("[WARN] PostgreSQL database (%q) not found", dbId)
-		d.SetId("")
-		return nil
 	case err != nil:
-		return fmt.Errorf("Error reading database: %w", err)
+		errors = append(errors, fmt.Errorf("%s: %w, expected milliseconds or a value with unit (e.g. 30s)", key, err))
+	case n < 0:
+		errors = append(errors, fmt.Errorf("%s: %q must not be negative", key, v))
 	}
+	return
+}
 
-	d.Set(dbNameAttr, dbName)
-	d.Set(dbOwnerAttr, ownerName)
-	d.Set(dbEncodingAttr, dbEncoding)
-	d.Set(dbCollationAttr, dbCollation)
-	d.Set(dbCTypeAttr, dbCType)
-	d.Set(dbTablespaceAttr, dbTablespaceName)
-	d.Set(dbConnLimitAttr, dbConnLimit)
-	dbTemplate := d.Get(dbTemplateAttr).(string)
-	if dbTemplate == "" {
-		dbTemplate = "template0"
+// suppressEquivalentDBDuration suppresses the diff between the same time values
+// expressed in different units, e.g. 30s and 30000.
+func suppressEquivalentDBDuration(k, old, new string, d *schema.ResourceData) bool {
+	o, err := parseDBDuration(old)
+	if err != nil {
+		return false
 	}
-	d.Set(dbTemplateAttr, dbTemplate)
+	n, err := parseDBDuration(new)
+	if err != nil {
+		return false
+	}
+	return o == n
+}
 
-	if db.featureSupported(featureDBAllowConnections) {
-		var dbAllowConns bool
-		dbSQL := fmt.Sprintf(dbSQLFmt, "d.datallowconn")
-		err = db.QueryRow(dbSQL, dbId).Scan(&dbAllowConns)
-		if err != nil {
-			return fmt.Errorf("Error reading ALLOW_CONNECTIONS property for DATABASE: %w", err)
+func validateDBSettings(v interface{}, key string) (warnings []string, errors []error) {
+	for name := range v.(map[string]interface{}) {
+		if err := validateDBSettingName(name); err != nil {
+			errors = append(errors, fmt.Errorf("%s: %w", key, err))
 		}
-
-		d.Set(dbAllowConnsAttr, dbAllowConns)
 	}
+	return
+}
 
-	if db.featureSupported(featureDBIsTemplate) {
-		var dbIsTemplate bool
-		dbSQL := fmt.Sprintf(dbSQLFmt, "d.datistemplate")
-		err = db.QueryRow(dbSQL, dbId).Scan(&dbIsTemplate)
-		if err != nil {
-			return fmt.Errorf("Error reading IS_TEMPLATE property for DATABASE: %w", err)
-		}
+// validateDBRawSetting validates an element of raw_settings, formatted as name=value.
+func validateDBRawSetting(v interface{}, key string) (warnings []string, errors []error) {
+	name, _, found := strings.Cut(v.(string), "=")
+	if !found {
+		return nil, []error{fmt.Errorf("%s: %q must be formatted as name=value", key, v)}
+	}
+	if err := validateDBSettingName(name); err != nil {
+		errors = append(errors, fmt.Errorf("%s: %w", key, err))
+	}
+	return
+}
 
-		d.Set(dbIsTemplateAttr, dbIsTemplate)
+func validateDBSettingName(name string) error {
+	if !settingNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid parameter name %q", name)
 	}
 
+	// These parameters have their own attribute.
+	for _, attr := range append([]string{dbSearchPathAttr, dbDefaultTablespace, dbLogStatementAttr, dbLogMinDurationAttr, dbSessionPreloadAttr, dbStatementTimeoutAttr, dbLockTimeoutAttr, dbToastCompressionAttr}, dbMemorySettings...) {
+		if name == attr {
+			return fmt.Errorf("%s must be set with the %s attribute", name, attr)
+		}
+	}
+	if name == "role" {
+		return fmt.Errorf("role must be set with the %s attribute", dbDefaultRoleAttr)
+	}
 	return nil
 }
 
-func resourcePostgreSQLDatabaseUpdate(db *DBConnection, d *schema.ResourceData) error {
-	if err := setDBName(db, d); err != nil {
-		return err
+// parseDBRawSettings returns the parameters of raw_settings as a map of name to value.
+func parseDBRawSettings(rawSettings []interface{}) (map[string]interface{}, error) {
+	settings := map[string]interface{}{}
+	for _, raw := range rawSettings {
+		name, value, _ := strings.Cut(raw.(string), "=")
+		if _, ok := settings[name]; ok {
+			return nil, fmt.Errorf("parameter %s is set more than once in %s", name, dbRawSettingsAttr)
+		}
+		settings[name] = value
 	}
+	return settings, nil
+}
 
-	if err := setAlterOwnership(db, d); err != nil {
-		return err
+func setDBRawSettings(db QueryAble, d *schema.ResourceData) error {
+	if !d.HasChange(dbRawSettingsAttr) {
+		return nil
 	}
 
-	if err := setDBOwner(db, d); err != nil {
+	oraw, nraw := d.GetChange(dbRawSettingsAttr)
+	o, err := parseDBRawSettings(oraw.([]interface{}))
+	if err != nil {
 		return err
 	}
-
-	if err := setDBTablespace(db, d); err != nil {
+	n, err := parseDBRawSettings(nraw.([]interface{}))
+	if err != nil {
 		return err
 	}
 
-	if err := setDBConnLimit(db, d); err != nil {
-		return err
+	for _, sql := range dbSettingsQueries(d.Get(dbNameAttr).(string), o, n) {
+		if _, err := db.Exec(sql); err != nil {
+			return fmt.Errorf("Error updating database raw settings: %w", err)
+		}
 	}
 
-	if err := setDBAllowConns(db, d); err != nil {
-		return err
+	return nil
+}
+
+func setDBSettings(db QueryAble, d *schema.ResourceData) error {
+	if !d.HasChange(dbSettingsAttr) {
+		return nil
 	}
 
-	if err := setDBIsTemplate(db, d); err != nil {
-		return err
+	oraw, nraw := d.GetChange(dbSettingsAttr)
+	for _, sql := range dbSettingsQueries(d.Get(dbNameAttr).(string), oraw.(map[string]interface{}), nraw.(map[string]interface{})) {
+		if _, err := db.Exec(sql); err != nil {
+			return fmt.Errorf("Error updating database settings: %w", err)
+		}
 	}
 
-	// Empty values: ALTER DATABASE name RESET configuration_parameter;
+	return nil
+}
 
-	return resourcePostgreSQLDatabaseReadImpl(db, d)
+// dbSettingsQueries returns the queries to go from the *o* settings to the *n* ones.
+// Only the changed parameters are set or reset, in a stable order.
+func dbSettingsQueries(dbName string, o, n map[string]interface{}) []string {
+	names := make([]string, 0, len(o)+len(n))
+	for name := range o {
+		if _, ok := n[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	for name, value := range n {
+		if oldValue, ok := o[name]; !ok || oldValue != value {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	queries := make([]string, 0, len(names))
+	for _, name := range names {
+		if value, ok := n[name]; ok && value == dbSettingFromCurrent {
+			queries = append(queries, fmt.Sprintf("ALTER DATABASE %s SET %s FROM CURRENT", pq.QuoteIdentifier(dbName), name))
+		} else if list, isList := value.(dbListSetting); ok && isList {
+			queries = append(queries, fmt.Sprintf("ALTER DATABASE %s SET %s TO %s", pq.QuoteIdentifier(dbName), name, list))
+		} else if ok {
+			queries = append(queries, fmt.Sprintf(
+				"ALTER DATABASE %s SET %s TO %s", pq.QuoteIdentifier(dbName), name, pq.QuoteLiteral(value.(string)),
+			))
+		} else {
+			queries = append(queries, fmt.Sprintf("ALTER DATABASE %s RESET %s", pq.QuoteIdentifier(dbName), name))
+		}
+	}
+
+	return queries
 }
 
-func setDBName(db QueryAble, d *schema.ResourceData) error {
-	if !d.HasChange(dbNameAttr) {
-		return nil
+// setDBPrivilegeRoles grants the privileges on the database to the added roles
+// and revokes them from the removed ones.
+func setDBPrivilegeRoles(db QueryAble, d *schema.ResourceData) error {
+	dbName := d.Get(dbNameAttr).(string)
+
+	for attr, privilege := range dbPrivilegeRolesAttrs {
+		if !d.HasChange(attr) {
+			continue
+		}
+
+		oraw, nraw := d.GetChange(attr)
+		o := oraw.(*schema.Set)
+		n := nraw.(*schema.Set)
+
+		for _, role := range o.Difference(n).List() {
+			sql := fmt.Sprintf(
+				"REVOKE %s ON DATABASE %s FROM %s", privilege, pq.QuoteIdentifier(dbName), pq.QuoteIdentifier(role.(string)),
+			)
+			if _, err := db.Exec(sql); err != nil {
+				return fmt.Errorf("Error revoking %s on database %s from %s: %w", privilege, dbName, role, err)
+			}
+		}
+
+		for _, role := range n.Difference(o).List() {
+			sql := fmt.Sprintf(
+				"GRANT %s ON DATABASE %s TO %s", privilege, pq.QuoteIdentifier(dbName), pq.QuoteIdentifier(role.(string)),
+			)
+			if _, err := db.Exec(sql); err != nil {
+				return fmt.Errorf("Error granting %s on database %s to %s: %w", privilege, dbName, role, err)
+			}
+		}
 	}
 
-	oraw, nraw := d.GetChange(dbNameAttr)
-	o := oraw.(string)
-	n := nraw.(string)
-	if n == "" {
-		return errors.New("Error setting database name to an empty string")
+	return nil
+}
+
+// readDBPrivilegeRoles returns the roles explicitly granted the privilege on the database,
+// PUBLIC and the owner excepted.
+func readDBPrivilegeRoles(db QueryAble, dbName, privilege string) ([]string, error) {
+	rows, err := db.Query(
+		"SELECT pg_catalog.pg_get_userbyid(a.grantee) "+
+			"FROM pg_catalog.pg_database AS d, aclexplode(d.datacl) AS a "+
+			"WHERE d.datname = $1 AND a.privilege_type = $2 AND a.grantee <> 0 AND a.grantee <> d.datdba",
+		dbName, privilege,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading %s privileges of database %s: %w", privilege, dbName, err)
 	}
+	defer rows.Close()
 
-	sql := fmt.Sprintf("ALTER DATABASE %s RENAME TO %s", pq.QuoteIdentifier(o), pq.QuoteIdentifier(n))
-	if _, err := db.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating database name: %w", err)
+	roles := []string{}
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, fmt.Errorf("could not scan role name: %w", err)
+		}
+		roles = append(roles, role)
 	}
-	d.SetId(n)
 
-	return nil
+	return roles, rows.Err()
 }
 
-func setDBOwner(db *DBConnection, d *schema.ResourceData) error {
-	if !d.HasChange(dbOwnerAttr) {
+func setDBPublicSchemaCreate(db *DBConnection, d *schema.ResourceData) error {
+	if !d.HasChange(dbPublicSchemaCreate) {
 		return nil
 	}
 
-	owner := d.Get(dbOwnerAttr).(string)
-	if owner == "" {
+	return doSetDBPublicSchemaCreate(db, d)
+}
+
+// doSetDBPublicSchemaCreate grants or revokes the CREATE privilege on the public schema
+// of the database to PUBLIC. Nothing is done if the attribute is not set.
+func doSetDBPublicSchemaCreate(db *DBConnection, d *schema.ResourceData) error {
+	publicSchemaCreate, ok := d.GetOkExists(dbPublicSchemaCreate) //nolint:staticcheck
+	if !ok {
 		return nil
 	}
-	currentUser := db.client.config.getDatabaseUsername()
 
-	lockTxn, err := startTransaction(db.client, "")
-	if err := pgLockRole(lockTxn, currentUser); err != nil {
+	dbName := d.Get(dbNameAttr).(string)
+	txn, err := startTransaction(db.client, dbName)
+	if err != nil {
 		return err
 	}
-	defer deferredRollback(lockTxn)
+	defer deferredRollback(txn)
 
-	//needed in order to set the owner of the db if the connection user is not a superuser
-	ownerGranted, err := grantRoleMembership(db, owner, currentUser)
+	owner, err := getSchemaOwner(txn, "public")
 	if err != nil {
 		return err
 	}
-	if ownerGranted {
-		defer func() {
-			_, err = revokeRoleMembership(db, owner, currentUser)
-		}()
+	owners, err := resolveOwners(txn, []string{owner})
+	if err != nil {
+		return err
 	}
 
-	dbName := d.Get(dbNameAttr).(string)
-
-	sql := fmt.Sprintf("ALTER DATABASE %s OWNER TO %s", pq.QuoteIdentifier(dbName), pq.QuoteIdentifier(owner))
-	if _, err := db.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating database OWNER: %w", err)
+	query := "REVOKE CREATE ON SCHEMA public FROM PUBLIC"
+	if publicSchemaCreate.(bool) {
+		query = "GRANT CREATE ON SCHEMA public TO PUBLIC"
 	}
 
-	return err
-}
-
-func setAlterOwnership(db *DBConnection, d *schema.ResourceData) error {
-	if !d.HasChange(dbOwnerAttr) && !d.HasChange(dbAlterObjectOwnership) {
-		return nil
-	}
-	owner := d.Get(dbOwnerAttr).(string)
-	if owner == "" {
-		return nil
+	if err := withRolesGranted(txn, owners, func() error {
+		_, err := txn.Exec(query)
+		return err
+	}); err != nil {
+		return fmt.Errorf("Error updating CREATE privilege on public schema of database %q: %w", dbName, err)
 	}
 
-	alterOwnership := d.Get(dbAlterObjectOwnership).(bool)
-	if !alterOwnership {
-		return nil
+	if err := txn.Commit(); err != nil {
+		return fmt.Errorf("Error committing public schema privileges: %w", err)
 	}
-	currentUser := db.client.config.getDatabaseUsername()
 
-	dbName := d.Get(dbNameAttr).(string)
-
-	lockTxn, err := startTransaction(db.client, dbName)
-	if err := pgLockRole(lockTxn, currentUser); err != nil {
-		return err
-	}
-	defer deferredRollback(lockTxn)
+	return nil
+}
 
-	currentOwner, err := getDatabaseOwner(db, dbName)
+// getDBPublicSchemaCreate returns true if PUBLIC has the CREATE privilege
+// on the public schema of the database.
+func getDBPublicSchemaCreate(db *DBConnection, dbName string) (bool, error) {
+	txn, err := startTransaction(db.client, dbName)
 	if err != nil {
-		return fmt.Errorf("Error getting current database OWNER: %w", err)
+		return false, err
 	}
+	defer deferredRollback(txn)
 
-	newOwner := d.Get(dbOwnerAttr).(string)
-
-	if currentOwner == newOwner {
-		return nil
+	var publicSchemaCreate bool
+	err = txn.QueryRow(
+		"SELECT COALESCE(has_schema_privilege('public', n.oid, 'CREATE'), false) FROM pg_catalog.pg_namespace AS n WHERE n.nspname = 'public'",
+	).Scan(&publicSchemaCreate)
+	switch {
+	case err == sql.ErrNoRows:
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("Error reading CREATE privilege on public schema of database %q: %w", dbName, err)
 	}
 
-	currentOwnerGranted, err := grantRoleMembership(db, currentOwner, currentUser)
+	return publicSchemaCreate, nil
+}
+
+// getDBColocationEffective returns true if the database is colocated.
+// It is always false if the server is not YugabyteDB.
+func getDBColocationEffective(db *DBConnection, dbName string) (bool, error) {
+	var isYugabyte bool
+	err := db.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM pg_catalog.pg_proc AS p, pg_catalog.pg_namespace AS n " +
+			"WHERE p.pronamespace = n.oid AND n.nspname = 'pg_catalog' AND p.proname = 'yb_is_database_colocated')",
+	).Scan(&isYugabyte)
 	if err != nil {
-		return err
+		return false, fmt.Errorf("Error checking if the server is YugabyteDB: %w", err)
 	}
-	if currentOwnerGranted {
-		defer func() {
-			_, err = revokeRoleMembership(db, currentOwner, currentUser)
-		}()
+	if !isYugabyte {
+		return false, nil
 	}
-	sql := fmt.Sprintf("REASSIGN OWNED BY %s TO %s", pq.QuoteIdentifier(currentOwner), pq.QuoteIdentifier(newOwner))
-	if _, err := lockTxn.Exec(sql); err != nil {
-		return fmt.Errorf("Error reassigning objects owned by '%s': %w", currentOwner, err)
+
+	// yb_is_database_colocated only reports the database it is executed in.
+	txn, err := startTransaction(db.client, dbName)
+	if err != nil {
+		return false, err
 	}
+	defer deferredRollback(txn)
 
-	if err := lockTxn.Commit(); err != nil {
-		return fmt.Errorf("error committing reassign: %w", err)
+	var colocated bool
+	if err := txn.QueryRow("SELECT pg_catalog.yb_is_database_colocated()").Scan(&colocated); err != nil {
+		return false, fmt.Errorf("Error reading colocation of database %q: %w", dbName, err)
 	}
-	return nil
+
+	return colocated, nil
 }
 
-func setDBTablespace(db QueryAble, d *schema.ResourceData) error {
-	if !d.HasChange(dbTablespaceAttr) {
-		return nil
-	}
+// dbCreatedAtTracker records and reads the creation time of a database,
+// as PostgreSQL does not store it.
+type dbCreatedAtTracker interface {
+	record(db *DBConnection, dbName string, createdAt time.Time) error
+	// read returns the recorded creation time (RFC3339) or an empty string if unknown.
+	read(db *DBConnection, dbName string) (string, error)
+	forget(db *DBConnection, dbName string) error
+}
 
-	tbspName := d.Get(dbTablespaceAttr).(string)
-	dbName := d.Get(dbNameAttr).(string)
-	var sql string
-	if tbspName == "" || strings.ToUpper(tbspName) == "DEFAULT" {
-		sql = fmt.Sprintf("ALTER DATABASE %s RESET TABLESPACE", pq.QuoteIdentifier(dbName))
-	} else {
-		sql = fmt.Sprintf("ALTER DATABASE %s SET TABLESPACE %s", pq.QuoteIdentifier(dbName), pq.QuoteIdentifier(tbspName))
+// dbCreatedAtTrackers are the available mechanisms to track the creation time
+// of databases. Tracking is disabled if the configured one is not in this map (i.e.: "none").
+var dbCreatedAtTrackers = map[string]dbCreatedAtTracker{
+	"comment": dbCommentCreatedAtTracker{},
+	"table":   dbTableCreatedAtTracker{},
+}
+
+const dbCreatedAtCommentPrefix = "terraform:created_at="
+
+// dbCommentCreatedAtTracker stores the creation time in the comment of the database.
+// Note that it overrides any existing comment.
+type dbCommentCreatedAtTracker struct{}
+
+func (dbCommentCreatedAtTracker) record(db *DBConnection, dbName string, createdAt time.Time) error {
+	sql := fmt.Sprintf(
+		"COMMENT ON DATABASE %s IS %s",
+		pq.QuoteIdentifier(dbName), pq.QuoteLiteral(dbCreatedAtCommentPrefix+createdAt.Format(time.RFC3339)),
+	)
+	_, err := db.Exec(sql)
+	return err
+}
+
+func (dbCommentCreatedAtTracker) read(db *DBConnection, dbName string) (string, error) {
+	var comment sql.NullString
+	err := db.QueryRow(
+		"SELECT pg_catalog.shobj_description(oid, 'pg_database') FROM pg_catalog.pg_database WHERE datname = $1",
+		dbName,
+	).Scan(&comment)
+	switch {
+	case err == sql.ErrNoRows:
+		return "", nil
+	case err != nil:
+		return "", err
 	}
 
-	if _, err := db.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating database TABLESPACE: %w", err)
+	if !strings.HasPrefix(comment.String, dbCreatedAtCommentPrefix) {
+		return "", nil
 	}
+	return strings.TrimPrefix(comment.String, dbCreatedAtCommentPrefix), nil
+}
 
+func (dbCommentCreatedAtTracker) forget(db *DBConnection, dbName string) error {
+	// The comment is dropped with the database.
 	return nil
 }
 
-func setDBConnLimit(db QueryAble, d *schema.ResourceData) error {
-	if !d.HasChange(dbConnLimitAttr) {
-		return nil
+const dbCreatedAtTable = "terraform_database_metadata"
+
+// dbTableCreatedAtTracker stores the creation time in a table managed by the provider
+// in the database the provider connects to. Rows are keyed by database oid so they survive renames.
+type dbTableCreatedAtTracker struct{}
+
+func (dbTableCreatedAtTracker) record(db *DBConnection, dbName string, createdAt time.Time) error {
+	txn, err := startTransaction(db.client, "")
+	if err != nil {
+		return err
 	}
+	defer deferredRollback(txn)
 
-	connLimit := d.Get(dbConnLimitAttr).(int)
-	dbName := d.Get(dbNameAttr).(string)
-	sql := fmt.Sprintf("ALTER DATABASE %s CONNECTION LIMIT = %d", pq.QuoteIdentifier(dbName), connLimit)
-	if _, err := db.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating database CONNECTION LIMIT: %w", err)
+	queries := []string{
+		fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s (database_oid oid PRIMARY KEY, created_at timestamptz NOT NULL)",
+			pq.QuoteIdentifier(dbCreatedAtTable),
+		),
+		fmt.Sprintf(
+			"DELETE FROM %s WHERE database_oid = (SELECT oid FROM pg_catalog.pg_database WHERE datname = $1)",
+			pq.QuoteIdentifier(dbCreatedAtTable),
+		),
+		fmt.Sprintf(
+			"INSERT INTO %s SELECT oid, $2 FROM pg_catalog.pg_database WHERE datname = $1",
+			pq.QuoteIdentifier(dbCreatedAtTable),
+		),
+	}
+	args := [][]interface{}{nil, {dbName}, {dbName, createdAt}}
+	for i, query := range queries {
+		if _, err := txn.Exec(query, args[i]...); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return txn.Commit()
 }
 
-func setDBAllowConns(db *DBConnection, d *schema.ResourceData) error {
-	if !d.HasChange(dbAllowConnsAttr) {
-		return nil
+func (dbTableCreatedAtTracker) read(db *DBConnection, dbName string) (string, error) {
+	var createdAt time.Time
+	err := db.QueryRow(
+		fmt.Sprintf(
+			"SELECT m.created_at FROM %s AS m JOIN pg_catalog.pg_database AS d ON d.oid = m.database_oid WHERE d.datname = $1",
+			pq.QuoteIdentifier(dbCreatedAtTable),
+		),
+		dbName,
+	).Scan(&createdAt)
+	switch {
+	case err == sql.ErrNoRows:
+		return "", nil
+	case isPQErrorCode(err, pqErrorCodeUndefinedTable):
+		log.Printf("[WARN] table %s not found while reading creation time of database %q", dbCreatedAtTable, dbName)
+		return "", nil
+	case err != nil:
+		return "", err
 	}
+	return createdAt.UTC().Format(time.RFC3339), nil
+}
 
-	if !db.featureSupported(featureDBAllowConnections) {
-		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support database ALLOW_CONNECTIONS", db.version.String())
+func (dbTableCreatedAtTracker) forget(db *DBConnection, dbName string) error {
+	_, err := db.Exec(
+		fmt.Sprintf(
+			"DELETE FROM %s WHERE database_oid = (SELECT oid FROM pg_catalog.pg_database WHERE datname = $1)",
+			pq.QuoteIdentifier(dbCreatedAtTable),
+		),
+		dbName,
+	)
+	if isPQErrorCode(err, pqErrorCodeUndefinedTable) {
+		return nil
 	}
+	return err
+}
 
-	allowConns := d.Get(dbAllowConnsAttr).(bool)
-	dbName := d.Get(dbNameAttr).(string)
-	sql := fmt.Sprintf("ALTER DATABASE %s ALLOW_CONNECTIONS %t", pq.QuoteIdentifier(dbName), allowConns)
-	if _, err := db.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating database ALLOW_CONNECTIONS: %w", err)
+func validateRoleConnLimits(v interface{}, key string) (warnings []string, errors []error) {
+	for role, limit := range v.(map[string]interface{}) {
+		if limit.(int) < -1 {
+			errors = append(errors, fmt.Errorf("expected %s.%s to be at least (-1), got %d", key, role, limit.(int)))
+		}
 	}
-
-	return nil
+	return
 }
 
-func setDBIsTemplate(db *DBConnection, d *schema.ResourceData) error {
-	if !d.HasChange(dbIsTemplateAttr) {
+func setDBRoleConnLimits(db QueryAble, d *schema.ResourceData) error {
+	if !d.HasChange(dbRoleConnLimitsAttr) {
 		return nil
 	}
 
-	if err := doSetDBIsTemplate(db, d.Get(dbNameAttr).(string), d.Get(dbIsTemplateAttr).(bool)); err != nil {
-		return fmt.Errorf("Error updating database IS_TEMPLATE: %w", err)
+	oraw, nraw := d.GetChange(dbRoleConnLimitsAttr)
+	o := oraw.(map[string]interface{})
+	n := nraw.(map[string]interface{})
+
+	// Roles removed from the configuration get their limit back to unlimited.
+	for role := range o {
+		if _, ok := n[role]; !ok {
+			if err := resetRoleConnLimit(db, role); err != nil {
+				return err
+			}
+		}
+	}
+
+	for role, limit := range n {
+		sql := fmt.Sprintf("ALTER ROLE %s CONNECTION LIMIT %d", pq.QuoteIdentifier(role), limit.(int))
+		if _, err := db.Exec(sql); err != nil {
+			return fmt.Errorf("Error updating CONNECTION LIMIT of role %s: %w", role, err)
+		}
 	}
 
 	return nil
 }
 
-func doSetDBIsTemplate(db *DBConnection, dbName string, isTemplate bool) error {
-	if !db.featureSupported(featureDBIsTemplate) {
-		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support database IS_TEMPLATE", db.version.String())
+// resetRoleConnLimit removes the connection limit of the role if it still exists.
+func resetRoleConnLimit(db QueryAble, role string) error {
+	var exists bool
+	if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = $1)", role).Scan(&exists); err != nil {
+		return fmt.Errorf("could not check if role %s exists: %w", role, err)
+	}
+	if !exists {
+		return nil
 	}
 
-	sql := fmt.Sprintf("ALTER DATABASE %s IS_TEMPLATE %t", pq.QuoteIdentifier(dbName), isTemplate)
+	sql := fmt.Sprintf("ALTER ROLE %s CONNECTION LIMIT -1", pq.QuoteIdentifier(role))
 	if _, err := db.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating database IS_TEMPLATE: %w", err)
+		return fmt.Errorf("Error resetting CONNECTION LIMIT of role %s: %w", role, err)
 	}
-
 	return nil
 }
 
-func terminateBConnections(db *DBConnection, dbName string) error {
-	var terminateSql string
-
-	if db.featureSupported(featureDBAllowConnections) {
-		alterSql := fmt.Sprintf("ALTER DATABASE %s ALLOW_CONNECTIONS false", pq.QuoteIdentifier(dbName))
-
-		if _, err := db.Exec(alterSql); err != nil {
-			return fmt.Errorf("Error blocking connections to database: %w", err)
+// readDBRoleConnLimits reads the current connection limit of the roles managed
+// by the resource. Roles which do not exist anymore are ignored.
+func readDBRoleConnLimits(db QueryAble, d *schema.ResourceData) (map[string]interface{}, error) {
+	roleConnLimits := make(map[string]interface{})
+	for role := range d.Get(dbRoleConnLimitsAttr).(map[string]interface{}) {
+		var connLimit int
+		err := db.QueryRow("SELECT rolconnlimit FROM pg_catalog.pg_roles WHERE rolname = $1", role).Scan(&connLimit)
+		switch {
+		case err == sql.ErrNoRows:
+			log.Printf("[WARN] PostgreSQL role (%q) not found while reading its connection limit", role)
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("Error reading CONNECTION LIMIT of role %s: %w", role, err)
 		}
+		roleConnLimits[role] = connLimit
 	}
-	pid := "procpid"
-	if db.featureSupported(featurePid) {
-		pid = "pid"
-	}
-	terminateSql = fmt.Sprintf("SELECT pg_terminate_backend(%s) FROM pg_stat_activity WHERE datname = '%s' AND %s <> pg_backend_pid()", pid, dbName, pid)
-	if _, err := db.Exec(terminateSql); err != nil {
-		return fmt.Errorf("Error terminating database connections: %w", err)
-	}
+	return roleConnLimits, nil
+}
 
-	return nil
+// readDataChecksums returns true if data checksums are enabled on the cluster.
+// YugabyteDB does not use the PostgreSQL data pages, its storage layer checksums
+// the data by itself: data_checksums is off there.
+func readDataChecksums(db QueryAble) (bool, error) {
+	var checksums bool
+	if err := db.QueryRow("SELECT pg_catalog.current_setting('data_checksums') = 'on'").Scan(&checksums); err != nil {
+		return false, fmt.Errorf("could not read data_checksums: %w", err)
+	}
+	return checksums, nil
 }
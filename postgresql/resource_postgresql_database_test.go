@@ -1,16 +1,1015 @@
 package postgresql
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/blang/semver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
 )
 
+func TestCreateDBSearchPathQuery(t *testing.T) {
+	cases := []struct {
+		resource map[string]interface{}
+		expected string
+		wantErr  bool
+	}{
+		{
+			resource: map[string]interface{}{
+				"name": "mydb",
+			},
+			expected: `ALTER DATABASE "mydb" RESET search_path`,
+		},
+		{
+			resource: map[string]interface{}{
+				"name":        "mydb",
+				"search_path": []interface{}{"public"},
+			},
+			expected: `ALTER DATABASE "mydb" SET search_path TO "public"`,
+		},
+		{
+			resource: map[string]interface{}{
+				"name":        "mydb",
+				"search_path": []interface{}{"$user", "foo-with-hyphen", "Mixed Case", `with"quote`, "foo, bar"},
+			},
+			expected: `ALTER DATABASE "mydb" SET search_path TO "$user", "foo-with-hyphen", "Mixed Case", "with""quote", "foo, bar"`,
+		},
+		{
+			resource: map[string]interface{}{
+				"name":             "mydb",
+				"search_path_mode": "inherit",
+			},
+			expected: `ALTER DATABASE "mydb" SET search_path FROM CURRENT`,
+		},
+		{
+			resource: map[string]interface{}{
+				"name":             "mydb",
+				"search_path_mode": "reset",
+			},
+			expected: `ALTER DATABASE "mydb" RESET search_path`,
+		},
+		{
+			resource: map[string]interface{}{
+				"name":             "mydb",
+				"search_path_mode": "explicit",
+				"search_path":      []interface{}{"foo"},
+			},
+			expected: `ALTER DATABASE "mydb" SET search_path TO "foo"`,
+		},
+	}
+
+	for _, c := range cases {
+		out, err := createDBSearchPathQuery(schema.TestResourceDataRaw(t, resourcePostgreSQLDatabase().Schema, c.resource))
+		if c.wantErr {
+			if err == nil {
+				t.Fatalf("Expected an error for %#v", c.resource)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if out != c.expected {
+			t.Fatalf("Error matching output and expected: %#v vs %#v", out, c.expected)
+		}
+	}
+}
+
+func TestParseDBIdentifierList(t *testing.T) {
+	for _, c := range []struct {
+		value    string
+		expected []string
+	}{
+		{value: "public", expected: []string{"public"}},
+		{value: `"$user", public`, expected: []string{"$user", "public"}},
+		{value: `"Mixed Case", "with""quote", "foo, bar", """"`, expected: []string{"Mixed Case", `with"quote`, "foo, bar", `"`}},
+		{value: `a,b ,  c`, expected: []string{"a", "b", "c"}},
+		{value: `"$libdir/plugins/pg_hint_plan",auto_explain`, expected: []string{"$libdir/plugins/pg_hint_plan", "auto_explain"}},
+	} {
+		assert.Equal(t, c.expected, parseDBIdentifierList(c.value), c.value)
+	}
+}
+
+func TestValidateRoleConnLimits(t *testing.T) {
+	cases := []struct {
+		input   map[string]interface{}
+		wantErr bool
+	}{
+		{input: map[string]interface{}{}},
+		{input: map[string]interface{}{"foo": -1, "bar": 0, "baz": 10}},
+		{input: map[string]interface{}{"foo": 1, "bar": -2}, wantErr: true},
+	}
+
+	for _, c := range cases {
+		_, errs := validateRoleConnLimits(c.input, dbRoleConnLimitsAttr)
+		if c.wantErr != (len(errs) > 0) {
+			t.Errorf("validateRoleConnLimits(%v) returned %v, expected error: %t", c.input, errs, c.wantErr)
+		}
+	}
+}
+
+func TestMemorySizeRegexp(t *testing.T) {
+	for _, v := range []string{"64MB", "1GB", "512kB", "1TB", "8192"} {
+		if !memorySizeRegexp.MatchString(v) {
+			t.Errorf("%q should be a valid memory size", v)
+		}
+	}
+	for _, v := range []string{"", "64mb", "1.5GB", "-1MB", "64 MB", "1PB"} {
+		if memorySizeRegexp.MatchString(v) {
+			t.Errorf("%q should not be a valid memory size", v)
+		}
+	}
+}
+
+func TestReadDBSetting(t *testing.T) {
+	dbConfig := pq.ByteaArray{[]byte("work_mem=64MB"), []byte("maintenance_work_mem=1GB")}
+
+	if v := readDBSetting(dbConfig, "work_mem"); v != "64MB" {
+		t.Errorf("readDBSetting returned %q for work_mem, expected 64MB", v)
+	}
+	if v := readDBSetting(dbConfig, "maintenance_work_mem"); v != "1GB" {
+		t.Errorf("readDBSetting returned %q for maintenance_work_mem, expected 1GB", v)
+	}
+	if v := readDBSetting(dbConfig, "temp_buffers"); v != "" {
+		t.Errorf("readDBSetting returned %q for temp_buffers, expected an empty string", v)
+	}
+}
+
+func TestReadDBSettings(t *testing.T) {
+	dbConfig := pq.ByteaArray{
+		[]byte("work_mem=64MB"),
+		[]byte("search_path=\"$user\", public"),
+		[]byte("app.filter=a=b"),
+	}
+	assert.Equal(t, map[string]string{
+		"work_mem":    "64MB",
+		"search_path": "\"$user\", public",
+		"app.filter":  "a=b",
+	}, readDBSettings(dbConfig))
+
+	assert.Equal(t, map[string]string{}, readDBSettings(nil))
+}
+
+func TestDBSessionsState(t *testing.T) {
+	sessions := []dbSession{
+		{pid: 4242, usename: "app", applicationName: "psql", state: "idle in transaction"},
+		// The state of the sessions of the other roles is hidden without pg_read_all_stats.
+		{pid: 4343, usename: "batch", applicationName: "", state: ""},
+	}
+	assert.Equal(t, []map[string]interface{}{
+		{"pid": 4242, "usename": "app", "application_name": "psql", "state": "idle in transaction"},
+		{"pid": 4343, "usename": "batch", "application_name": "", "state": ""},
+	}, dbSessionsState(sessions))
+
+	assert.Equal(t, []map[string]interface{}{}, dbSessionsState(nil))
+
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLDatabase().Schema, map[string]interface{}{"name": "test_db"})
+	assert.NoError(t, d.Set(dbActiveSessionsAttr, dbSessionsState(sessions)))
+	assert.Equal(t, 2, d.Get(dbActiveSessionsAttr+".#"))
+	assert.Equal(t, 4343, d.Get(dbActiveSessionsAttr+".1.pid"))
+	assert.Equal(t, "idle in transaction", d.Get(dbActiveSessionsAttr+".0.state"))
+}
+
+func TestReadDBDurationSetting(t *testing.T) {
+	var tests = []struct {
+		value string
+		want  int
+	}{
+		{"", -1},
+		{"500", 500},
+		{"-1", -1},
+		{"250ms", 250},
+		{"2s", 2000},
+		{"1min", 60000},
+		{"1500us", 1},
+	}
+
+	for _, test := range tests {
+		dbConfig := pq.ByteaArray{}
+		if test.value != "" {
+			dbConfig = append(dbConfig, []byte("log_min_duration_statement="+test.value))
+		}
+
+		got, err := readDBDurationSetting(dbConfig, "log_min_duration_statement")
+		if err != nil {
+			t.Errorf("readDBDurationSetting returned an error for %q: %v", test.value, err)
+		}
+		if got != test.want {
+			t.Errorf("readDBDurationSetting returned %d for %q, expected %d", got, test.value, test.want)
+		}
+	}
+
+	if _, err := readDBDurationSetting(pq.ByteaArray{[]byte("log_min_duration_statement=1 week")}, "log_min_duration_statement"); err == nil {
+		t.Error("readDBDurationSetting should return an error for an invalid value")
+	}
+}
+
+func TestDBSettingsQueries(t *testing.T) {
+	o := map[string]interface{}{}
+	for i := 0; i < 10; i++ {
+		o[fmt.Sprintf("app.setting_%d", i)] = strconv.Itoa(i)
+	}
+
+	// Changing one parameter of ten only alters this one.
+	n := map[string]interface{}{}
+	for k, v := range o {
+		n[k] = v
+	}
+	n["app.setting_3"] = "changed"
+
+	queries := dbSettingsQueries("my db", o, n)
+	expected := []string{`ALTER DATABASE "my db" SET app.setting_3 TO 'changed'`}
+	if !reflect.DeepEqual(queries, expected) {
+		t.Fatalf("dbSettingsQueries returned %#v, expected %#v", queries, expected)
+	}
+
+	// Added and removed parameters.
+	delete(n, "app.setting_5")
+	n["app.setting_10"] = "10"
+	queries = dbSettingsQueries("my db", o, n)
+	expected = []string{
+		`ALTER DATABASE "my db" SET app.setting_10 TO '10'`,
+		`ALTER DATABASE "my db" SET app.setting_3 TO 'changed'`,
+		`ALTER DATABASE "my db" RESET app.setting_5`,
+	}
+	if !reflect.DeepEqual(queries, expected) {
+		t.Fatalf("dbSettingsQueries returned %#v, expected %#v", queries, expected)
+	}
+
+	if queries := dbSettingsQueries("my db", o, o); len(queries) != 0 {
+		t.Fatalf("dbSettingsQueries returned %#v for unchanged settings", queries)
+	}
+
+	// The current value is pinned with FROM CURRENT rather than set as a literal.
+	queries = dbSettingsQueries("my db", o, map[string]interface{}{"app.setting_0": "0", "app.setting_1": dbSettingFromCurrent})
+	assert.Equal(t, `ALTER DATABASE "my db" SET app.setting_1 FROM CURRENT`, queries[0])
+}
+
+func TestPinnedDBSettingValue(t *testing.T) {
+	assert.Equal(t, dbSettingFromCurrent, pinnedDBSettingValue(dbSettingFromCurrent, "100"))
+	assert.Equal(t, "100", pinnedDBSettingValue("50", "100"))
+	assert.Equal(t, "100", pinnedDBSettingValue("100", "100"))
+}
+
+func TestCreateDatabaseQuery(t *testing.T) {
+	// YugabyteDB reports the version of PostgreSQL it is based on.
+	db := &DBConnection{client: &Client{}, version: semver.MustParse("11.2.0")}
+
+	cases := []struct {
+		resource map[string]interface{}
+		expected string
+	}{
+		{
+			resource: map[string]interface{}{"name": "mydb"},
+			expected: `CREATE DATABASE "mydb" WITH OWNER "alice" TEMPLATE template0 ENCODING 'UTF8'  ALLOW_CONNECTIONS true CONNECTION LIMIT -1`,
+		},
+		{
+			// The options follow a single WITH, COLOCATION is last and template0 is not forced.
+			resource: map[string]interface{}{"name": "mydb", "colocation": true},
+			expected: `CREATE DATABASE "mydb" WITH OWNER "alice" ENCODING 'UTF8'  ALLOW_CONNECTIONS true CONNECTION LIMIT -1 COLOCATION = true`,
+		},
+		{
+			resource: map[string]interface{}{"name": "mydb", "colocation": true, "template": "template1"},
+			expected: `CREATE DATABASE "mydb" WITH OWNER "alice" TEMPLATE "template1" ENCODING 'UTF8'  ALLOW_CONNECTIONS true CONNECTION LIMIT -1 COLOCATION = true`,
+		},
+		{
+			// The OID is emitted before the colocation.
+			resource: map[string]interface{}{"name": "mydb", "oid": 20000, "colocation": true},
+			expected: `CREATE DATABASE "mydb" WITH OWNER "alice" ENCODING 'UTF8'  ALLOW_CONNECTIONS true CONNECTION LIMIT -1 OID 20000 COLOCATION = true`,
+		},
+		{
+			// A reserved word is a valid name as it is quoted.
+			resource: map[string]interface{}{"name": "order", "template": "user"},
+			expected: `CREATE DATABASE "order" WITH OWNER "alice" TEMPLATE "user" ENCODING 'UTF8'  ALLOW_CONNECTIONS true CONNECTION LIMIT -1`,
+		},
+		{
+			// IS_TEMPLATE is only emitted if configured, false included.
+			resource: map[string]interface{}{"name": "mydb", "is_template": false},
+			expected: `CREATE DATABASE "mydb" WITH OWNER "alice" TEMPLATE template0 ENCODING 'UTF8'  ALLOW_CONNECTIONS true CONNECTION LIMIT -1 IS_TEMPLATE false`,
+		},
+		{
+			resource: map[string]interface{}{"name": "mydb", "is_template": true},
+			expected: `CREATE DATABASE "mydb" WITH OWNER "alice" TEMPLATE template0 ENCODING 'UTF8'  ALLOW_CONNECTIONS true CONNECTION LIMIT -1 IS_TEMPLATE true`,
+		},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLDatabase().Schema, c.resource)
+		query := createDatabaseQuery(db, d, "alice", "PostgreSQL 11.2-YB-2.18.0.0-b0 on x86_64-pc-linux-gnu")
+		if query != c.expected {
+			t.Errorf("createDatabaseQuery(%v) returned:\n%s\nexpected:\n%s", c.resource, query, c.expected)
+		}
+		if strings.Count(query, "WITH") != 1 {
+			t.Errorf("createDatabaseQuery(%v) must have a single WITH: %s", c.resource, query)
+		}
+	}
+}
+
+func TestCreateDatabaseQueryColocationSyntax(t *testing.T) {
+	db := &DBConnection{client: &Client{}, version: semver.MustParse("11.2.0")}
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLDatabase().Schema, map[string]interface{}{
+		"name":       "mydb",
+		"colocation": true,
+	})
+
+	cases := []struct {
+		pgVersion string
+		expected  string
+	}{
+		{"PostgreSQL 11.2-YB-2.14.0.0-b0 on x86_64-pc-linux-gnu", `CREATE DATABASE "mydb" WITH OWNER "alice" ENCODING 'UTF8'  ALLOW_CONNECTIONS true CONNECTION LIMIT -1 COLOCATION = true`},
+		{"PostgreSQL 11.2-YB-2.20.1.0-b97 on x86_64-pc-linux-gnu", `CREATE DATABASE "mydb" WITH OWNER "alice" ENCODING 'UTF8'  ALLOW_CONNECTIONS true CONNECTION LIMIT -1 COLOCATION = true`},
+		{"PostgreSQL 15.2-YB-2.25.0.0-b0 on x86_64-pc-linux-gnu", `CREATE DATABASE "mydb" OWNER "alice" ENCODING 'UTF8'  ALLOW_CONNECTIONS true CONNECTION LIMIT -1 WITH (COLOCATION = true)`},
+		{"PostgreSQL 15.12-YB-2025.1.0.0-b0 on x86_64-pc-linux-gnu", `CREATE DATABASE "mydb" OWNER "alice" ENCODING 'UTF8'  ALLOW_CONNECTIONS true CONNECTION LIMIT -1 WITH (COLOCATION = true)`},
+		// Not a YugabyteDB server, or the version is unknown.
+		{"PostgreSQL 16.1 on x86_64-pc-linux-gnu", `CREATE DATABASE "mydb" WITH OWNER "alice" ENCODING 'UTF8'  ALLOW_CONNECTIONS true CONNECTION LIMIT -1 COLOCATION = true`},
+		{"", `CREATE DATABASE "mydb" WITH OWNER "alice" ENCODING 'UTF8'  ALLOW_CONNECTIONS true CONNECTION LIMIT -1 COLOCATION = true`},
+	}
+
+	for _, c := range cases {
+		if query := createDatabaseQuery(db, d, "alice", c.pgVersion); query != c.expected {
+			t.Errorf("createDatabaseQuery with version %q returned:\n%s\nexpected:\n%s", c.pgVersion, query, c.expected)
+		}
+	}
+}
+
+func TestConnLimitSupportedBy(t *testing.T) {
+	cases := []struct {
+		pgVersion string
+		expected  bool
+	}{
+		{"PostgreSQL 9.6.24 on x86_64-pc-linux-gnu", true},
+		{"PostgreSQL 16.1 on x86_64-pc-linux-gnu", true},
+		{"", true},
+		{"PostgreSQL 11.2-YB-2.6.1.0-b0 on x86_64-pc-linux-gnu", false},
+		{"PostgreSQL 11.2-YB-2.12.9.0-b0 on x86_64-pc-linux-gnu", false},
+		{"PostgreSQL 11.2-YB-2.14.0.0-b0 on x86_64-pc-linux-gnu", true},
+		{"PostgreSQL 11.2-YB-2.20.1.0-b97 on x86_64-pc-linux-gnu", true},
+		{"PostgreSQL 15.2-YB-2.25.0.0-b0 on x86_64-pc-linux-gnu", true},
+		{"PostgreSQL 15.12-YB-2025.1.0.0-b0 on x86_64-pc-linux-gnu", true},
+	}
+
+	for _, c := range cases {
+		if out := connLimitSupportedBy(c.pgVersion); out != c.expected {
+			t.Errorf("connLimitSupportedBy(%q) returned %t, expected %t", c.pgVersion, out, c.expected)
+		}
+	}
+}
+
+func TestCreateDatabaseQueryConnLimit(t *testing.T) {
+	db := &DBConnection{client: &Client{}, version: semver.MustParse("11.2.0")}
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLDatabase().Schema, map[string]interface{}{
+		"name":             "mydb",
+		"connection_limit": 10,
+	})
+
+	cases := []struct {
+		pgVersion string
+		expected  string
+	}{
+		{"PostgreSQL 11.2-YB-2.18.0.0-b0 on x86_64-pc-linux-gnu", `CREATE DATABASE "mydb" WITH OWNER "alice" TEMPLATE template0 ENCODING 'UTF8'  ALLOW_CONNECTIONS true CONNECTION LIMIT 10`},
+		// The versions which do not enforce it are not given the connection limit.
+		{"PostgreSQL 11.2-YB-2.8.0.0-b0 on x86_64-pc-linux-gnu", `CREATE DATABASE "mydb" WITH OWNER "alice" TEMPLATE template0 ENCODING 'UTF8'  ALLOW_CONNECTIONS true`},
+		{"PostgreSQL 11.22 on x86_64-pc-linux-gnu", `CREATE DATABASE "mydb" WITH OWNER "alice" TEMPLATE template0 ENCODING 'UTF8'  ALLOW_CONNECTIONS true CONNECTION LIMIT 10`},
+	}
+
+	for _, c := range cases {
+		if query := createDatabaseQuery(db, d, "alice", c.pgVersion); query != c.expected {
+			t.Errorf("createDatabaseQuery with version %q returned:\n%s\nexpected:\n%s", c.pgVersion, query, c.expected)
+		}
+	}
+}
+
+func TestValidateDBSettings(t *testing.T) {
+	cases := []struct {
+		input   map[string]interface{}
+		wantErr bool
+	}{
+		{input: map[string]interface{}{"idle_in_transaction_session_timeout": "30s", "app.tenant": "acme"}},
+		{input: map[string]interface{}{"Idle_In_Transaction_Session_Timeout": "30s"}, wantErr: true},
+		{input: map[string]interface{}{"work_mem; DROP": "1"}, wantErr: true},
+		{input: map[string]interface{}{"search_path": "public"}, wantErr: true},
+		{input: map[string]interface{}{"work_mem": "64MB"}, wantErr: true},
+		{input: map[string]interface{}{"role": "app"}, wantErr: true},
+		{input: map[string]interface{}{"statement_timeout": "30s"}, wantErr: true},
+	}
+
+	for _, c := range cases {
+		_, errs := validateDBSettings(c.input, dbSettingsAttr)
+		if c.wantErr != (len(errs) > 0) {
+			t.Errorf("validateDBSettings(%v) returned %v, expected error: %t", c.input, errs, c.wantErr)
+		}
+	}
+}
+
+func TestParseDBRawSettings(t *testing.T) {
+	settings, err := parseDBRawSettings([]interface{}{"app.greeting=hello world", "app.expr=a=b", "app.empty="})
+	if err != nil {
+		t.Fatalf("parseDBRawSettings returned an error: %v", err)
+	}
+	expected := map[string]interface{}{"app.greeting": "hello world", "app.expr": "a=b", "app.empty": ""}
+	if !reflect.DeepEqual(settings, expected) {
+		t.Errorf("parseDBRawSettings returned %v, expected %v", settings, expected)
+	}
+
+	if _, err := parseDBRawSettings([]interface{}{"app.x=1", "app.x=2"}); err == nil {
+		t.Error("parseDBRawSettings should return an error for a duplicated parameter")
+	}
+
+	for _, c := range []struct {
+		input   string
+		wantErr bool
+	}{
+		{input: "idle_in_transaction_session_timeout=30s"},
+		{input: "app.greeting=hello world"},
+		{input: "idle_in_transaction_session_timeout", wantErr: true},
+		{input: "lock_timeout=5s", wantErr: true},
+		{input: "work_mem=64MB", wantErr: true},
+		{input: "Bad Name=1", wantErr: true},
+	} {
+		_, errs := validateDBRawSetting(c.input, dbRawSettingsAttr)
+		if c.wantErr != (len(errs) > 0) {
+			t.Errorf("validateDBRawSetting(%q) returned %v, expected error: %t", c.input, errs, c.wantErr)
+		}
+	}
+}
+
+func TestValidateDBName(t *testing.T) {
+	for _, c := range []struct {
+		input       string
+		wantWarning bool
+	}{
+		{input: "my_db"},
+		{input: "my db"},
+		{input: "MixedCase", wantWarning: true},
+		{input: "UPPER", wantWarning: true},
+	} {
+		warnings, errs := validateDBName(c.input, dbNameAttr)
+		if len(errs) > 0 {
+			t.Errorf("validateDBName(%q) returned errors: %v", c.input, errs)
+		}
+		if c.wantWarning != (len(warnings) > 0) {
+			t.Errorf("validateDBName(%q) returned %v, expected a warning: %t", c.input, warnings, c.wantWarning)
+		}
+	}
+}
+
+func TestDBLocale(t *testing.T) {
+	null := sql.NullString{}
+	str := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+
+	var tests = []struct {
+		locale, provider, icuLocale sql.NullString
+		want                        string
+	}{
+		// libc databases.
+		{str("en_US.UTF-8"), null, null, "en_US.UTF-8"},
+		{str("en_US.UTF-8"), str("c"), null, "en_US.UTF-8"},
+		// ICU databases.
+		{str("en_US.UTF-8"), str("i"), str("en-US"), "en_US.UTF-8"},
+		{str(""), str("i"), str("en-US"), "en-US"},
+		{null, str("i"), str("en-US"), "en-US"},
+		{null, null, null, ""},
+	}
+
+	for _, test := range tests {
+		if got := dbLocale(test.locale, test.provider, test.icuLocale); got != test.want {
+			t.Errorf("dbLocale(%v, %v, %v) returned %q, expected %q", test.locale, test.provider, test.icuLocale, got, test.want)
+		}
+	}
+}
+
+func TestSetDBCatalogState(t *testing.T) {
+	str := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+
+	t.Run("all NULL", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLDatabase().Schema, map[string]interface{}{})
+		setDBCatalogState(d, "mydb", dbCatalogRow{})
+
+		expected := map[string]interface{}{
+			dbNameAttr:       "mydb",
+			dbOwnerAttr:      "",
+			dbEncodingAttr:   "",
+			dbCollationAttr:  "",
+			dbCTypeAttr:      "",
+			dbTablespaceAttr: "pg_default",
+			dbConnLimitAttr:  -1,
+		}
+		for attr, want := range expected {
+			if got := d.Get(attr); got != want {
+				t.Errorf("%s: expected %v, got %v", attr, want, got)
+			}
+		}
+	})
+
+	t.Run("all set", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLDatabase().Schema, map[string]interface{}{})
+		setDBCatalogState(d, "mydb", dbCatalogRow{
+			name:       str("mydb"),
+			owner:      str("alice"),
+			encoding:   str("UTF8"),
+			collation:  str("en_US.UTF-8"),
+			ctype:      str("C"),
+			tablespace: str("fast"),
+			connLimit:  sql.NullInt64{Int64: 10, Valid: true},
+			oid:        sql.NullInt64{Int64: 16385, Valid: true},
+		})
+
+		expected := map[string]interface{}{
+			dbNameAttr:       "mydb",
+			dbOwnerAttr:      "alice",
+			dbEncodingAttr:   "UTF8",
+			dbCollationAttr:  "en_US.UTF-8",
+			dbCTypeAttr:      "C",
+			dbTablespaceAttr: "fast",
+			dbConnLimitAttr:  10,
+			dbOIDAttr:        16385,
+		}
+		for attr, want := range expected {
+			if got := d.Get(attr); got != want {
+				t.Errorf("%s: expected %v, got %v", attr, want, got)
+			}
+		}
+	})
+}
+
+func TestExpandDBCommand(t *testing.T) {
+	command := expandDBCommand(
+		[]string{"pg_dump", "--host={host}", "--port", "{port}", "--file=/backups/{dbname}.dump", "{dbname}"},
+		map[string]string{"host": "db.local", "port": "5432", "dbname": "my db; rm -rf /"},
+	)
+	expected := []string{"pg_dump", "--host=db.local", "--port", "5432", "--file=/backups/my db; rm -rf /.dump", "my db; rm -rf /"}
+	if !reflect.DeepEqual(command, expected) {
+		t.Fatalf("expandDBCommand returned %#v, expected %#v", command, expected)
+	}
+}
+
+func TestRunPreDeleteCommand(t *testing.T) {
+	defaultRunner := dbCommandRunner
+	defer func() { dbCommandRunner = defaultRunner }()
+
+	db := &DBConnection{client: &Client{config: Config{Host: "db.local", Port: 5433, Username: "admin", Password: "secret"}}}
+
+	cases := []struct {
+		name      string
+		resource  map[string]interface{}
+		runErr    error
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:     "no command",
+			resource: map[string]interface{}{"name": "mydb"},
+		},
+		{
+			name: "successful backup",
+			resource: map[string]interface{}{
+				"name":               "mydb",
+				"pre_delete_command": []interface{}{"pg_dump", "-h", "{host}", "-p", "{port}", "-U", "{username}", "{dbname}"},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "failed backup",
+			resource: map[string]interface{}{
+				"name":               "mydb",
+				"pre_delete_command": []interface{}{"pg_dump", "{dbname}"},
+			},
+			runErr:    errors.New("exit status 1"),
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name: "ignored failed backup",
+			resource: map[string]interface{}{
+				"name":                  "mydb",
+				"pre_delete_command":    []interface{}{"pg_dump", "{dbname}"},
+				"ignore_backup_failure": true,
+			},
+			runErr:    errors.New("exit status 1"),
+			wantCalls: 1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			calls := 0
+			dbCommandRunner = func(ctx context.Context, name string, args []string, env []string) ([]byte, error) {
+				calls++
+				if name != "pg_dump" || args[len(args)-1] != "mydb" {
+					t.Errorf("unexpected command %s %v", name, args)
+				}
+				if len(args) > 1 && !reflect.DeepEqual(args, []string{"-h", "db.local", "-p", "5433", "-U", "admin", "mydb"}) {
+					t.Errorf("unexpected arguments %v", args)
+				}
+				if env[len(env)-1] != "PGPASSWORD=secret" {
+					t.Errorf("expected the password in PGPASSWORD, got %v", env[len(env)-1])
+				}
+				return []byte("pg_dump: error"), c.runErr
+			}
+
+			err := runPreDeleteCommand(db, schema.TestResourceDataRaw(t, resourcePostgreSQLDatabase().Schema, c.resource))
+			if c.wantErr != (err != nil) {
+				t.Errorf("runPreDeleteCommand returned %v, expected error: %t", err, c.wantErr)
+			}
+			if calls != c.wantCalls {
+				t.Errorf("expected %d calls of the command, got %d", c.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestDrainBackends(t *testing.T) {
+	cases := []struct {
+		cancelFirst   bool
+		canceledPids  []int
+		expectedCalls []string
+	}{
+		{cancelFirst: false, expectedCalls: []string{"pg_terminate_backend"}},
+		{cancelFirst: true, canceledPids: []int{42}, expectedCalls: []string{"pg_cancel_backend", "pg_terminate_backend"}},
+		{cancelFirst: true, expectedCalls: []string{"pg_cancel_backend", "pg_terminate_backend"}},
+	}
+
+	for _, c := range cases {
+		var calls []string
+		signal := func(function string) ([]int, error) {
+			calls = append(calls, function)
+			if function == "pg_cancel_backend" {
+				return c.canceledPids, nil
+			}
+			return []int{}, nil
+		}
+
+		if err := drainBackends(context.Background(), signal, c.cancelFirst, time.Millisecond); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(calls, c.expectedCalls) {
+			t.Errorf("drainBackends(cancelFirst=%t) called %v, expected %v", c.cancelFirst, calls, c.expectedCalls)
+		}
+	}
+
+	// The cancel error stops the drain before terminating the backends.
+	var calls []string
+	err := drainBackends(context.Background(), func(function string) ([]int, error) {
+		calls = append(calls, function)
+		return nil, errors.New("permission denied")
+	}, true, time.Millisecond)
+	if err == nil || len(calls) != 1 {
+		t.Errorf("expected the drain to stop after the cancel error, got %v after %v", err, calls)
+	}
+}
+
+func TestReconcileDBTypedSettings(t *testing.T) {
+	get := func(values map[string]interface{}) func(string) interface{} {
+		return func(attr string) interface{} {
+			if v, ok := values[attr]; ok {
+				return v
+			}
+			if attr == dbLogMinDurationAttr {
+				return -1
+			}
+			return ""
+		}
+	}
+
+	unset := dbTypedSettingValues(get(map[string]interface{}{}))
+	set := dbTypedSettingValues(get(map[string]interface{}{
+		"work_mem":           "64MB",
+		dbLogMinDurationAttr: 0,
+	}))
+	assert.Equal(t, map[string]interface{}{"work_mem": "64MB", "log_min_duration_statement": "0"}, set)
+
+	// Setting then clearing the parameters.
+	assert.Equal(t, []string{
+		`ALTER DATABASE "mydb" SET log_min_duration_statement TO '0'`,
+		`ALTER DATABASE "mydb" SET work_mem TO '64MB'`,
+	}, dbSettingsQueries("mydb", unset, set))
+	assert.Equal(t, []string{
+		`ALTER DATABASE "mydb" RESET log_min_duration_statement`,
+		`ALTER DATABASE "mydb" RESET work_mem`,
+	}, dbSettingsQueries("mydb", set, unset))
+	assert.Empty(t, dbSettingsQueries("mydb", set, set))
+}
+
+func TestDBListSetting(t *testing.T) {
+	assert.Equal(t, dbListSetting(`'auto_explain', 'pg_hint_plan'`), listSettingValue([]interface{}{"auto_explain", "pg_hint_plan"}))
+	assert.Equal(t, dbListSetting(`'$libdir/plugins/it''s'`), listSettingValue([]interface{}{"$libdir/plugins/it's"}))
+	assert.Equal(t, "", listSettingValue([]interface{}{}))
+	assert.Equal(t, "", listSettingValue(nil))
+
+	dbConfig := pq.ByteaArray{
+		[]byte("work_mem=64MB"),
+		[]byte(`session_preload_libraries=auto_explain, "$libdir/plugins/pg_hint_plan"`),
+	}
+	assert.Equal(t, []string{"auto_explain", "$libdir/plugins/pg_hint_plan"}, readDBListSetting(dbConfig, "session_preload_libraries"))
+	assert.Nil(t, readDBListSetting(dbConfig, "local_preload_libraries"))
+
+	// The list is reconciled as a single parameter.
+	get := func(values map[string]interface{}) func(string) interface{} {
+		return func(attr string) interface{} {
+			if v, ok := values[attr]; ok {
+				return v
+			}
+			if attr == dbLogMinDurationAttr {
+				return -1
+			}
+			return ""
+		}
+	}
+	set := dbTypedSettingValues(get(map[string]interface{}{dbSessionPreloadAttr: []interface{}{"auto_explain"}}))
+	unset := dbTypedSettingValues(get(map[string]interface{}{dbSessionPreloadAttr: []interface{}{}}))
+	assert.Equal(t, []string{`ALTER DATABASE "mydb" SET session_preload_libraries TO 'auto_explain'`}, dbSettingsQueries("mydb", unset, set))
+	assert.Equal(t, []string{`ALTER DATABASE "mydb" RESET session_preload_libraries`}, dbSettingsQueries("mydb", set, unset))
+
+	// Each library is sent as its own literal, not as a single "a,b" library.
+	both := dbTypedSettingValues(get(map[string]interface{}{dbSessionPreloadAttr: []interface{}{"auto_explain", "pg_stat_statements"}}))
+	assert.Equal(t, []string{
+		`ALTER DATABASE "mydb" SET session_preload_libraries TO 'auto_explain', 'pg_stat_statements'`,
+	}, dbSettingsQueries("mydb", set, both))
+	assert.Empty(t, dbSettingsQueries("mydb", both, both))
+}
+
+func TestDBDurationSettings(t *testing.T) {
+	for _, c := range []struct {
+		input    string
+		expected int
+		wantErr  bool
+	}{
+		{input: "30000", expected: 30000},
+		{input: "30s", expected: 30000},
+		{input: "30 s", expected: 30000},
+		{input: "1min", expected: 60000},
+		{input: "1500us", expected: 1},
+		{input: "0", expected: 0},
+		{input: "30sec", wantErr: true},
+		{input: "", wantErr: true},
+	} {
+		n, err := parseDBDuration(c.input)
+		if c.wantErr != (err != nil) || (err == nil && n != c.expected) {
+			t.Errorf("parseDBDuration(%q) returned %d, %v, expected %d, error: %t", c.input, n, err, c.expected, c.wantErr)
+		}
+	}
+
+	_, errs := validateDBDuration("30s", dbStatementTimeoutAttr)
+	assert.Empty(t, errs)
+	_, errs = validateDBDuration("-1", dbStatementTimeoutAttr)
+	assert.NotEmpty(t, errs)
+	_, errs = validateDBDuration("soon", dbStatementTimeoutAttr)
+	assert.NotEmpty(t, errs)
+
+	// 30s and 30000 compare equal.
+	assert.True(t, suppressEquivalentDBDuration(dbStatementTimeoutAttr, "30000", "30s", nil))
+	assert.True(t, suppressEquivalentDBDuration(dbStatementTimeoutAttr, "1min", "60s", nil))
+	assert.False(t, suppressEquivalentDBDuration(dbStatementTimeoutAttr, "30000", "31s", nil))
+	assert.False(t, suppressEquivalentDBDuration(dbStatementTimeoutAttr, "", "0", nil))
+	assert.Equal(t, "30000", durationSettingValue("30s"))
+	assert.Equal(t, "", durationSettingValue(""))
+
+	// The parameters are set normalized, and reset when removed.
+	get := func(values map[string]interface{}) func(string) interface{} {
+		return func(attr string) interface{} {
+			if v, ok := values[attr]; ok {
+				return v
+			}
+			if attr == dbLogMinDurationAttr {
+				return -1
+			}
+			return ""
+		}
+	}
+	unset := dbTypedSettingValues(get(map[string]interface{}{}))
+	seconds := dbTypedSettingValues(get(map[string]interface{}{dbStatementTimeoutAttr: "30s", dbLockTimeoutAttr: "5s"}))
+	millis := dbTypedSettingValues(get(map[string]interface{}{dbStatementTimeoutAttr: "30000", dbLockTimeoutAttr: "5000"}))
+	assert.Equal(t, []string{
+		`ALTER DATABASE "mydb" SET lock_timeout TO '5000'`,
+		`ALTER DATABASE "mydb" SET statement_timeout TO '30000'`,
+	}, dbSettingsQueries("mydb", unset, seconds))
+	assert.Empty(t, dbSettingsQueries("mydb", seconds, millis))
+	assert.Equal(t, []string{
+		`ALTER DATABASE "mydb" RESET lock_timeout`,
+		`ALTER DATABASE "mydb" RESET statement_timeout`,
+	}, dbSettingsQueries("mydb", millis, unset))
+}
+
+func TestCheckDBToastCompression(t *testing.T) {
+	db := &DBConnection{client: &Client{}, version: semver.MustParse("13.0.0")}
+
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLDatabase().Schema, map[string]interface{}{"name": "mydb"})
+	assert.NoError(t, checkDBToastCompression(db, d))
+
+	d = schema.TestResourceDataRaw(t, resourcePostgreSQLDatabase().Schema, map[string]interface{}{
+		"name":                      "mydb",
+		"default_toast_compression": "lz4",
+	})
+	err := checkDBToastCompression(db, d)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "default_toast_compression requires PostgreSQL 14 or later")
+	}
+}
+
+func TestCheckDBRecreate(t *testing.T) {
+	assert.NoError(t, checkDBRecreate("mydb", nil))
+
+	err := checkDBRecreate("mydb", []string{dbEncodingAttr, dbTemplateAttr})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "changing encoding, template recreates database mydb")
+		assert.Contains(t, err.Error(), "all its data is lost")
+	}
+}
+
+func TestDBForceNewAttrs(t *testing.T) {
+	attrs := dbForceNewAttrs()
+	assert.True(t, sort.StringsAreSorted(attrs))
+	for _, attr := range []string{dbTemplateAttr, dbEncodingAttr, dbCollationAttr, dbCTypeAttr} {
+		assert.Contains(t, attrs, attr)
+	}
+	assert.NotContains(t, attrs, dbOwnerAttr)
+}
+
+func TestCheckDBTemplateEncoding(t *testing.T) {
+	if err := checkDBTemplateEncoding("template1", "UTF-8", "UTF8", true); err != nil {
+		t.Errorf("Unexpected error for a compatible encoding: %v", err)
+	}
+	err := checkDBTemplateEncoding("template1", "LATIN1", "UTF8", false)
+	if err == nil || !strings.Contains(err.Error(), `incompatible with the encoding of template "template1" (UTF8)`) {
+		t.Errorf("Expected an incompatible encoding error, got: %v", err)
+	}
+}
+
+func TestMaintenanceDBConnection(t *testing.T) {
+	// The connection is kept if the provider is not connected to the dropped database.
+	db := &DBConnection{client: &Client{databaseName: "postgres"}}
+	conn, err := maintenanceDBConnection(db, "mydb")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if conn != db {
+		t.Fatalf("Expected the provider connection to be used")
+	}
+}
+
+func TestDBConnLimitDiags(t *testing.T) {
+	cases := []struct {
+		connLimit   int
+		wantWarning bool
+	}{
+		{connLimit: 10},
+		{connLimit: 100},
+		{connLimit: 101, wantWarning: true},
+		{connLimit: 100000, wantWarning: true},
+	}
+
+	for _, c := range cases {
+		diags := dbConnLimitDiags("mydb", c.connLimit, 100)
+		if c.wantWarning != (len(diags) > 0) {
+			t.Errorf("dbConnLimitDiags(%d) returned %v, expected warning: %t", c.connLimit, diags, c.wantWarning)
+		}
+		for _, d := range diags {
+			if d.Severity != diag.Warning {
+				t.Errorf("dbConnLimitDiags(%d) returned a non-warning diagnostic: %v", c.connLimit, d)
+			}
+		}
+	}
+}
+
+func TestDBOrphanedOwner(t *testing.T) {
+	cases := []struct {
+		owner    string
+		expected bool
+	}{
+		{owner: "unknown (OID=16390)", expected: true},
+		{owner: "app_owner", expected: false},
+		{owner: "unknown", expected: false},
+		{owner: "unknown (OID=)", expected: false},
+		{owner: "", expected: false},
+	}
+
+	for _, c := range cases {
+		if out := isOrphanedDBOwner(c.owner); out != c.expected {
+			t.Errorf("isOrphanedDBOwner(%q) returned %t, expected %t", c.owner, out, c.expected)
+		}
+	}
+
+	diags := dbOrphanedOwnerDiags("mydb", "unknown (OID=16390)")
+	if len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Fatalf("dbOrphanedOwnerDiags returned %v, expected a warning", diags)
+	}
+	if !strings.Contains(diags[0].Detail, dbReassignOrphanAttr) {
+		t.Errorf("the warning should suggest %s: %q", dbReassignOrphanAttr, diags[0].Detail)
+	}
+}
+
+func TestCheckDBUnlimitedConnections(t *testing.T) {
+	var tests = []struct {
+		unlimited bool
+		connLimit int
+		wantErr   bool
+	}{
+		{true, -1, false},
+		{true, 10, true},
+		{true, 0, true},
+		{false, 10, false},
+		{false, 0, false},
+		{false, -1, true},
+	}
+
+	for _, test := range tests {
+		err := checkDBUnlimitedConnections(test.unlimited, test.connLimit)
+		if (err != nil) != test.wantErr {
+			t.Errorf("checkDBUnlimitedConnections(%t, %d) returned %v, expected error: %t", test.unlimited, test.connLimit, err, test.wantErr)
+		}
+	}
+}
+
+func TestCheckDBEncodingLocale(t *testing.T) {
+	var tests = []struct {
+		encoding string
+		collate  string
+		ctype    string
+		wantErr  bool
+	}{
+		{"UTF8", "en_US.UTF-8", "en_US.UTF-8", false},
+		{"utf-8", "en_US.utf8", "en_US.utf8", false},
+		{"UTF8", "C", "POSIX", false},
+		{"UTF8", "DEFAULT", "", false},
+		{"DEFAULT", "de_DE.ISO-8859-1", "de_DE.ISO-8859-1", false},
+		{"LATIN1", "de_DE.ISO-8859-1", "de_DE.ISO-8859-1", false},
+		{"iso-8859-15", "fr_FR.ISO-8859-15@euro", "fr_FR.ISO-8859-15@euro", false},
+		{"EUC_JP", "ja_JP.eucJP", "ja_JP.eucJP", false},
+		{"SQL_ASCII", "en_US.UTF-8", "en_US.UTF-8", false},
+		// Unknown codesets are accepted.
+		{"UTF8", "en_US.unknown", "en_US.unknown", false},
+		{"UTF8", "de_DE.ISO-8859-1", "de_DE.ISO-8859-1", true},
+		{"UTF8", "C", "ja_JP.eucJP", true},
+		{"LATIN1", "en_US.UTF-8", "C", true},
+	}
+
+	for _, test := range tests {
+		err := checkDBEncodingLocale(test.encoding, map[string]string{
+			dbCollationAttr: test.collate,
+			dbCTypeAttr:     test.ctype,
+		})
+		if (err != nil) != test.wantErr {
+			t.Errorf("checkDBEncodingLocale(%q, %q, %q) returned %v, expected an error: %t", test.encoding, test.collate, test.ctype, err, test.wantErr)
+		}
+	}
+}
+
+func TestOwnerMembershipNeeded(t *testing.T) {
+	cases := []struct {
+		owner    string
+		expected bool
+	}{
+		{owner: "", expected: false},
+		{owner: "admin", expected: false},
+		{owner: "app_owner", expected: true},
+		{owner: "unknown (OID=16390)", expected: false},
+	}
+
+	for _, c := range cases {
+		if out := ownerMembershipNeeded(c.owner, "admin"); out != c.expected {
+			t.Errorf("ownerMembershipNeeded(%q, %q) returned %t, expected %t", c.owner, "admin", out, c.expected)
+		}
+	}
+}
+
+func TestIsSystemDatabase(t *testing.T) {
+	for _, name := range []string{"postgres", "template0", "template1"} {
+		if !isSystemDatabase(name) {
+			t.Errorf("expected %q to be a system database", name)
+		}
+	}
+	for _, name := range []string{"app", "Postgres", "template2"} {
+		if isSystemDatabase(name) {
+			t.Errorf("expected %q not to be a system database", name)
+		}
+	}
+}
+
+func TestDeleteProtectedSystemDatabase(t *testing.T) {
+	db := &DBConnection{client: &Client{config: Config{ProtectSystemDatabases: true}}}
+	d := resourcePostgreSQLDatabase().TestResourceData()
+	d.Set(dbNameAttr, "postgres")
+
+	diags := resourcePostgreSQLDatabaseDelete(db, d)
+	if !diags.HasError() || !regexp.MustCompile("is a system database").MatchString(diags[0].Summary) {
+		t.Fatalf("expected the deletion of a system database to be blocked, got: %v", diags)
+	}
+}
+
 func TestAccPostgresqlDatabase_Basic(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:     func() { testAccPreCheck(t) },
@@ -18,283 +1017,3739 @@ func TestAccPostgresqlDatabase_Basic(t *testing.T) {
 		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccPostgreSQLDatabaseConfig,
+				Config: testAccPostgreSQLDatabaseConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.mydb"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.mydb", "name", "mydb"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.mydb", "owner", "myrole"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.mydb", "colocation_effective", "false"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.default_opts", "owner", "myrole"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.default_opts", "name", "default_opts_name"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.default_opts", "template", "template0"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.default_opts", "encoding", "UTF8"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.default_opts", "lc_collate", "C"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.default_opts", "lc_ctype", "C"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.default_opts", "tablespace_name", "pg_default"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.default_opts", "connection_limit", "-1"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.default_opts", "is_template", "false"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.default_opts", "alter_object_ownership", "false"),
+
+					resource.TestCheckResourceAttr(
+						"postgresql_database.modified_opts", "owner", "myrole"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.modified_opts", "name", "custom_template_db"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.modified_opts", "template", "template0"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.modified_opts", "encoding", "UTF8"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.modified_opts", "lc_collate", "en_US.UTF-8"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.modified_opts", "lc_ctype", "en_US.UTF-8"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.modified_opts", "tablespace_name", "pg_default"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.modified_opts", "connection_limit", "10"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.modified_opts", "is_template", "true"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.modified_opts", "alter_object_ownership", "true"),
+
+					resource.TestCheckResourceAttr(
+						"postgresql_database.pathological_opts", "owner", "myrole"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.pathological_opts", "name", "bad_template_db"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.pathological_opts", "template", "template0"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.pathological_opts", "encoding", "LATIN1"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.pathological_opts", "lc_collate", "C"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.pathological_opts", "lc_ctype", "C"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.pathological_opts", "tablespace_name", "pg_default"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.pathological_opts", "connection_limit", "0"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.pathological_opts", "is_template", "true"),
+
+					resource.TestCheckResourceAttr(
+						"postgresql_database.pg_default_opts", "owner", "myrole"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.pg_default_opts", "name", "pg_defaults_db"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.pg_default_opts", "template", "DEFAULT"),
+					// resource.TestCheckResourceAttr(
+					// 	"postgresql_database.pg_default_opts", "encoding", "DEFAULT"),
+					// resource.TestCheckResourceAttr(
+					// 	"postgresql_database.pg_default_opts", "lc_collate", "DEFAULT"),
+					// resource.TestCheckResourceAttr(
+					//  "postgresql_database.pg_default_opts", "lc_ctype", "DEFAULT"),
+					// resource.TestCheckResourceAttr(
+					// 	"postgresql_database.pg_default_opts", "tablespace_name", "DEFAULT"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.pg_default_opts", "connection_limit", "0"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.pg_default_opts", "is_template", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_DefaultOwner(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgreSQLDatabaseConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.mydb_default_owner"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.mydb_default_owner", "name", "mydb_default_owner"),
+					resource.TestCheckResourceAttrSet(
+						"postgresql_database.mydb_default_owner", "owner"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_ProviderDefaultOwner(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	dbExecute(t, dsn, "CREATE ROLE test_default_owner")
+	defer func() {
+		dbExecute(t, dsn, "DROP ROLE test_default_owner")
+	}()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "postgresql" {
+	default_owner = "test_default_owner"
+}
+
+resource postgresql_database "test_db" {
+	name = "test_db"
+}
+
+resource postgresql_database "test_db_owner" {
+	name  = "test_db_owner"
+	owner = "%s"
+}
+`, config.Username),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", "test_default_owner"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db_owner", "owner", config.Username),
+
+					// check if connected user does not have test_default_owner granted anymore.
+					checkUserMembership(t, dsn, config.Username, "test_default_owner", false),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_ProviderDefaultEncoding(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "postgresql" {
+	default_encoding = "SQL_ASCII"
+}
+
+resource postgresql_database "test_db" {
+	name = "test_db_default_encoding"
+}
+
+resource postgresql_database "test_db_encoding" {
+	name     = "test_db_encoding"
+	encoding = "UTF8"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "encoding", "SQL_ASCII"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db_encoding", "encoding", "UTF8"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_Update(t *testing.T) {
+
+	// Version dependent features values will be set in PreCheck
+	// because we need to access database to check Postgres version.
+
+	// Allow connection depends on Postgres version (needs pg >= 9.5)
+	var allowConnections bool
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+
+			client := testAccProvider.Meta().(*Client)
+			db, err := client.Connect()
+			if err != nil {
+				t.Fatalf("could not connect to database: %v", err)
+			}
+			allowConnections = db.featureSupported(featureDBAllowConnections)
+
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource postgresql_database test_db {
+    name = "test_db"
+	allow_connections = "%t"
+}
+`, allowConnections),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", "test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "connection_limit", "-1"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.test_db", "allow_connections",
+						strconv.FormatBool(allowConnections),
+					),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+	connection_limit = 2
+	allow_connections = false
+}
+	`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", "test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "connection_limit", "2"),
+					resource.TestCheckResourceAttr(
+						"postgresql_database.test_db", "allow_connections", "false",
+					),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_CheckCreateDBPrivilege(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	dbExecute(t, dsn, fmt.Sprintf("CREATE ROLE tf_tests_no_createdb LOGIN PASSWORD '%s'", testRolePassword))
+	defer dbExecute(t, dsn, "DROP ROLE tf_tests_no_createdb")
+
+	config.Username = "tf_tests_no_createdb"
+	config.Password = testRolePassword
+	db, err := config.NewClient("postgres").Connect()
+	if err != nil {
+		t.Fatalf("could not connect as tf_tests_no_createdb: %v", err)
+	}
+
+	err = checkCreateDBPrivilege(db)
+	if err == nil || !regexp.MustCompile(`role "tf_tests_no_createdb" cannot create databases: it needs the CREATEDB privilege`).MatchString(err.Error()) {
+		t.Fatalf("expected a CREATEDB privilege error, got: %v", err)
+	}
+
+	dbExecute(t, dsn, "ALTER ROLE tf_tests_no_createdb CREATEDB")
+	if err := checkCreateDBPrivilege(db); err != nil {
+		t.Fatalf("expected no error once CREATEDB is granted, got: %v", err)
+	}
+}
+
+func TestAccPostgresqlDatabase_TemplateFallback(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database "test_db" {
+	name      = "tf_tests_db_templates"
+	templates = ["tf_tests_missing_golden_template", "template0"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "template", "template0"),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database "test_db_missing" {
+	name      = "tf_tests_db_templates_missing"
+	templates = ["tf_tests_missing_golden_template", "tf_tests_missing_template"]
+}
+`,
+				ExpectError: regexp.MustCompile(`template database "tf_tests_missing_template" does not exist`),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_RevokeOwnerAfterFailedCreate(t *testing.T) {
+	skipIfNotAcc(t)
+
+	teardown := createTestRole(t, "tf_tests_failed_create_owner")
+	defer teardown()
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	testConfig := `
+resource postgresql_database "test_db" {
+	name     = "tf_tests_db_failed_create"
+	owner    = "tf_tests_failed_create_owner"
+	template = "tf_tests_missing_template"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				// The owner is granted to create the database, which fails.
+				Config:      testConfig,
+				ExpectError: regexp.MustCompile(`template database "tf_tests_missing_template" does not exist`),
+			},
+			{
+				PreConfig: func() {
+					if err := checkUserMembership(t, dsn, config.Username, "tf_tests_failed_create_owner", false)(nil); err != nil {
+						t.Fatalf("the owner has not been revoked after the failed creation: %v", err)
+					}
+				},
+				Config:             testConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_EffectiveSettings(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	testConfig := `
+resource postgresql_database "test_db" {
+	name     = "tf_tests_db_effective_settings"
+	settings = { "application_name" = "terraform" }
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "effective_settings.%", "1"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "effective_settings.application_name", "terraform"),
+				),
+			},
+			{
+				// The parameters set outside of Terraform are read too, without planning any change.
+				PreConfig: func() {
+					dbExecute(t, dsn, "ALTER DATABASE tf_tests_db_effective_settings SET idle_in_transaction_session_timeout = '1min'")
+					dbExecute(t, dsn, "ALTER DATABASE tf_tests_db_effective_settings SET app.tenant = 'acme'")
+				},
+				Config: testConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "effective_settings.%", "3"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "effective_settings.application_name", "terraform"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "effective_settings.idle_in_transaction_session_timeout", "1min"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "effective_settings.app.tenant", "acme"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_TablespaceNotExists(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database "test_db" {
+	name            = "tf_tests_db_missing_tablespace"
+	tablespace_name = "tf_tests_missing_tablespace"
+}
+`,
+				ExpectError: regexp.MustCompile(`tablespace "tf_tests_missing_tablespace" does not exist`),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_PlacementTablespace(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database "test_db" {
+	name                 = "tf_tests_db_placement"
+	placement_tablespace = "tf_tests_missing_tablespace"
+}
+`,
+				ExpectError: regexp.MustCompile(`placement tablespace "tf_tests_missing_tablespace" does not exist`),
+			},
+			{
+				Config: `
+resource postgresql_database "test_db" {
+	name                 = "tf_tests_db_placement"
+	placement_tablespace = "pg_default"
+}
+`,
+				ExpectError: regexp.MustCompile(`tablespace "pg_default" has no placement policy`),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_UnresolvedTablespace(t *testing.T) {
+	skipIfNotAcc(t)
+	skipIfNotSuperuser(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	const dbConfig = `
+resource postgresql_database test_db {
+	name              = "test_db"
+	allow_connections = false
+}
+`
+	// The catalog is modified to simulate a tablespace which cannot be resolved,
+	// it is restored before the database is dropped.
+	restoreTablespace := func() {
+		dbExecute(t, dsn, "UPDATE pg_catalog.pg_database SET dattablespace = "+
+			"(SELECT oid FROM pg_catalog.pg_tablespace WHERE spcname = 'pg_default') WHERE datname = 'test_db'")
+	}
+	defer restoreTablespace()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: dbConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "tablespace_name", "pg_default"),
+				),
+			},
+			{
+				PreConfig: func() {
+					dbExecute(t, dsn, "UPDATE pg_catalog.pg_database SET dattablespace = 0 WHERE datname = 'test_db'")
+				},
+				Config: dbConfig,
+				Check: resource.ComposeTestCheckFunc(
+					func(*terraform.State) error {
+						restoreTablespace()
+						return nil
+					},
+					// The database is still in the state, with the default tablespace.
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", "test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "tablespace_name", "pg_default"),
+				),
+			},
+		},
+	})
+}
+
+// createInPlaceTablespace creates a tablespace and returns the function dropping it.
+// An in-place tablespace avoids depending on a directory of the server.
+func createInPlaceTablespace(t *testing.T, dsn, name string) func() {
+	pool, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("could not create connection pool: %v", err)
+	}
+	defer pool.Close()
+	conn, err := pool.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("could not open connection: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(context.Background(), "SET allow_in_place_tablespaces = on"); err != nil {
+		t.Skipf("Skip test: in-place tablespaces are not supported: %v", err)
+	}
+	if _, err := conn.ExecContext(context.Background(), fmt.Sprintf("CREATE TABLESPACE %s LOCATION ''", name)); err != nil {
+		t.Fatalf("could not create tablespace: %v", err)
+	}
+
+	return func() {
+		dbExecute(t, dsn, fmt.Sprintf("DROP TABLESPACE %s", name))
+	}
+}
+
+func TestAccPostgresqlDatabase_DefaultTablespace(t *testing.T) {
+	skipIfNotAcc(t)
+	skipIfNotSuperuser(t)
+
+	config := getTestConfig(t)
+	defer createInPlaceTablespace(t, config.connStr("postgres"), "test_default_tbsp")()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				// The objects are created in test_default_tbsp,
+				// but the database itself stays in pg_default.
+				Config: `
+resource postgresql_database test_db {
+	name               = "test_db"
+	default_tablespace = "test_default_tbsp"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "default_tablespace", "test_default_tbsp"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "tablespace_name", "pg_default"),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "default_tablespace", ""),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "tablespace_name", "pg_default"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_DefaultRole(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name         = "test_db"
+	default_role = "test_missing_role"
+}
+`,
+				ExpectError: regexp.MustCompile(`default_role: role "test_missing_role" does not exist`),
+			},
+			{
+				Config: `
+resource postgresql_role app {
+	name = "test_app_role"
+}
+
+resource postgresql_database test_db {
+	name         = "test_db"
+	default_role = postgresql_role.app.name
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "default_role", "test_app_role"),
+				),
+			},
+			{
+				Config: `
+resource postgresql_role app {
+	name = "test_app_role"
+}
+
+resource postgresql_database test_db {
+	name = "test_db"
+}
+`,
+				Check: resource.TestCheckResourceAttr("postgresql_database.test_db", "default_role", ""),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_TablespaceDrain(t *testing.T) {
+	skipIfNotAcc(t)
+	skipIfNotSuperuser(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	defer createInPlaceTablespace(t, dsn, "test_tbsp")()
+
+	// Session kept open on the database while its tablespace is reset.
+	var session *sql.DB
+	defer func() {
+		if session != nil {
+			session.Close()
+		}
+	}()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name                         = "test_db"
+	tablespace_name              = "test_tbsp"
+	tablespace_drain_connections = true
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "tablespace_name", "test_tbsp"),
+				),
+			},
+			{
+				PreConfig: func() {
+					var err error
+					session, err = sql.Open("postgres", config.connStr("test_db"))
+					if err != nil {
+						t.Fatalf("could not create connection pool: %v", err)
+					}
+					if err := session.Ping(); err != nil {
+						t.Fatalf("could not open session on test_db: %v", err)
+					}
+				},
+				Config: `
+resource postgresql_database test_db {
+	name                         = "test_db"
+	tablespace_name              = "DEFAULT"
+	tablespace_drain_connections = true
+}
+`,
+				// DEFAULT is read back as pg_default.
+				ExpectNonEmptyPlan: true,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "tablespace_name", "pg_default"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_DataChecksums(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+}
+`,
+				Check: resource.TestCheckResourceAttrWith("postgresql_database.test_db", "data_checksums", func(value string) error {
+					client := testAccProvider.Meta().(*Client)
+					db, err := client.Connect()
+					if err != nil {
+						return err
+					}
+					var setting string
+					if err := db.QueryRow("SHOW data_checksums").Scan(&setting); err != nil {
+						return err
+					}
+					if expected := strconv.FormatBool(setting == "on"); value != expected {
+						return fmt.Errorf("expected data_checksums %s (server setting %s), got %s", expected, setting, value)
+					}
+					return nil
+				}),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_ActiveConnectionCount(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+
+	var session *sql.DB
+	defer func() {
+		if session != nil {
+			session.Close()
+		}
+	}()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+}
+`,
+				Check: resource.TestCheckResourceAttrSet("postgresql_database.test_db", "active_connection_count"),
+			},
+			{
+				PreConfig: func() {
+					var err error
+					session, err = sql.Open("postgres", config.connStr("test_db"))
+					if err != nil {
+						t.Fatalf("could not create connection pool: %v", err)
+					}
+					if err := session.Ping(); err != nil {
+						t.Fatalf("could not open session on test_db: %v", err)
+					}
+				},
+				RefreshState: true,
+				// The session of the test is counted, the ones of the provider may still be closing.
+				Check: resource.TestCheckResourceAttrWith("postgresql_database.test_db", "active_connection_count", func(value string) error {
+					if count, err := strconv.Atoi(value); err != nil || count < 1 {
+						return fmt.Errorf("expected at least 1 connection, got %q", value)
+					}
+					return nil
+				}),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_ActiveSessions(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+
+	var session *sql.DB
+	defer func() {
+		if session != nil {
+			session.Close()
+		}
+	}()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+}
+`,
+				Check: resource.TestCheckResourceAttrSet("postgresql_database.test_db", "active_sessions.#"),
+			},
+			{
+				PreConfig: func() {
+					var err error
+					session, err = sql.Open("postgres", config.connStr("test_db"))
+					if err != nil {
+						t.Fatalf("could not create connection pool: %v", err)
+					}
+					// A single connection so the application_name is set on the session kept open.
+					session.SetMaxOpenConns(1)
+					if _, err := session.Exec("SET application_name = 'tf_active_sessions'"); err != nil {
+						t.Fatalf("could not open session on test_db: %v", err)
+					}
+				},
+				RefreshState: true,
+				Check: resource.TestCheckTypeSetElemNestedAttrs("postgresql_database.test_db", "active_sessions.*", map[string]string{
+					"usename":          config.Username,
+					"application_name": "tf_active_sessions",
+					"state":            "idle",
+				}),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_SettingsFromCurrent(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	db, err := sql.Open("postgres", config.connStr("postgres"))
+	if err != nil {
+		t.Fatalf("could not create connection pool: %v", err)
+	}
+	defer db.Close()
+
+	var current string
+	if err := db.QueryRow("SELECT current_setting('default_statistics_target')").Scan(&current); err != nil {
+		t.Fatalf("could not read default_statistics_target: %v", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				// The plan after the apply must be empty although the pinned value is read back.
+				Config: `
+resource postgresql_database test_db {
+	name         = "test_db"
+	settings     = { default_statistics_target = "__FROM_CURRENT__" }
+	raw_settings = ["geqo_threshold=__FROM_CURRENT__"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "settings.default_statistics_target", "__FROM_CURRENT__"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "raw_settings.0", "geqo_threshold=__FROM_CURRENT__"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "effective_settings.default_statistics_target", current),
+					resource.TestCheckResourceAttrSet("postgresql_database.test_db", "effective_settings.geqo_threshold"),
+				),
+			},
+			{
+				RefreshState: true,
+				Check:        resource.TestCheckResourceAttr("postgresql_database.test_db", "settings.default_statistics_target", "__FROM_CURRENT__"),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_SearchPath(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name        = "test_db"
+	search_path = ["$user", "foo-with-hyphen", "Mixed Case", "with\"quote", "foo, bar"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "search_path.#", "5"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "search_path.0", "$user"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "search_path.1", "foo-with-hyphen"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "search_path.2", "Mixed Case"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "search_path.3", `with"quote`),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "search_path.4", "foo, bar"),
+					testAccCheckDBConfigValue("test_db", "search_path", `"$user", "foo-with-hyphen", "Mixed Case", "with""quote", "foo, bar"`),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name        = "test_db"
+	search_path = ["public"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "search_path.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "search_path.0", "public"),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "search_path.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_ReconcileSettings(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name                       = "test_db"
+	work_mem                   = "64MB"
+	log_min_duration_statement = 500
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDBConfigSet("test_db", "work_mem"),
+					testAccCheckDBConfigSet("test_db", "log_min_duration_statement"),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "work_mem", ""),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "log_min_duration_statement", "-1"),
+					testAccCheckDBConfigReset("test_db", "work_mem"),
+					testAccCheckDBConfigReset("test_db", "log_min_duration_statement"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_Timeouts(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name              = "test_db"
+	statement_timeout = "30s"
+	lock_timeout      = "5000"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "statement_timeout", "30000"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "lock_timeout", "5000"),
+					testAccCheckDBConfigSet("test_db", "statement_timeout"),
+					testAccCheckDBConfigSet("test_db", "lock_timeout"),
+				),
+			},
+			{
+				// The same values in other units do not cause a diff.
+				Config: `
+resource postgresql_database test_db {
+	name              = "test_db"
+	statement_timeout = "30000"
+	lock_timeout      = "5s"
+}
+`,
+				PlanOnly: true,
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "statement_timeout", ""),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "lock_timeout", ""),
+					testAccCheckDBConfigReset("test_db", "statement_timeout"),
+					testAccCheckDBConfigReset("test_db", "lock_timeout"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_ToastCompression(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureToastCompression)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name                      = "test_db"
+	default_toast_compression = "pglz"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "default_toast_compression", "pglz"),
+					testAccCheckDBConfigSet("test_db", "default_toast_compression"),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "default_toast_compression", ""),
+					testAccCheckDBConfigReset("test_db", "default_toast_compression"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_SessionPreloadLibraries(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name                      = "test_db"
+	session_preload_libraries = ["auto_explain"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "session_preload_libraries.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "session_preload_libraries.0", "auto_explain"),
+					testAccCheckDBConfigSet("test_db", "session_preload_libraries"),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name                      = "test_db"
+	session_preload_libraries = ["auto_explain", "pg_stat_statements"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "session_preload_libraries.#", "2"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "session_preload_libraries.0", "auto_explain"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "session_preload_libraries.1", "pg_stat_statements"),
+					// Two libraries are stored, not a single "auto_explain,pg_stat_statements" one.
+					testAccCheckDBConfigValue("test_db", "session_preload_libraries", "auto_explain, pg_stat_statements"),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "session_preload_libraries.#", "0"),
+					testAccCheckDBConfigReset("test_db", "session_preload_libraries"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_SearchPathMode(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name             = "test_db"
+	search_path_mode = "inherit"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "search_path_mode", "inherit"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "search_path.#", "0"),
+					testAccCheckDBConfigSet("test_db", "search_path"),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name             = "test_db"
+	search_path_mode = "explicit"
+	search_path      = ["public"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "search_path_mode", "explicit"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "search_path.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "search_path.0", "public"),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name             = "test_db"
+	search_path_mode = "reset"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "search_path_mode", "reset"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "search_path.#", "0"),
+					testAccCheckDBConfigReset("test_db", "search_path"),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name             = "test_db"
+	search_path_mode = "reset"
+	search_path      = ["public"]
+}
+`,
+				ExpectError: regexp.MustCompile("search_path cannot be set with search_path_mode"),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_MemorySettings(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+	const testConfig = `
+resource postgresql_database test_db {
+	name         = "test_db"
+	work_mem     = "32MB"
+	temp_buffers = "16MB"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name                 = "test_db"
+	maintenance_work_mem = "1GB"
+	work_mem             = "64MB"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "maintenance_work_mem", "1GB"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "work_mem", "64MB"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "temp_buffers", ""),
+				),
+			},
+			{
+				Config: testConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "maintenance_work_mem", ""),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "work_mem", "32MB"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "temp_buffers", "16MB"),
+				),
+			},
+			{
+				// A parameter set outside of Terraform is not managed: it is not reset.
+				PreConfig: func() {
+					dbExecute(t, dsn, "ALTER DATABASE test_db SET maintenance_work_mem TO '512MB'")
+				},
+				Config: testConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "maintenance_work_mem", ""),
+					testAccCheckDBConfigValue("test_db", "maintenance_work_mem", "512MB"),
+				),
+			},
+			{
+				// A managed parameter changed outside of Terraform is detected.
+				PreConfig: func() {
+					dbExecute(t, dsn, "ALTER DATABASE test_db SET work_mem TO '8MB'")
+				},
+				Config:             testConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestDBSettingManaged(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLDatabase().Schema, map[string]interface{}{
+		"name":                      "mydb",
+		"work_mem":                  "64MB",
+		"session_preload_libraries": []interface{}{"auto_explain"},
+	})
+	assert.True(t, dbSettingManaged(d, "work_mem"))
+	assert.True(t, dbSettingManaged(d, dbSessionPreloadAttr))
+	assert.False(t, dbSettingManaged(d, "temp_buffers"))
+	assert.False(t, dbSettingManaged(d, dbStatementTimeoutAttr))
+	// -1 leaves log_min_duration_statement unset, 0 logs all the statements.
+	assert.False(t, dbSettingManaged(d, dbLogMinDurationAttr))
+	d.Set(dbLogMinDurationAttr, 0)
+	assert.True(t, dbSettingManaged(d, dbLogMinDurationAttr))
+}
+
+func TestAccPostgresqlDatabase_DestructiveRecreate(t *testing.T) {
+	skipIfNotAcc(t)
+
+	const databaseSuffix = "recreate"
+	databaseName, _ := getTestDBNames(databaseSuffix)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource postgresql_database test_db {
+	name       = "%s"
+	lc_collate = "C"
+	lc_ctype   = "C"
+}
+`, databaseName),
+				Check: func(*terraform.State) error {
+					_ = createTestTables(t, databaseSuffix, []string{"testtable1"}, "")
+					return nil
+				},
+			},
+			{
+				Config: fmt.Sprintf(`
+resource postgresql_database test_db {
+	name       = "%s"
+	lc_collate = "POSIX"
+	lc_ctype   = "C"
+}
+`, databaseName),
+				ExpectError: regexp.MustCompile(`changing lc_collate recreates database .* which contains 1 objects`),
+			},
+		},
+	})
+}
+
+func TestDBCommonLocale(t *testing.T) {
+	cases := []struct {
+		collation, ctype string
+		expected         string
+	}{
+		{"C", "C", "C"},
+		{"en_US.UTF-8", "en_US.UTF-8", "en_US.UTF-8"},
+		{"POSIX", "C", ""},
+		{"", "", ""},
+	}
+
+	for _, c := range cases {
+		if out := dbCommonLocale(c.collation, c.ctype); out != c.expected {
+			t.Errorf("dbCommonLocale(%q, %q) returned %q, expected %q", c.collation, c.ctype, out, c.expected)
+		}
+	}
+}
+
+func TestAccPostgresqlDatabase_Locale(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name   = "tf_tests_db_locale"
+	locale = "C"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "locale", "C"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "lc_collate", "C"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "lc_ctype", "C"),
+				),
+			},
+			{
+				// Setting the same value explicitly is consistent.
+				Config: `
+resource postgresql_database test_db {
+	name       = "tf_tests_db_locale"
+	locale     = "C"
+	lc_collate = "C"
+}
+`,
+				PlanOnly: true,
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name       = "tf_tests_db_locale"
+	locale     = "C"
+	lc_collate = "POSIX"
+}
+`,
+				ExpectError: regexp.MustCompile(`lc_collate "POSIX" is inconsistent with locale "C"`),
+			},
+			{
+				// The locale is read back as empty when lc_collate and lc_ctype differ.
+				Config: `
+resource postgresql_database test_db {
+	name       = "tf_tests_db_locale"
+	lc_collate = "POSIX"
+	lc_ctype   = "C"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "locale", ""),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "lc_collate", "POSIX"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "lc_ctype", "C"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_PreventRecreate(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name             = "tf_tests_db_prevent_recreate"
+	lc_collate       = "C"
+	lc_ctype         = "C"
+	prevent_recreate = true
+}
+`,
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name             = "tf_tests_db_prevent_recreate"
+	lc_collate       = "POSIX"
+	lc_ctype         = "C"
+	prevent_recreate = true
+}
+`,
+				ExpectError: regexp.MustCompile("changing lc_collate recreates database tf_tests_db_prevent_recreate"),
+			},
+			{
+				// The database is recreated once prevent_recreate is unset.
+				Config: `
+resource postgresql_database test_db {
+	name       = "tf_tests_db_prevent_recreate"
+	lc_collate = "POSIX"
+	lc_ctype   = "C"
+}
+`,
+				Check: resource.TestCheckResourceAttr("postgresql_database.test_db", "lc_collate", "POSIX"),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_LogSettings(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name                       = "test_db"
+	log_statement              = "ddl"
+	log_min_duration_statement = 500
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "log_statement", "ddl"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "log_min_duration_statement", "500"),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name          = "test_db"
+	log_statement = "all"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "log_statement", "all"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "log_min_duration_statement", "-1"),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name          = "test_db"
+	log_statement = "verbose"
+}
+`,
+				ExpectError: regexp.MustCompile(`expected log_statement to be one of`),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_Settings(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+	settings = {
+		idle_in_transaction_session_timeout = "30s"
+		"app.tenant"                        = "acme"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "settings.%", "2"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "settings.idle_in_transaction_session_timeout", "30s"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "settings.app.tenant", "acme"),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+	settings = {
+		idle_in_transaction_session_timeout = "1min"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "settings.%", "1"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "settings.idle_in_transaction_session_timeout", "1min"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_RawSettings(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+	raw_settings = [
+		"app.greeting=hello world",
+		"app.quote=it's a 'test'",
+		"idle_in_transaction_session_timeout=5s",
+	]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "raw_settings.#", "3"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "raw_settings.0", "app.greeting=hello world"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "raw_settings.1", "app.quote=it's a 'test'"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "raw_settings.2", "idle_in_transaction_session_timeout=5s"),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+	raw_settings = [
+		"app.greeting=hello again",
+	]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "raw_settings.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "raw_settings.0", "app.greeting=hello again"),
+					testAccCheckDBConfigReset("test_db", "idle_in_transaction_session_timeout"),
+					testAccCheckDBConfigReset("test_db", "app.quote"),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckDBConfigReset checks that the parameter is not set on the database anymore.
+func testAccCheckDBConfigReset(dbName, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		set, err := testAccDBConfigIsSet(dbName, name)
+		if err != nil {
+			return err
+		}
+		if set {
+			return fmt.Errorf("parameter %s should have been reset on database %s", name, dbName)
+		}
+		return nil
+	}
+}
+
+// testAccCheckDBConfigSet checks that the parameter is set on the database.
+func testAccCheckDBConfigSet(dbName, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		set, err := testAccDBConfigIsSet(dbName, name)
+		if err != nil {
+			return err
+		}
+		if !set {
+			return fmt.Errorf("parameter %s should be set on database %s", name, dbName)
+		}
+		return nil
+	}
+}
+
+// testAccCheckDBConfigValue checks the value stored for the parameter *name* of the database.
+func testAccCheckDBConfigValue(dbName, name, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		db, err := client.Connect()
+		if err != nil {
+			return err
+		}
+
+		dbConfig, err := readDBConfig(db, dbName)
+		if err != nil {
+			return fmt.Errorf("could not read the parameters of database %s: %w", dbName, err)
+		}
+		if value := readDBSetting(dbConfig, name); value != expected {
+			return fmt.Errorf("parameter %s of database %s is %q, expected %q", name, dbName, value, expected)
+		}
+		return nil
+	}
+}
+
+func testAccDBConfigIsSet(dbName, name string) (bool, error) {
+	client := testAccProvider.Meta().(*Client)
+	db, err := client.Connect()
+	if err != nil {
+		return false, err
+	}
+
+	var set bool
+	err = db.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_db_role_setting s "+
+			"JOIN pg_catalog.pg_database d ON d.oid = s.setdatabase "+
+			"WHERE d.datname = $1 AND s.setrole = 0 AND EXISTS ("+
+			"SELECT 1 FROM unnest(s.setconfig) c WHERE c LIKE $2 || '=%'))",
+		dbName, name,
+	).Scan(&set)
+	if err != nil {
+		return false, fmt.Errorf("could not read the parameters of database %s: %w", dbName, err)
+	}
+	return set, nil
+}
+
+func TestAccPostgresqlDatabase_PrivilegeRoles(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_role "app" {
+	name = "test_app"
+}
+resource postgresql_role "reporting" {
+	name = "test_reporting"
+}
+resource postgresql_database test_db {
+	name          = "test_db"
+	connect_roles = [postgresql_role.app.name, postgresql_role.reporting.name]
+	temp_roles    = [postgresql_role.app.name]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "connect_roles.#", "2"),
+					resource.TestCheckTypeSetElemAttr("postgresql_database.test_db", "connect_roles.*", "test_app"),
+					resource.TestCheckTypeSetElemAttr("postgresql_database.test_db", "connect_roles.*", "test_reporting"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "temp_roles.#", "1"),
+					testAccCheckDBPrivilegeRoles("test_db", "CONNECT", []string{"test_app", "test_reporting"}),
+					testAccCheckDBPrivilegeRoles("test_db", "TEMPORARY", []string{"test_app"}),
+				),
+			},
+			{
+				Config: `
+resource postgresql_role "app" {
+	name = "test_app"
+}
+resource postgresql_role "reporting" {
+	name = "test_reporting"
+}
+resource postgresql_database test_db {
+	name          = "test_db"
+	connect_roles = [postgresql_role.reporting.name]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "connect_roles.#", "1"),
+					resource.TestCheckTypeSetElemAttr("postgresql_database.test_db", "connect_roles.*", "test_reporting"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "temp_roles.#", "0"),
+					testAccCheckDBPrivilegeRoles("test_db", "CONNECT", []string{"test_reporting"}),
+					testAccCheckDBPrivilegeRoles("test_db", "TEMPORARY", []string{}),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckDBPrivilegeRoles(dbName, privilege string, expected []string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		db, err := client.Connect()
+		if err != nil {
+			return err
+		}
+
+		roles, err := readDBPrivilegeRoles(db, dbName, privilege)
+		if err != nil {
+			return err
+		}
+		sort.Strings(roles)
+		if !reflect.DeepEqual(roles, expected) {
+			return fmt.Errorf("Database %s: expected %s granted to %v, got %v", dbName, privilege, expected, roles)
+		}
+		return nil
+	}
+}
+
+func TestAccPostgresqlDatabase_IsTemplate(t *testing.T) {
+	var config = `
+resource postgresql_database test_db {
+	name        = "test_db"
+	is_template = %t
+}
+
+resource postgresql_database test_db_clone {
+	name     = "test_db_clone"
+	template = postgresql_database.test_db.name
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureDBIsTemplate)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db_clone"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "is_template", "true"),
+					testAccCheckPostgresqlDatabaseIsTemplate("test_db", true),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "is_template", "false"),
+					testAccCheckPostgresqlDatabaseIsTemplate("test_db", false),
+				),
+			},
+			{
+				// Flag the database as template outside of Terraform,
+				// the drift must be detected and reverted.
+				PreConfig: func() {
+					client := testAccProvider.Meta().(*Client)
+					db, err := client.Connect()
+					if err != nil {
+						t.Fatalf("could not connect to database: %v", err)
+					}
+					if err := doSetDBIsTemplate(db, "test_db", true); err != nil {
+						t.Fatalf("could not set test_db as template: %v", err)
+					}
+				},
+				Config: fmt.Sprintf(config, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "is_template", "false"),
+					testAccCheckPostgresqlDatabaseIsTemplate("test_db", false),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckPostgresqlDatabaseIsTemplate(dbName string, expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		db, err := client.Connect()
+		if err != nil {
+			return err
+		}
+
+		isTemplate, err := getDBIsTemplate(db, dbName)
+		if err != nil {
+			return err
+		}
+		if isTemplate != expected {
+			return fmt.Errorf("Database %s: expected is_template to be %t, got %t", dbName, expected, isTemplate)
+		}
+		return nil
+	}
+}
+
+// A failed pre_delete_command leaves the database untouched, is_template included.
+func TestAccPostgresqlDatabase_PreDeleteCommandFailureKeepsTemplate(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := func(ignoreFailure bool) string {
+		return fmt.Sprintf(`
+resource postgresql_database "test_db" {
+	name                  = "tf_tests_db_pre_delete_template"
+	is_template           = true
+	pre_delete_command    = ["false"]
+	ignore_backup_failure = %t
+}
+`, ignoreFailure)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureDBIsTemplate)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config(false),
+				Check:  testAccCheckPostgresqlDatabaseIsTemplate("tf_tests_db_pre_delete_template", true),
+			},
+			{
+				Config:      config(false),
+				Destroy:     true,
+				ExpectError: regexp.MustCompile(`Error executing pre_delete_command before dropping database`),
+			},
+			{
+				// is_template is unchanged, so the database can be dropped at the end of the test.
+				Config: config(true),
+				Check:  testAccCheckPostgresqlDatabaseIsTemplate("tf_tests_db_pre_delete_template", true),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_PublicSchemaCreate(t *testing.T) {
+	skipIfNotAcc(t)
+
+	var config = `
+resource postgresql_database test_db {
+	name                 = "test_db"
+	public_schema_create = %t
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "public_schema_create", "false"),
+					testAccCheckPostgresqlDatabasePublicSchemaCreate("test_db", false),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "public_schema_create", "true"),
+					testAccCheckPostgresqlDatabasePublicSchemaCreate("test_db", true),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckPostgresqlDatabasePublicSchemaCreate(dbName string, expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		db, err := client.Connect()
+		if err != nil {
+			return err
+		}
+
+		publicSchemaCreate, err := getDBPublicSchemaCreate(db, dbName)
+		if err != nil {
+			return err
+		}
+		if publicSchemaCreate != expected {
+			return fmt.Errorf("Database %s: expected CREATE on public schema to be %t, got %t", dbName, expected, publicSchemaCreate)
+		}
+		return nil
+	}
+}
+
+// Test the case where the database has already been created by an interrupted apply:
+// it is not adopted without adopt_existing.
+func TestAccPostgresqlDatabase_AlreadyExists(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	dbExecute(t, dsn, "CREATE DATABASE test_db TEMPLATE template0 ENCODING 'UTF8'")
+	defer func() {
+		dbExecute(t, dsn, "DROP DATABASE IF EXISTS test_db")
+	}()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+}
+`,
+				ExpectError: regexp.MustCompile(`database "test_db" already exists`),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_RoleConnLimits(t *testing.T) {
+	skipIfNotAcc(t)
+
+	for _, role := range []string{"tenant_a", "tenant_b"} {
+		teardown := createTestRole(t, role)
+		defer teardown()
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+	role_connection_limits = {
+		tenant_a = 5
+		tenant_b = 10
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "role_connection_limits.%", "2"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "role_connection_limits.tenant_a", "5"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "role_connection_limits.tenant_b", "10"),
+					testAccCheckRoleConnLimit("tenant_a", 5),
+					testAccCheckRoleConnLimit("tenant_b", 10),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+	role_connection_limits = {
+		tenant_a = 2
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "role_connection_limits.%", "1"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "role_connection_limits.tenant_a", "2"),
+					testAccCheckRoleConnLimit("tenant_a", 2),
+					testAccCheckRoleConnLimit("tenant_b", -1),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckRoleConnLimit(role string, expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		db, err := client.Connect()
+		if err != nil {
+			return err
+		}
+
+		var connLimit int
+		if err := db.QueryRow("SELECT rolconnlimit FROM pg_roles WHERE rolname = $1", role).Scan(&connLimit); err != nil {
+			return fmt.Errorf("could not read connection limit of role %s: %w", role, err)
+		}
+		if connLimit != expected {
+			return fmt.Errorf("Role %s: expected connection limit to be %d, got %d", role, expected, connLimit)
+		}
+		return nil
+	}
+}
+
+func TestAccPostgresqlDatabase_VerifyCreate(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name          = "test_db"
+	template      = "template0"
+	encoding      = "LATIN1"
+	lc_collate    = "C"
+	lc_ctype      = "C"
+	verify_create = true
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "encoding", "LATIN1"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "verify_create", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_CreatedAt(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db_none {
+	name = "test_db_none"
+}
+
+resource postgresql_database test_db_comment {
+	name               = "test_db_comment"
+	created_at_tracker = "comment"
+}
+
+resource postgresql_database test_db_table {
+	name               = "test_db_table"
+	created_at_tracker = "table"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db_none", "created_at", ""),
+					resource.TestMatchResourceAttr("postgresql_database.test_db_comment", "created_at", regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T`)),
+					resource.TestMatchResourceAttr("postgresql_database.test_db_table", "created_at", regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T`)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_Prototype(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+
+	prototype {
+		bootstrap_sql = [
+			"CREATE SCHEMA app",
+			"CREATE TABLE app.settings (key text PRIMARY KEY, value text)",
+		]
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "prototype.0.bootstrap_sql.#", "2"),
+					testAccCheckPostgresqlDatabaseHasTable("test_db", "app.settings", true),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_RecreateHooks(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+	defer dbExecute(t, dsn, "DROP ROLE IF EXISTS tf_tests_hook_marker")
+
+	// hook_log records whether the marker role created by pre_destroy_sql
+	// existed when post_create_sql was executed.
+	checkHookLog := func(expected int) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			db, err := sql.Open("postgres", config.connStr("tf_tests_db_hooks"))
+			if err != nil {
+				return fmt.Errorf("could not connect to tf_tests_db_hooks: %w", err)
+			}
+			defer db.Close()
+
+			var count int
+			if err := db.QueryRow("SELECT count(*) FROM hook_log").Scan(&count); err != nil {
+				return fmt.Errorf("could not read hook_log: %w", err)
+			}
+			if count != expected {
+				return fmt.Errorf("expected %d rows in hook_log, got %d", expected, count)
+			}
+			return nil
+		}
+	}
+
+	const postCreateSQL = `["CREATE TABLE hook_log AS SELECT rolname FROM pg_catalog.pg_roles WHERE rolname = 'tf_tests_hook_marker'"]`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource postgresql_database "test_db" {
+	name            = "tf_tests_db_hooks"
+	template        = "template0"
+	pre_destroy_sql = ["CREATE ROLE tf_tests_hook_marker"]
+	post_create_sql = %s
+}
+`, postCreateSQL),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					checkHookLog(0),
+				),
+			},
+			{
+				// The template change recreates the database: pre_destroy_sql of
+				// the state is executed first, then post_create_sql.
+				Config: fmt.Sprintf(`
+resource postgresql_database "test_db" {
+	name            = "tf_tests_db_hooks"
+	template        = "template1"
+	pre_destroy_sql = ["DROP ROLE tf_tests_hook_marker"]
+	post_create_sql = %s
+}
+`, postCreateSQL),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					checkHookLog(1),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_PostCreateMaintenance(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+
+	// ANALYZE records the statistics of the table created by post_create_sql.
+	checkAnalyzed := func(*terraform.State) error {
+		db, err := sql.Open("postgres", config.connStr("tf_tests_db_maintenance"))
+		if err != nil {
+			return fmt.Errorf("could not connect to tf_tests_db_maintenance: %w", err)
+		}
+		defer db.Close()
+
+		var count int
+		if err := db.QueryRow("SELECT count(*) FROM pg_catalog.pg_stats WHERE tablename = 'primed'").Scan(&count); err != nil {
+			return fmt.Errorf("could not read the statistics of primed: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("expected table primed to be analyzed")
+		}
+		return nil
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database "test_db" {
+	name                    = "tf_tests_db_maintenance"
+	lc_collate              = "C"
+	lc_ctype                = "C"
+	post_create_sql         = ["CREATE TABLE primed AS SELECT generate_series(1, 100) AS id"]
+	post_create_maintenance = "analyze"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					checkAnalyzed,
+				),
+			},
+			{
+				// The database is recreated, VACUUM cannot run in a transaction.
+				Config: `
+resource postgresql_database "test_db" {
+	name                       = "tf_tests_db_maintenance"
+	lc_collate                 = "POSIX"
+	lc_ctype                   = "C"
+	allow_destructive_recreate = true
+	post_create_sql            = ["CREATE TABLE primed AS SELECT generate_series(1, 100) AS id"]
+	post_create_maintenance    = "vacuum_analyze"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					checkAnalyzed,
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_PrototypeRollback(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+
+	prototype {
+		bootstrap_sql = [
+			"CREATE TABLE bootstrapped (id int)",
+			"CREATE TABLE invalid (",
+		]
+	}
+}
+`,
+				ExpectError: regexp.MustCompile("Error executing bootstrap statement 1"),
+			},
+			{
+				// The database is tainted, check that the first statement has been rolled back.
+				PreConfig: func() {
+					db, err := sql.Open("postgres", config.connStr("test_db"))
+					if err != nil {
+						t.Fatalf("could not connect to test_db: %v", err)
+					}
+					defer db.Close()
+
+					var exists bool
+					if err := db.QueryRow("SELECT to_regclass('bootstrapped') IS NOT NULL").Scan(&exists); err != nil {
+						t.Fatalf("could not check bootstrapped table: %v", err)
+					}
+					if exists {
+						t.Fatalf("bootstrap statements have not been rolled back")
+					}
+				},
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+}
+`,
+				Check: testAccCheckPostgresqlDatabaseHasTable("test_db", "bootstrapped", false),
+			},
+		},
+	})
+}
+
+func testAccCheckPostgresqlDatabaseHasTable(dbName, table string, expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		txn, err := startTransaction(client, dbName)
+		if err != nil {
+			return err
+		}
+		defer deferredRollback(txn)
+
+		var exists bool
+		if err := txn.QueryRow("SELECT to_regclass($1) IS NOT NULL", table).Scan(&exists); err != nil {
+			return fmt.Errorf("could not check table %s: %w", table, err)
+		}
+		if exists != expected {
+			return fmt.Errorf("Database %s: expected table %s to exist: %t, got %t", dbName, table, expected, exists)
+		}
+		return nil
+	}
+}
+
+func TestAccPostgresqlDatabase_CollationVersion(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureDatabaseCollationVersion)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "collation_version_mismatch", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_OID(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureDatabaseOID)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "tf_tests_db_oid"
+	oid  = 987654
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "oid", "987654"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_CreateTimeout(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database test_db {
+	name = "test_db"
+
+	prototype {
+		bootstrap_sql = ["SELECT pg_sleep(30)"]
+	}
+
+	timeouts {
+		create = "2s"
+	}
+}
+`,
+				ExpectError: regexp.MustCompile("context deadline exceeded"),
+			},
+		},
+	})
+}
+
+// Test the case where we need to grant the owner to the connected user.
+// The owner should be revoked
+func TestAccPostgresqlDatabase_GrantOwner(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	var stateConfig = `
+resource postgresql_role "test_owner" {
+       name = "test_owner"
+}
+resource postgresql_database "test_db" {
+       name  = "test_db"
+       owner = "${postgresql_role.test_owner.name}"
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: stateConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", "test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", "test_owner"),
+
+					// check if connected user does not have test_owner granted anymore.
+					checkUserMembership(t, dsn, config.Username, "test_owner", false),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_RenameAndOwner(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_role "test_owner" {
+       name = "test_owner"
+}
+resource postgresql_role "test_owner2" {
+       name = "test_owner2"
+}
+resource postgresql_database "test_db" {
+       name  = "test_db"
+       owner = "${postgresql_role.test_owner.name}"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", "test_owner"),
+				),
+			},
+			{
+				Config: `
+resource postgresql_role "test_owner" {
+       name = "test_owner"
+}
+resource postgresql_role "test_owner2" {
+       name = "test_owner2"
+}
+resource postgresql_database "test_db" {
+       name  = "test_db_renamed"
+       owner = "${postgresql_role.test_owner2.name}"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", "test_db_renamed"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", "test_owner2"),
+
+					// check if connected user does not have test_owner2 granted anymore.
+					checkUserMembership(t, dsn, config.Username, "test_owner2", false),
+				),
+			},
+		},
+	})
+}
+
+// The rename keeps the memberships of the connecting user in the owner which existed before.
+func TestAccPostgresqlDatabase_RenameKeepsOwnerMembership(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	teardown := createTestRole(t, "test_owner")
+	defer teardown()
+	dbExecute(t, dsn, fmt.Sprintf("GRANT test_owner TO %s", pq.QuoteIdentifier(config.Username)))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database "test_db" {
+	name  = "test_db"
+	owner = "test_owner"
+}
+`,
+				Check: checkUserMembership(t, dsn, config.Username, "test_owner", true),
+			},
+			{
+				Config: `
+resource postgresql_database "test_db" {
+	name  = "test_db_renamed"
+	owner = "test_owner"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", "test_db_renamed"),
+					testAccCheckDBOwner("test_db_renamed", "test_owner"),
+					checkUserMembership(t, dsn, config.Username, "test_owner", true),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_LocaleProviders(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	db, err := config.NewClient("postgres").Connect()
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	if !db.featureSupported(featureDatabaseLocaleProvider) {
+		t.Skip("Skip test: ICU databases are not supported")
+	}
+
+	dbExecute(t, dsn, "CREATE DATABASE tf_tests_db_icu TEMPLATE template0 LOCALE_PROVIDER icu ICU_LOCALE 'en-US' LOCALE 'C'")
+	defer dbExecute(t, dsn, "DROP DATABASE IF EXISTS tf_tests_db_icu")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database "libc" {
+	name       = "tf_tests_db_libc"
+	lc_collate = "C"
+	lc_ctype   = "C"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.libc", "lc_collate", "C"),
+					resource.TestCheckResourceAttr("postgresql_database.libc", "lc_ctype", "C"),
+				),
+			},
+			{
+				// The libc locale of the ICU database is set, it is read as is.
+				ResourceName:  "postgresql_database.icu",
+				ImportState:   true,
+				ImportStateId: "tf_tests_db_icu",
+				Config: `
+resource postgresql_database "icu" {
+	name = "tf_tests_db_icu"
+}
+`,
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 || states[0].Attributes["lc_collate"] != "C" {
+						return fmt.Errorf("expected lc_collate C for the ICU database, got: %v", states)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_MixedCaseName(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database "test_db" {
+	name = "MixedCase"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", "MixedCase"),
+					// The name is quoted, it has not been folded to lowercase.
+					testAccCheckDBConnLimit("MixedCase", -1),
+				),
+			},
+			{
+				ResourceName:  "postgresql_database.test_db",
+				ImportState:   true,
+				ImportStateId: "MixedCase",
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 || states[0].Attributes["name"] != "MixedCase" {
+						return fmt.Errorf("expected the database MixedCase to be imported, got: %v", states)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+// The reserved words are valid names: they are quoted in the queries, bound as
+// parameters in the catalog lookups and escaped in the connection strings.
+func TestAccPostgresqlDatabase_ReservedWordName(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database "test_db" {
+	name              = "order"
+	connection_limit  = 5
+	statement_timeout = "30s"
+	search_path       = ["select", "public"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", "order"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "search_path.0", "select"),
+					testAccCheckDBConnLimit("order", 5),
+				),
+			},
+			{
+				ResourceName:  "postgresql_database.test_db",
+				ImportState:   true,
+				ImportStateId: "order",
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 || states[0].Attributes["name"] != "order" || states[0].Attributes["connection_limit"] != "5" {
+						return fmt.Errorf("expected the database order to be imported, got: %v", states)
+					}
+					return nil
+				},
+			},
+			{
+				// The database is renamed in place.
+				Config: `
+resource postgresql_database "test_db" {
+	name              = "user"
+	connection_limit  = 5
+	statement_timeout = "30s"
+	search_path       = ["select", "public"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "id", "user"),
+					testAccCheckDBConnLimit("user", 5),
+				),
+			},
+			{
+				ResourceName:  "postgresql_database.test_db",
+				ImportState:   true,
+				ImportStateId: "user",
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 || states[0].Attributes["name"] != "user" || states[0].Attributes["statement_timeout"] != "30s" {
+						return fmt.Errorf("expected the database user to be imported, got: %v", states)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_ImportDiscovered(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	resourceConfig := `
+resource postgresql_database "discovered" {
+	name             = "tf_tests_db_discovered"
+	connection_limit = 5
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				// The database is created outside of Terraform, then discovered.
+				PreConfig: func() {
+					dbExecute(t, dsn, "CREATE DATABASE tf_tests_db_discovered CONNECTION LIMIT 5")
+				},
+				Config: `
+data "postgresql_databases" "all" {}
+`,
+				Check: resource.TestCheckTypeSetElemAttr("data.postgresql_databases.all", "databases.*", "tf_tests_db_discovered"),
+			},
+			{
+				ResourceName:       "postgresql_database.discovered",
+				ImportState:        true,
+				ImportStateId:      "tf_tests_db_discovered",
+				ImportStatePersist: true,
+				Config:             resourceConfig,
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 || states[0].Attributes["connection_limit"] != "5" {
+						return fmt.Errorf("expected connection_limit 5 for the imported database, got: %v", states)
+					}
+					return nil
+				},
+			},
+			{
+				// The plan following the import must be empty.
+				Config:   resourceConfig,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+// is_template is read from the imported database when it is not configured,
+// the database is not made a non-template one.
+func TestAccPostgresqlDatabase_ImportTemplate(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	resourceConfig := `
+resource postgresql_database "template" {
+	name = "tf_tests_db_import_template"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureDBIsTemplate)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					dbExecute(t, dsn, "CREATE DATABASE tf_tests_db_import_template IS_TEMPLATE true")
+				},
+				ResourceName:       "postgresql_database.template",
+				ImportState:        true,
+				ImportStateId:      "tf_tests_db_import_template",
+				ImportStatePersist: true,
+				Config:             resourceConfig,
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 || states[0].Attributes["is_template"] != "true" {
+						return fmt.Errorf("expected is_template true for the imported database, got: %v", states)
+					}
+					return nil
+				},
+			},
+			{
+				// The plan following the import must be empty.
+				Config:   resourceConfig,
+				PlanOnly: true,
+			},
+			{
+				Config: resourceConfig,
+				Check:  resource.TestCheckResourceAttr("postgresql_database.template", "is_template", "true"),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_SelfDrop(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	// The provider is connected to the database it drops.
+	providerConfig := `
+provider "postgresql" {
+	database = "tf_tests_db_self_drop"
+}
+
+resource postgresql_database "self" {
+	name = "tf_tests_db_self_drop"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		// The provider cannot check the database has been dropped from a connection to it.
+		CheckDestroy: func(s *terraform.State) error {
+			exists, err := checkDatabaseExists(config.NewClient("postgres"), "tf_tests_db_self_drop")
+			if err != nil {
+				return err
+			}
+			if exists {
+				return errors.New("Db still exists after destroy")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				ResourceName:       "postgresql_database.self",
+				ImportState:        true,
+				ImportStateId:      "tf_tests_db_self_drop",
+				ImportStatePersist: true,
+				PreConfig: func() {
+					dbExecute(t, dsn, "CREATE DATABASE tf_tests_db_self_drop")
+				},
+				Config: providerConfig,
+			},
+			{
+				Config: providerConfig,
+				Check:  testAccCheckPostgresqlDatabaseExists("postgresql_database.self"),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_ConnectionLimitAboveMax(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database "test_db" {
+	name             = "tf_tests_db_conn_limit_max"
+	connection_limit = 5
+}
+`,
+				Check: testAccCheckDBConnLimit("tf_tests_db_conn_limit_max", 5),
+			},
+			{
+				// A limit above max_connections is only warned about.
+				Config: `
+resource postgresql_database "test_db" {
+	name             = "tf_tests_db_conn_limit_max"
+	connection_limit = 100000
+}
+`,
+				Check: testAccCheckDBConnLimit("tf_tests_db_conn_limit_max", 100000),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_AdoptExisting(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					dbExecute(t, dsn, "CREATE DATABASE tf_tests_db_adopt CONNECTION LIMIT 7")
+				},
+				// The database is not adopted by default.
+				Config: `
+resource postgresql_database "test_db" {
+	name = "tf_tests_db_adopt"
+}
+`,
+				ExpectError: regexp.MustCompile(`already exists \(set adopt_existing or import the database to manage it\)`),
+			},
+			{
+				// The connection limit is not set in the configuration, so it is kept
+				// on adoption, but the next plan changes it to its default.
+				Config: `
+resource postgresql_database "test_db" {
+	name           = "tf_tests_db_adopt"
+	adopt_existing = true
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					testAccCheckDBConnLimit("tf_tests_db_adopt", 7),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "connection_limit", "7"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					dbExecute(t, dsn, "CREATE DATABASE tf_tests_db_adopt CONNECTION LIMIT 7")
+				},
+				// The attributes set in the configuration are applied.
+				Config: `
+resource postgresql_database "test_db" {
+	name             = "tf_tests_db_adopt"
+	adopt_existing   = true
+	connection_limit = 3
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDBConnLimit("tf_tests_db_adopt", 3),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "connection_limit", "3"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_OrphanedOwner(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	// A role owning a database cannot be dropped: the owner is replaced in the
+	// catalog by an OID which does not belong to any role, like after a dropped owner.
+	orphanDatabase := func() {
+		dbExecute(t, dsn, "UPDATE pg_catalog.pg_database SET datdba = 4294967000 WHERE datname = 'tf_tests_db_orphan'")
+	}
+
+	testConfig := `
+resource postgresql_role "owner" {
+	name = "tf_tests_orphan_owner"
+}
+
+resource postgresql_database "test_db" {
+	name                    = "tf_tests_db_orphan"
+	owner                   = postgresql_role.owner.name
+	reassign_orphaned_owner = %t
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testConfig, false),
+				Check: resource.TestCheckResourceAttr(
+					"postgresql_database.test_db", "owner", "tf_tests_orphan_owner",
+				),
+			},
+			{
+				// The orphaned owner is read and reported, but not reassigned.
+				PreConfig: orphanDatabase,
+				Config:    fmt.Sprintf(testConfig, false),
+				Check: resource.TestCheckResourceAttr(
+					"postgresql_database.test_db", "owner", "unknown (OID=4294967000)",
+				),
+			},
+			{
+				Config: fmt.Sprintf(testConfig, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", "tf_tests_orphan_owner"),
+					testAccCheckDBOwner("tf_tests_db_orphan", "tf_tests_orphan_owner"),
+				),
+			},
+			{
+				// The database can also be dropped with an orphaned owner.
+				PreConfig: orphanDatabase,
+				Config:    fmt.Sprintf(testConfig, false),
+				Destroy:   true,
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_TemplateEncoding(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	db, err := sql.Open("postgres", config.connStr("postgres"))
+	if err != nil {
+		t.Fatalf("could not create connection pool: %v", err)
+	}
+	defer db.Close()
+
+	var templateEncoding string
+	if err := db.QueryRow("SELECT pg_encoding_to_char(encoding) FROM pg_database WHERE datname = 'template1'").Scan(&templateEncoding); err != nil {
+		t.Fatalf("could not read the encoding of template1: %v", err)
+	}
+	otherEncoding := "SQL_ASCII"
+	if templateEncoding == otherEncoding {
+		otherEncoding = "UTF8"
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				// Refused at plan time instead of by CREATE DATABASE.
+				Config: fmt.Sprintf(`
+resource postgresql_database "test_db" {
+	name     = "tf_tests_db_template_encoding"
+	template = "template1"
+	encoding = "%s"
+}
+`, otherEncoding),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("is incompatible with the encoding of template"),
+			},
+			{
+				Config: fmt.Sprintf(`
+resource postgresql_database "test_db" {
+	name     = "tf_tests_db_template_encoding"
+	template = "template1"
+	encoding = "%s"
+}
+`, templateEncoding),
+				Check: resource.TestCheckResourceAttr("postgresql_database.test_db", "encoding", templateEncoding),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_DroppedOutside(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	resourceConfig := `
+resource postgresql_database "test_db" {
+	name = "tf_tests_db_dropped"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: resourceConfig,
+				Check:  testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+			},
+			{
+				// The read removes the missing database from the state, it is planned for creation.
+				PreConfig: func() {
+					dbExecute(t, dsn, "DROP DATABASE tf_tests_db_dropped")
+				},
+				Config:             resourceConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_ImportMissing(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				ResourceName:  "postgresql_database.missing",
+				ImportState:   true,
+				ImportStateId: "tf_tests_db_import_missing",
+				Config: `
+resource postgresql_database "missing" {
+	name = "tf_tests_db_import_missing"
+}
+`,
+				ExpectError: regexp.MustCompile(`PostgreSQL database "tf_tests_db_import_missing" does not exist`),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_RenameAndConnectionLimit(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database "test_db" {
+	name             = "tf_tests_db_conn_limit"
+	connection_limit = 5
+}
+`,
 				Check: resource.ComposeTestCheckFunc(
-					testAccCheckPostgresqlDatabaseExists("postgresql_database.mydb"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.mydb", "name", "mydb"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.mydb", "owner", "myrole"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.default_opts", "owner", "myrole"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.default_opts", "name", "default_opts_name"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.default_opts", "template", "template0"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.default_opts", "encoding", "UTF8"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.default_opts", "lc_collate", "C"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.default_opts", "lc_ctype", "C"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.default_opts", "tablespace_name", "pg_default"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.default_opts", "connection_limit", "-1"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.default_opts", "is_template", "false"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.default_opts", "alter_object_ownership", "false"),
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					testAccCheckDBConnLimit("tf_tests_db_conn_limit", 5),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database "test_db" {
+	name             = "tf_tests_db_conn_limit_renamed"
+	connection_limit = 10
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", "tf_tests_db_conn_limit_renamed"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "connection_limit", "10"),
+					testAccCheckDBConnLimit("tf_tests_db_conn_limit_renamed", 10),
+				),
+			},
+		},
+	})
+}
 
-					resource.TestCheckResourceAttr(
-						"postgresql_database.modified_opts", "owner", "myrole"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.modified_opts", "name", "custom_template_db"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.modified_opts", "template", "template0"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.modified_opts", "encoding", "UTF8"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.modified_opts", "lc_collate", "en_US.UTF-8"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.modified_opts", "lc_ctype", "en_US.UTF-8"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.modified_opts", "tablespace_name", "pg_default"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.modified_opts", "connection_limit", "10"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.modified_opts", "is_template", "true"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.modified_opts", "alter_object_ownership", "true"),
+func TestAccPostgresqlDatabase_UnlimitedConnections(t *testing.T) {
+	skipIfNotAcc(t)
 
-					resource.TestCheckResourceAttr(
-						"postgresql_database.pathological_opts", "owner", "myrole"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.pathological_opts", "name", "bad_template_db"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.pathological_opts", "template", "template0"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.pathological_opts", "encoding", "LATIN1"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.pathological_opts", "lc_collate", "C"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.pathological_opts", "lc_ctype", "C"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.pathological_opts", "tablespace_name", "pg_default"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.pathological_opts", "connection_limit", "0"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.pathological_opts", "is_template", "true"),
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database "test_db" {
+	name             = "tf_tests_db_unlimited"
+	connection_limit = 5
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "unlimited_connections", "false"),
+					testAccCheckDBConnLimit("tf_tests_db_unlimited", 5),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database "test_db" {
+	name                  = "tf_tests_db_unlimited"
+	unlimited_connections = true
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "unlimited_connections", "true"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "connection_limit", "-1"),
+					testAccCheckDBConnLimit("tf_tests_db_unlimited", -1),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database "test_db" {
+	name                  = "tf_tests_db_unlimited"
+	unlimited_connections = true
+	connection_limit      = 10
+}
+`,
+				ExpectError: regexp.MustCompile("unlimited_connections = true conflicts with connection_limit = 10"),
+			},
+		},
+	})
+}
 
-					resource.TestCheckResourceAttr(
-						"postgresql_database.pg_default_opts", "owner", "myrole"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.pg_default_opts", "name", "pg_defaults_db"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.pg_default_opts", "template", "DEFAULT"),
-					// resource.TestCheckResourceAttr(
-					// 	"postgresql_database.pg_default_opts", "encoding", "DEFAULT"),
-					// resource.TestCheckResourceAttr(
-					// 	"postgresql_database.pg_default_opts", "lc_collate", "DEFAULT"),
-					// resource.TestCheckResourceAttr(
-					//  "postgresql_database.pg_default_opts", "lc_ctype", "DEFAULT"),
-					// resource.TestCheckResourceAttr(
-					// 	"postgresql_database.pg_default_opts", "tablespace_name", "DEFAULT"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.pg_default_opts", "connection_limit", "0"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.pg_default_opts", "is_template", "true"),
+func TestAccPostgresqlDatabase_RenameWithDependentSchema(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database "test_db" {
+	name = "tf_tests_db_dependent"
+}
+
+resource postgresql_schema "test_schema" {
+	name     = "test_schema"
+	database = postgresql_database.test_db.name
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					testAccCheckPostgresqlSchemaExists("postgresql_schema.test_schema", "test_schema"),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database "test_db" {
+	name             = "tf_tests_db_dependent_renamed"
+	connection_limit = 10
+}
+
+resource postgresql_schema "test_schema" {
+	name     = "test_schema"
+	database = postgresql_database.test_db.name
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "id", "tf_tests_db_dependent_renamed"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", "tf_tests_db_dependent_renamed"),
+					testAccCheckDBConnLimit("tf_tests_db_dependent_renamed", 10),
+					// The schema follows the new name of the database within the same apply.
+					resource.TestCheckResourceAttr("postgresql_schema.test_schema", "database", "tf_tests_db_dependent_renamed"),
+					testAccCheckPostgresqlSchemaExists("postgresql_schema.test_schema", "test_schema"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckDBConnLimit(dbName string, expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		db, err := client.Connect()
+		if err != nil {
+			return err
+		}
+
+		var connLimit int
+		err = db.QueryRow("SELECT datconnlimit FROM pg_catalog.pg_database WHERE datname = $1", dbName).Scan(&connLimit)
+		if err != nil {
+			return fmt.Errorf("could not read connection limit of database %s: %w", dbName, err)
+		}
+		if connLimit != expected {
+			return fmt.Errorf("expected connection limit %d for database %s, got %d", expected, dbName, connLimit)
+		}
+		return nil
+	}
+}
+
+func testAccCheckDBOwner(dbName, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		db, err := client.Connect()
+		if err != nil {
+			return err
+		}
+
+		owner, err := getDatabaseOwner(db, dbName)
+		if err != nil {
+			return err
+		}
+		if owner != expected {
+			return fmt.Errorf("expected owner %s for database %s, got %s", expected, dbName, owner)
+		}
+		return nil
+	}
+}
+
+func TestAccPostgresqlDatabase_CreateOwnerIfMissing(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	// The owner role is not managed by the resource and outlives the database.
+	defer dbExecute(t, dsn, "DROP ROLE IF EXISTS tf_tests_created_owner")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_database "test_db" {
+	name                    = "tf_tests_db_created_owner"
+	owner                   = "tf_tests_created_owner"
+	create_owner_if_missing = true
+	owner_password          = "secret"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", "tf_tests_created_owner"),
+					testAccCheckPostgresqlRoleExists("tf_tests_created_owner", nil, nil),
+				),
+			},
+		},
+	})
+}
+
+// Test the case where the owner is the connected user,
+// no role membership should be granted nor revoked.
+func TestAccPostgresqlDatabase_OwnerIsCurrentUser(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource postgresql_database "test_db" {
+	name  = "test_db"
+	owner = "%s"
+}
+`, config.Username),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", config.Username),
+				),
+			},
+			{
+				Config: `
+resource postgresql_role "test_owner" {
+	name = "test_owner"
+}
+resource postgresql_database "test_db" {
+	name  = "test_db"
+	owner = "${postgresql_role.test_owner.name}"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", "test_owner"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(`
+resource postgresql_role "test_owner" {
+	name = "test_owner"
+}
+resource postgresql_database "test_db" {
+	name  = "test_db"
+	owner = "%s"
+}
+`, config.Username),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", config.Username),
 				),
 			},
 		},
 	})
 }
 
-func TestAccPostgresqlDatabase_DefaultOwner(t *testing.T) {
+// Test the case where the connected user is already a member of the owner.
+// There were a bug which was revoking the owner anyway.
+func TestAccPostgresqlDatabase_GrantOwnerNotNeeded(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	dbExecute(
+		t, dsn,
+		fmt.Sprintf("CREATE ROLE test_owner; GRANT test_owner TO %s", config.Username),
+	)
+	defer func() {
+		dbExecute(t, dsn, "DROP ROLE test_owner")
+	}()
+
+	var stateConfig = `
+resource postgresql_database "test_db" {
+       name  = "test_db"
+       owner = "test_owner"
+}
+`
 	resource.Test(t, resource.TestCase{
 		PreCheck:     func() { testAccPreCheck(t) },
 		Providers:    testAccProviders,
 		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccPostgreSQLDatabaseConfig,
+				Config: stateConfig,
 				Check: resource.ComposeTestCheckFunc(
-					testAccCheckPostgresqlDatabaseExists("postgresql_database.mydb_default_owner"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.mydb_default_owner", "name", "mydb_default_owner"),
-					resource.TestCheckResourceAttrSet(
-						"postgresql_database.mydb_default_owner", "owner"),
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", "test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", "test_owner"),
+
+					// check if connected user still have test_owner granted.
+					checkUserMembership(t, dsn, config.Username, "test_owner", true),
 				),
 			},
 		},
 	})
 }
 
-func TestAccPostgresqlDatabase_Update(t *testing.T) {
+// Test the case where the owned objects by the previous database owner are altered.
+func TestAccPostgresqlDatabase_AlterObjectOwnership(t *testing.T) {
+	skipIfNotAcc(t)
 
-	// Version dependent features values will be set in PreCheck
-	// because we need to access database to check Postgres version.
+	const (
+		databaseSuffix = "ownership"
+		tableName      = "testtable1"
+		previous_owner = "previous_owner"
+		new_owner      = "new_owner"
+	)
 
-	// Allow connection depends on Postgres version (needs pg >= 9.5)
-	var allowConnections bool
+	databaseName := fmt.Sprintf("%s_%s", dbNamePrefix, databaseSuffix)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	for _, role := range []string{previous_owner, new_owner} {
+		dbExecute(
+			t, dsn,
+			fmt.Sprintf("CREATE ROLE %s;", role),
+		)
+		defer func(role string) {
+			dbExecute(t, dsn, fmt.Sprintf("DROP ROLE %s", role))
+		}(role)
+
+	}
 
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
-
-			client := testAccProvider.Meta().(*Client)
-			db, err := client.Connect()
-			if err != nil {
-				t.Fatalf("could not connect to database: %v", err)
-			}
-			allowConnections = db.featureSupported(featureDBAllowConnections)
-
+			testSuperuserPreCheck(t)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
 		Steps: []resource.TestStep{
 			{
-				Config: fmt.Sprintf(`
-resource postgresql_database test_db {
-    name = "test_db"
-	allow_connections = "%t"
+				Config: `
+resource postgresql_database "test_db" {
+       name  = "tf_tests_db_ownership"
+       owner = "previous_owner"
+	   alter_object_ownership = true
 }
-`, allowConnections),
+`,
+				Check: func(*terraform.State) error {
+					// To test default privileges, we need to create a table
+					// after having apply the state.
+					_ = createTestTables(t, databaseSuffix, []string{tableName}, previous_owner)
+					return nil
+				},
+			},
+			{
+				Config: `
+resource postgresql_database "test_db" {
+       name  = "tf_tests_db_ownership"
+       owner = "new_owner"
+	   alter_object_ownership = true
+}
+`,
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
-					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", "test_db"),
-					resource.TestCheckResourceAttr("postgresql_database.test_db", "connection_limit", "-1"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.test_db", "allow_connections",
-						strconv.FormatBool(allowConnections),
-					),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", databaseName),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", new_owner),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "alter_object_ownership", "true"),
+
+					checkTableOwnership(t, config.connStr(databaseName), new_owner, tableName),
 				),
 			},
+		},
+	})
+
+}
+
+func TestAccPostgresqlDatabase_AlterObjectOwnershipViaSetRole(t *testing.T) {
+	skipIfNotAcc(t)
+
+	const (
+		databaseSuffix = "ownership_set_role"
+		tableName      = "testtable1"
+		previous_owner = "previous_owner"
+		new_owner      = "new_owner"
+	)
+
+	databaseName := fmt.Sprintf("%s_%s", dbNamePrefix, databaseSuffix)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	for _, role := range []string{previous_owner, new_owner} {
+		dbExecute(
+			t, dsn,
+			fmt.Sprintf("CREATE ROLE %s;", role),
+		)
+		defer func(role string) {
+			dbExecute(t, dsn, fmt.Sprintf("DROP ROLE %s", role))
+		}(role)
+	}
+	// REASSIGN OWNED run as the previous owner requires to be a member of the new one.
+	dbExecute(t, dsn, fmt.Sprintf("GRANT %s TO %s", new_owner, previous_owner))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "postgresql" {
+	reassign_via_set_role = true
+}
+
+resource postgresql_database "test_db" {
+	name                   = "%s"
+	owner                  = "previous_owner"
+	alter_object_ownership = true
+}
+`, databaseName),
+				Check: func(*terraform.State) error {
+					_ = createTestTables(t, databaseSuffix, []string{tableName}, previous_owner)
+					return nil
+				},
+			},
 			{
-				Config: `
-resource postgresql_database test_db {
-	name = "test_db"
-	connection_limit = 2
-	allow_connections = false
+				Config: fmt.Sprintf(`
+provider "postgresql" {
+	reassign_via_set_role = true
 }
-	`,
+
+resource postgresql_database "test_db" {
+	name                   = "%s"
+	owner                  = "new_owner"
+	alter_object_ownership = true
+}
+`, databaseName),
 				Check: resource.ComposeTestCheckFunc(
-					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
-					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", "test_db"),
-					resource.TestCheckResourceAttr("postgresql_database.test_db", "connection_limit", "2"),
-					resource.TestCheckResourceAttr(
-						"postgresql_database.test_db", "allow_connections", "false",
-					),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", new_owner),
+					checkTableOwnership(t, config.connStr(databaseName), new_owner, tableName),
+
+					// No membership has been granted to run REASSIGN.
+					checkUserMembership(t, dsn, config.Username, previous_owner, false),
 				),
 			},
 		},
 	})
 }
 
-// Test the case where we need to grant the owner to the connected user.
-// The owner should be revoked
-func TestAccPostgresqlDatabase_GrantOwner(t *testing.T) {
+// The large objects are only reassigned by a REASSIGN OWNED run in their database.
+func TestAccPostgresqlDatabase_AlterObjectOwnershipLargeObjects(t *testing.T) {
 	skipIfNotAcc(t)
 
+	const (
+		previous_owner = "previous_owner"
+		new_owner      = "new_owner"
+		largeObjectOID = 424242
+	)
+
+	databaseName := fmt.Sprintf("%s_%s", dbNamePrefix, "ownership_lo")
+
 	config := getTestConfig(t)
 	dsn := config.connStr("postgres")
 
-	var stateConfig = `
-resource postgresql_role "test_owner" {
-       name = "test_owner"
+	for _, role := range []string{previous_owner, new_owner} {
+		dbExecute(t, dsn, fmt.Sprintf("CREATE ROLE %s", role))
+		defer func(role string) {
+			dbExecute(t, dsn, fmt.Sprintf("DROP ROLE %s", role))
+		}(role)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource postgresql_database "test_db" {
+	name                   = "%s"
+	owner                  = "previous_owner"
+	alter_object_ownership = true
 }
+`, databaseName),
+				Check: func(*terraform.State) error {
+					dbExecute(t, config.connStr(databaseName), fmt.Sprintf("SELECT lo_create(%d)", largeObjectOID))
+					dbExecute(t, config.connStr(databaseName), fmt.Sprintf("ALTER LARGE OBJECT %d OWNER TO %s", largeObjectOID, previous_owner))
+					return nil
+				},
+			},
+			{
+				Config: fmt.Sprintf(`
 resource postgresql_database "test_db" {
-       name  = "test_db"
-       owner = "${postgresql_role.test_owner.name}"
+	name                   = "%s"
+	owner                  = "new_owner"
+	alter_object_ownership = true
 }
-`
+`, databaseName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", new_owner),
+					checkLargeObjectOwnership(t, config.connStr(databaseName), new_owner, largeObjectOID),
+				),
+			},
+		},
+	})
+}
+
+// The comment and the security label of the database are not owned objects:
+// they are kept when the owner changes, with or without a rename.
+func TestAccPostgresqlDatabase_AlterOwnerKeepsMetadata(t *testing.T) {
+	skipIfNotAcc(t)
+
+	const (
+		previous_owner = "previous_owner"
+		new_owner      = "new_owner"
+	)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	for _, role := range []string{previous_owner, new_owner} {
+		dbExecute(t, dsn, fmt.Sprintf("CREATE ROLE %s;", role))
+		defer func(role string) {
+			dbExecute(t, dsn, fmt.Sprintf("DROP ROLE %s", role))
+		}(role)
+	}
+
+	checkMetadata := func(dbName string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			db, err := sql.Open("postgres", dsn)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			var comment, label sql.NullString
+			err = db.QueryRow(
+				"SELECT pg_catalog.shobj_description(d.oid, 'pg_database'), l.label FROM pg_catalog.pg_database AS d "+
+					"LEFT JOIN pg_catalog.pg_shseclabel AS l ON l.objoid = d.oid AND l.classoid = 'pg_catalog.pg_database'::regclass "+
+					"WHERE d.datname = $1",
+				dbName,
+			).Scan(&comment, &label)
+			if err != nil {
+				return fmt.Errorf("could not read the metadata of database %s: %w", dbName, err)
+			}
+			if comment.String != "kept comment" || label.String != "secret" {
+				return fmt.Errorf("database %s: expected comment %q and label %q, got %q and %q", dbName, "kept comment", "secret", comment.String, label.String)
+			}
+			return nil
+		}
+	}
+
 	resource.Test(t, resource.TestCase{
-		PreCheck:     func() { testAccPreCheck(t) },
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureSecurityLabel)
+			testSuperuserPreCheck(t)
+		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
 		Steps: []resource.TestStep{
 			{
-				Config: stateConfig,
+				Config: `
+resource postgresql_database "test_db" {
+	name                   = "tf_tests_db_metadata"
+	owner                  = "previous_owner"
+	alter_object_ownership = true
+}
+`,
+				Check: func(*terraform.State) error {
+					dbExecute(t, dsn, "COMMENT ON DATABASE tf_tests_db_metadata IS 'kept comment'")
+					dbExecute(t, dsn, "SECURITY LABEL FOR dummy ON DATABASE tf_tests_db_metadata IS 'secret'")
+					return nil
+				},
+			},
+			{
+				Config: `
+resource postgresql_database "test_db" {
+	name                   = "tf_tests_db_metadata"
+	owner                  = "new_owner"
+	alter_object_ownership = true
+}
+`,
 				Check: resource.ComposeTestCheckFunc(
-					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
-					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", "test_db"),
-					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", "test_owner"),
-
-					// check if connected user does not have test_owner granted anymore.
-					checkUserMembership(t, dsn, config.Username, "test_owner", false),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", new_owner),
+					checkMetadata("tf_tests_db_metadata"),
+				),
+			},
+			{
+				Config: `
+resource postgresql_database "test_db" {
+	name                   = "tf_tests_db_metadata_renamed"
+	owner                  = "previous_owner"
+	alter_object_ownership = true
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", previous_owner),
+					checkMetadata("tf_tests_db_metadata_renamed"),
 				),
 			},
 		},
 	})
 }
 
-// Test the case where the connected user is already a member of the owner.
-// There were a bug which was revoking the owner anyway.
-func TestAccPostgresqlDatabase_GrantOwnerNotNeeded(t *testing.T) {
+// The reassignment of the objects is committed on its own:
+// it is kept if a following setter fails.
+func TestAccPostgresqlDatabase_AlterObjectOwnershipThenFailure(t *testing.T) {
 	skipIfNotAcc(t)
 
+	const (
+		databaseSuffix = "ownership_failure"
+		tableName      = "testtable1"
+		previous_owner = "previous_owner"
+		new_owner      = "new_owner"
+	)
+
+	databaseName := fmt.Sprintf("%s_%s", dbNamePrefix, databaseSuffix)
+
 	config := getTestConfig(t)
 	dsn := config.connStr("postgres")
 
-	dbExecute(
-		t, dsn,
-		fmt.Sprintf("CREATE ROLE test_owner; GRANT test_owner TO %s", config.Username),
-	)
-	defer func() {
-		dbExecute(t, dsn, "DROP ROLE test_owner")
-	}()
+	for _, role := range []string{previous_owner, new_owner} {
+		dbExecute(t, dsn, fmt.Sprintf("CREATE ROLE %s;", role))
+		defer func(role string) {
+			dbExecute(t, dsn, fmt.Sprintf("DROP ROLE %s", role))
+		}(role)
+	}
 
-	var stateConfig = `
-resource postgresql_database "test_db" {
-       name  = "test_db"
-       owner = "test_owner"
-}
-`
 	resource.Test(t, resource.TestCase{
-		PreCheck:     func() { testAccPreCheck(t) },
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testSuperuserPreCheck(t)
+		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
 		Steps: []resource.TestStep{
 			{
-				Config: stateConfig,
-				Check: resource.ComposeTestCheckFunc(
-					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
-					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", "test_db"),
-					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", "test_owner"),
-
-					// check if connected user still have test_owner granted.
-					checkUserMembership(t, dsn, config.Username, "test_owner", true),
-				),
+				Config: fmt.Sprintf(`
+resource postgresql_database "test_db" {
+	name                   = "%s"
+	owner                  = "previous_owner"
+	alter_object_ownership = true
+}
+`, databaseName),
+				Check: func(*terraform.State) error {
+					_ = createTestTables(t, databaseSuffix, []string{tableName}, previous_owner)
+					return nil
+				},
+			},
+			{
+				// default_role is set after the reassignment and fails.
+				Config: fmt.Sprintf(`
+resource postgresql_database "test_db" {
+	name                   = "%s"
+	owner                  = "new_owner"
+	alter_object_ownership = true
+	default_role           = "tf_tests_missing_role"
+}
+`, databaseName),
+				ExpectError: regexp.MustCompile(`role "tf_tests_missing_role" does not exist`),
+			},
+			{
+				PreConfig: func() {
+					if err := checkTableOwnership(t, config.connStr(databaseName), new_owner, tableName)(nil); err != nil {
+						t.Fatalf("the reassignment should have been kept: %v", err)
+					}
+				},
+				// Nothing is left to change once the failing attribute is removed.
+				Config: fmt.Sprintf(`
+resource postgresql_database "test_db" {
+	name                   = "%s"
+	owner                  = "new_owner"
+	alter_object_ownership = true
+}
+`, databaseName),
+				PlanOnly: true,
 			},
 		},
 	})
 }
 
-// Test the case where the owned objects by the previous database owner are altered.
-func TestAccPostgresqlDatabase_AlterObjectOwnership(t *testing.T) {
+func TestAccPostgresqlDatabase_GrantNewOwnerOnExisting(t *testing.T) {
 	skipIfNotAcc(t)
 
+	for _, alterObjectOwnership := range []bool{false, true} {
+		t.Run(fmt.Sprintf("alter_object_ownership=%t", alterObjectOwnership), func(t *testing.T) {
+			testAccPostgresqlDatabaseGrantNewOwnerOnExisting(t, alterObjectOwnership)
+		})
+	}
+}
+
+func testAccPostgresqlDatabaseGrantNewOwnerOnExisting(t *testing.T, alterObjectOwnership bool) {
 	const (
-		databaseSuffix = "ownership"
-		tableName      = "testtable1"
-		previous_owner = "previous_owner"
-		new_owner      = "new_owner"
+		tableName     = "testtable1"
+		previousOwner = "grant_previous_owner"
+		newOwner      = "grant_new_owner"
 	)
 
+	databaseSuffix := fmt.Sprintf("grant_new_owner_%t", alterObjectOwnership)
 	databaseName := fmt.Sprintf("%s_%s", dbNamePrefix, databaseSuffix)
 
 	config := getTestConfig(t)
 	dsn := config.connStr("postgres")
 
-	for _, role := range []string{previous_owner, new_owner} {
-		dbExecute(
-			t, dsn,
-			fmt.Sprintf("CREATE ROLE %s;", role),
-		)
+	for _, role := range []string{previousOwner, newOwner} {
+		dbExecute(t, dsn, fmt.Sprintf("CREATE ROLE %s", role))
 		defer func(role string) {
 			dbExecute(t, dsn, fmt.Sprintf("DROP ROLE %s", role))
 		}(role)
+	}
 
+	// When alter_object_ownership is set, the new owner owns the objects
+	// and grant_new_owner_on_existing has nothing to do.
+	expectedTableOwner := previousOwner
+	if alterObjectOwnership {
+		expectedTableOwner = newOwner
 	}
 
 	resource.Test(t, resource.TestCase{
@@ -306,40 +4761,58 @@ func TestAccPostgresqlDatabase_AlterObjectOwnership(t *testing.T) {
 		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
 		Steps: []resource.TestStep{
 			{
-				Config: `
+				Config: fmt.Sprintf(`
 resource postgresql_database "test_db" {
-       name  = "tf_tests_db_ownership"
-       owner = "previous_owner"
-	   alter_object_ownership = true
+	name  = "%s"
+	owner = "%s"
 }
-`,
+`, databaseName, previousOwner),
 				Check: func(*terraform.State) error {
-					// To test default privileges, we need to create a table
-					// after having apply the state.
-					_ = createTestTables(t, databaseSuffix, []string{tableName}, previous_owner)
+					_ = createTestTables(t, databaseSuffix, []string{tableName}, previousOwner)
 					return nil
 				},
 			},
 			{
-				Config: `
+				Config: fmt.Sprintf(`
 resource postgresql_database "test_db" {
-       name  = "tf_tests_db_ownership"
-       owner = "new_owner"
-	   alter_object_ownership = true
+	name                        = "%s"
+	owner                       = "%s"
+	alter_object_ownership      = %t
+	grant_new_owner_on_existing = true
 }
-`,
+`, databaseName, newOwner, alterObjectOwnership),
 				Check: resource.ComposeTestCheckFunc(
-					testAccCheckPostgresqlDatabaseExists("postgresql_database.test_db"),
-					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", databaseName),
-					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", new_owner),
-					resource.TestCheckResourceAttr("postgresql_database.test_db", "alter_object_ownership", "true"),
-
-					checkTableOwnership(t, config.connStr(databaseName), new_owner, tableName),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", newOwner),
+					checkTableOwnership(t, config.connStr(databaseName), expectedTableOwner, tableName),
+					checkTablePrivilege(t, config.connStr(databaseName), newOwner, tableName, "SELECT"),
 				),
 			},
 		},
 	})
+}
+
+func checkTablePrivilege(
+	t *testing.T, dsn, role, tableName, privilege string,
+) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			t.Fatalf("could not create connection pool: %v", err)
+		}
+		defer db.Close()
 
+		var hasPrivilege bool
+		if err := db.QueryRow(
+			"SELECT has_table_privilege($1, $2, $3)", role, tableName, privilege,
+		).Scan(&hasPrivilege); err != nil {
+			t.Fatalf("Error checking table privilege. %v", err)
+		}
+
+		if !hasPrivilege {
+			return fmt.Errorf("User %s should have %s privilege on %s but has not", role, privilege, tableName)
+		}
+		return nil
+	}
 }
 
 func checkUserMembership(
@@ -383,6 +4856,28 @@ func checkUserMembership(
 	}
 }
 
+func checkLargeObjectOwnership(t *testing.T, dsn, owner string, oid int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			t.Fatalf("could not create connection pool: %v", err)
+		}
+		defer db.Close()
+
+		var lomOwner string
+		err = db.QueryRow(
+			"SELECT pg_catalog.pg_get_userbyid(lomowner) FROM pg_catalog.pg_largeobject_metadata WHERE oid = $1", oid,
+		).Scan(&lomOwner)
+		if err != nil {
+			return fmt.Errorf("could not read the owner of large object %d: %w", oid, err)
+		}
+		if lomOwner != owner {
+			return fmt.Errorf("large object %d should be owned by %s but is owned by %s", oid, owner, lomOwner)
+		}
+		return nil
+	}
+}
+
 func checkTableOwnership(
 	t *testing.T, dsn, owner, tableName string,
 ) resource.TestCheckFunc {
@@ -558,3 +5053,213 @@ resource "postgresql_database" "mydb_default_owner" {
 }
 
 `
+
+func TestAccGetRoleOwnedObjectsDatabases(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	otherSuffix, otherTeardown := setupTestDatabase(t, true, true)
+	defer otherTeardown()
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+	otherDBName, _ := getTestDBNames(otherSuffix)
+
+	dropTables := createTestTables(t, otherSuffix, []string{"test_table"}, roleName)
+	defer dropTables()
+
+	config := getTestConfig(t)
+	client := config.NewClient("postgres")
+	db, err := client.Connect()
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+
+	databases, err := getRoleOwnedObjectsDatabases(db, roleName, dbName)
+	if err != nil {
+		t.Fatalf("could not list databases: %v", err)
+	}
+	if len(databases) != 1 || databases[0] != otherDBName {
+		t.Fatalf("expected objects owned by %s only in %s, got: %v", roleName, otherDBName, databases)
+	}
+
+	databases, err = getRoleOwnedObjectsDatabases(db, roleName, otherDBName)
+	if err != nil {
+		t.Fatalf("could not list databases: %v", err)
+	}
+	if len(databases) != 0 {
+		t.Fatalf("expected no other database with objects owned by %s, got: %v", roleName, databases)
+	}
+}
+
+func TestAccDatabaseResourceReadChecksExistence(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	db, err := config.NewClient("postgres").Connect()
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+
+	// The read removes a missing database from the state by itself, without Exists function.
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLDatabase().Schema, map[string]interface{}{
+		"name": "tf_tests_db_read_missing",
+	})
+	d.SetId("tf_tests_db_read_missing")
+	if diags := resourcePostgreSQLDatabaseRead(db, d); diags.HasError() {
+		t.Fatalf("could not read the missing database: %v", diags)
+	}
+	if d.Id() != "" {
+		t.Fatalf("expected the ID of the missing database to be cleared, got %q", d.Id())
+	}
+}
+
+func TestAccChangeDBOwner(t *testing.T) {
+	skipIfNotAcc(t)
+	// The memberships are checked with the connection of the provider.
+	testAccPreCheck(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+	currentUser := config.getDatabaseUsername()
+
+	for _, role := range []string{"tf_tests_owner_a", "tf_tests_owner_b"} {
+		teardown := createTestRole(t, role)
+		defer teardown()
+	}
+	dbExecute(t, dsn, "CREATE DATABASE tf_tests_db_change_owner OWNER tf_tests_owner_a")
+	defer dbExecute(t, dsn, "DROP DATABASE IF EXISTS tf_tests_db_change_owner")
+
+	db, err := config.NewClient("postgres").Connect()
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	if err := changeDBOwner(db, []string{"tf_tests_db_change_owner"}, "tf_tests_owner_b"); err != nil {
+		t.Fatalf("could not change the owner: %v", err)
+	}
+
+	owner, exists, err := readDBOwner(db, "tf_tests_db_change_owner")
+	if err != nil || !exists || owner != "tf_tests_owner_b" {
+		t.Fatalf("expected the database to be owned by tf_tests_owner_b, got %q (exists: %t, error: %v)", owner, exists, err)
+	}
+	// The memberships of the previous and the new owner are only granted for the change.
+	for _, role := range []string{"tf_tests_owner_a", "tf_tests_owner_b"} {
+		if err := checkUserMembership(t, dsn, currentUser, role, false)(nil); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if err := changeDBOwner(db, []string{"tf_tests_db_missing"}, "tf_tests_owner_b"); err == nil {
+		t.Errorf("expected an error for a missing database")
+	}
+}
+
+func TestAccWithOwnerMembershipReleasesLock(t *testing.T) {
+	skipIfNotAcc(t)
+	// The memberships are checked with the connection of the provider.
+	testAccPreCheck(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+	currentUser := config.getDatabaseUsername()
+
+	teardown := createTestRole(t, "tf_tests_owner_lock")
+	defer teardown()
+
+	db, err := config.NewClient("postgres").Connect()
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	other, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	defer other.Close()
+
+	err = withOwnerMembership(db, "tf_tests_owner_lock", func() error {
+		if err := checkUserMembership(t, dsn, currentUser, "tf_tests_owner_lock", true)(nil); err != nil {
+			return err
+		}
+		// The role is not locked while the membership is used: the other operations
+		// on the role do not wait for this one.
+		var locked bool
+		if err := other.QueryRow(
+			"SELECT pg_try_advisory_xact_lock(oid::bigint) FROM pg_roles WHERE rolname = $1", currentUser,
+		).Scan(&locked); err != nil {
+			return err
+		}
+		if !locked {
+			return fmt.Errorf("role %s is still locked while the membership is used", currentUser)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkUserMembership(t, dsn, currentUser, "tf_tests_owner_lock", false)(nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAccWaitForDBIdle(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	config := getTestConfig(t)
+	db, err := config.NewClient("postgres").Connect()
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+
+	previousInterval := dbIdlePollInterval
+	dbIdlePollInterval = 100 * time.Millisecond
+	defer func() { dbIdlePollInterval = previousInterval }()
+
+	// The database is idle, no need to wait.
+	if err := waitForDBIdle(db, dbName, time.Second); err != nil {
+		t.Fatalf("expected database %s to be idle: %v", dbName, err)
+	}
+
+	session, err := sql.Open("postgres", config.connStr(dbName))
+	if err != nil {
+		t.Fatalf("could not create connection pool: %v", err)
+	}
+	defer session.Close()
+
+	runQuery := func(duration string) <-chan error {
+		done := make(chan error, 1)
+		go func() {
+			_, err := session.Exec(fmt.Sprintf("SELECT pg_sleep(%s)", duration))
+			done <- err
+		}()
+		// Let the query start.
+		time.Sleep(500 * time.Millisecond)
+		return done
+	}
+
+	// The timeout is reached while the query is running.
+	done := runQuery("3")
+	if err := waitForDBIdle(db, dbName, time.Second); err == nil {
+		t.Fatal("expected waiting for the database to be idle to time out")
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+
+	// The query is left to finish.
+	done = runQuery("2")
+	start := time.Now()
+	if err := waitForDBIdle(db, dbName, 10*time.Second); err != nil {
+		t.Fatalf("expected database %s to become idle: %v", dbName, err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("expected to wait for the active query, returned after %s", elapsed)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("query should not have been canceled: %v", err)
+	}
+}
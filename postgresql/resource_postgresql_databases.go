@@ -0,0 +1,405 @@
+package postgresql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	dbsNamesAttr     = "names"
+	dbsOwnerAttr     = "owner"
+	dbsEncodingAttr  = "encoding"
+	dbsTemplateAttr  = "template"
+	dbsDatabasesAttr = "databases"
+
+	dbsStatusPresent = "present"
+	dbsStatusMissing = "missing"
+)
+
+func resourcePostgreSQLDatabases() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: limitOperation(batchDatabasesWeight, PGResourceFunc(resourcePostgreSQLDatabasesCreate)),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLDatabasesRead),
+		UpdateContext: limitOperation(batchDatabasesWeight, PGResourceFunc(resourcePostgreSQLDatabasesUpdate)),
+		DeleteContext: limitOperation(batchDatabasesWeight, PGResourceFunc(resourcePostgreSQLDatabasesDelete)),
+		Importer: &schema.ResourceImporter{
+			StateContext: resourcePostgreSQLDatabasesImport,
+		},
+		CustomizeDiff: checkBatchDatabasesRecreateDiff,
+
+		Schema: map[string]*schema.Schema{
+			dbsNamesAttr: {
+				Type:        schema.TypeSet,
+				Required:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "The names of the databases to manage",
+			},
+			dbsOwnerAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The role owning all the databases (defaults to the provider default_owner, then the connecting user)",
+			},
+			dbsEncodingAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "Character set encoding of all the databases (defaults to the provider default_encoding, then UTF8)",
+			},
+			dbsTemplateAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "template0",
+				Description: "The name of the template the databases are created from",
+			},
+			dbsDatabasesAttr: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The status of each database, in name order",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the database",
+						},
+						"owner": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The owner of the database, empty if it is missing",
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "present if the database exists, missing if it has been dropped outside of Terraform",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLDatabasesCreate(db *DBConnection, d *schema.ResourceData) error {
+	names := setToStringSlice(d.Get(dbsNamesAttr).(*schema.Set))
+	created, err := createBatchDatabases(db, d, names)
+	if err != nil {
+		// The databases already created are kept in the state, so they are not
+		// left behind: the resource is tainted and recreated by the next apply.
+		if len(created) > 0 {
+			d.SetId(batchDatabasesID(names))
+			d.Set(dbsNamesAttr, created)
+		}
+		return err
+	}
+
+	d.SetId(batchDatabasesID(names))
+	if d.Get(dbsEncodingAttr).(string) == "" {
+		d.Set(dbsEncodingAttr, defaultDBEncoding(db.client.config))
+	}
+
+	return resourcePostgreSQLDatabasesRead(db, d)
+}
+
+// resourcePostgreSQLDatabasesImport imports the databases whose names are separated
+// by commas in the import ID, e.g. tenant_a,tenant_b. The ID of the imported resource
+// is then derived from the names, as for a created one.
+func resourcePostgreSQLDatabasesImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	names := strings.Split(d.Id(), ",")
+	for _, name := range names {
+		if name == "" {
+			return nil, fmt.Errorf("invalid ID %q, expected the names of the databases separated by commas", d.Id())
+		}
+	}
+	d.SetId(batchDatabasesID(names))
+	d.Set(dbsNamesAttr, names)
+	// The template cannot be read from the server, it is set to its default.
+	d.Set(dbsTemplateAttr, "template0")
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourcePostgreSQLDatabasesRead(db *DBConnection, d *schema.ResourceData) error {
+	names := setToStringSlice(d.Get(dbsNamesAttr).(*schema.Set))
+
+	rows, err := db.Query(
+		"SELECT d.datname, pg_catalog.pg_get_userbyid(d.datdba), pg_catalog.pg_encoding_to_char(d.encoding) "+
+			"FROM pg_catalog.pg_database AS d WHERE d.datname = ANY($1)",
+		pq.Array(names),
+	)
+	if err != nil {
+		return fmt.Errorf("Error reading databases: %w", err)
+	}
+	defer rows.Close()
+
+	owners := map[string]string{}
+	encodings := map[string]bool{}
+	for rows.Next() {
+		var name, owner, encoding string
+		if err := rows.Scan(&name, &owner, &encoding); err != nil {
+			return fmt.Errorf("Error scanning database: %w", err)
+		}
+		owners[name] = owner
+		encodings[encoding] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("Error reading databases: %w", err)
+	}
+
+	// The missing databases are removed from the names, so they are created again
+	// on the next apply, and reported as missing until then.
+	present := []string{}
+	databases := make([]map[string]interface{}, 0, len(names))
+	ownerSet := map[string]bool{}
+	for _, name := range names {
+		owner, ok := owners[name]
+		status := dbsStatusPresent
+		if ok {
+			present = append(present, name)
+			ownerSet[owner] = true
+		} else {
+			log.Printf("[WARN] PostgreSQL database (%q) of %s not found", name, d.Id())
+			status = dbsStatusMissing
+		}
+		databases = append(databases, map[string]interface{}{
+			"name":   name,
+			"owner":  owner,
+			"status": status,
+		})
+	}
+
+	if len(present) == 0 {
+		log.Printf("[WARN] no PostgreSQL database of %s found", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	d.Set(dbsNamesAttr, present)
+	d.Set(dbsDatabasesAttr, databases)
+	// The owner is only read when it is the same for all the databases,
+	// otherwise a change is planned to make it consistent.
+	if len(ownerSet) == 1 {
+		for owner := range ownerSet {
+			d.Set(dbsOwnerAttr, owner)
+		}
+	} else {
+		d.Set(dbsOwnerAttr, "")
+	}
+	// The encoding cannot be changed in place, it is read for the imported databases.
+	if len(encodings) == 1 {
+		for encoding := range encodings {
+			d.Set(dbsEncodingAttr, encoding)
+		}
+	}
+
+	return nil
+}
+
+func resourcePostgreSQLDatabasesUpdate(db *DBConnection, d *schema.ResourceData) error {
+	oldRaw, newRaw := d.GetChange(dbsNamesAttr)
+	oldNames, newNames := oldRaw.(*schema.Set), newRaw.(*schema.Set)
+
+	// If an error occurs, the names in the state are the databases which exist:
+	// the next apply only drops or creates the remaining ones.
+	names := schema.NewSet(schema.HashString, oldNames.List())
+	dropped, err := dropBatchDatabases(db, setToStringSlice(oldNames.Difference(newNames)))
+	for _, name := range dropped {
+		names.Remove(name)
+	}
+	if err != nil {
+		d.Set(dbsNamesAttr, names)
+		return err
+	}
+
+	created, err := createBatchDatabases(db, d, setToStringSlice(newNames.Difference(oldNames)))
+	for _, name := range created {
+		names.Add(name)
+	}
+	if err != nil {
+		d.Set(dbsNamesAttr, names)
+		return err
+	}
+
+	if d.HasChange(dbsOwnerAttr) {
+		// The new databases are already created with the new owner.
+		existing := setToStringSlice(newNames.Intersection(oldNames))
+		if err := changeDBOwner(db, existing, batchDatabasesOwner(db, d)); err != nil {
+			return err
+		}
+	}
+
+	return resourcePostgreSQLDatabasesRead(db, d)
+}
+
+func resourcePostgreSQLDatabasesDelete(db *DBConnection, d *schema.ResourceData) error {
+	names := d.Get(dbsNamesAttr).(*schema.Set)
+	dropped, err := dropBatchDatabases(db, setToStringSlice(names))
+	if err != nil {
+		// The databases already dropped are removed from the state.
+		for _, name := range dropped {
+			names.Remove(name)
+		}
+		d.Set(dbsNamesAttr, names)
+		return err
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// batchDatabasesID returns the ID of the resource created with the databases *names*:
+// a hash of the sorted names, so it stays short with many databases (the names are
+// in the names attribute). It is kept when the names change.
+func batchDatabasesID(names []string) string {
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+	// The names cannot contain a NUL byte, so it separates them unambiguously.
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "\x00")))
+	return "databases-" + hex.EncodeToString(sum[:8])
+}
+
+// checkBatchDatabasesRecreateDiff fails the plan if encoding or template changes:
+// they cannot be changed in place, and recreating all the databases at once
+// would lose all their data.
+func checkBatchDatabasesRecreateDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	changed := []string{}
+	for _, attr := range []string{dbsEncodingAttr, dbsTemplateAttr} {
+		if d.HasChange(attr) {
+			changed = append(changed, attr)
+		}
+	}
+	return checkBatchDatabasesRecreate(changed)
+}
+
+// checkBatchDatabasesRecreate returns an error if the *changed* attributes would
+// require recreating the databases.
+func checkBatchDatabasesRecreate(changed []string) error {
+	if len(changed) == 0 {
+		return nil
+	}
+	return fmt.Errorf(
+		"%s cannot be changed once the databases are created: they would all be dropped "+
+			"and created again, losing their data. Create the databases in a new resource instead",
+		strings.Join(changed, ", "),
+	)
+}
+
+// batchDatabasesWeight returns the weight of an operation for max_concurrent_operations:
+// the number of databases, as they are created or dropped one after the other.
+func batchDatabasesWeight(d *schema.ResourceData) int {
+	return d.Get(dbsNamesAttr).(*schema.Set).Len()
+}
+
+// batchDatabasesOwner returns the owner of the databases: the resource owner,
+// the provider default owner or the connecting user.
+func batchDatabasesOwner(db *DBConnection, d *schema.ResourceData) string {
+	if owner := d.Get(dbsOwnerAttr).(string); owner != "" {
+		return owner
+	}
+	if owner := db.client.config.DefaultOwner; owner != "" {
+		return owner
+	}
+	return db.client.config.getDatabaseUsername()
+}
+
+// createBatchDatabases creates the databases *names* with the shared settings
+// of the resource, and returns the ones created even if it fails on one of them.
+// As for postgresql_database, an existing database is not adopted: the creation fails.
+func createBatchDatabases(db *DBConnection, d *schema.ResourceData, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	owner := batchDatabasesOwner(db, d)
+	encoding := d.Get(dbsEncodingAttr).(string)
+	if encoding == "" {
+		encoding = defaultDBEncoding(db.client.config)
+	}
+	template := d.Get(dbsTemplateAttr).(string)
+
+	created := []string{}
+	err := withOwnerMembership(db, owner, func() error {
+		// As for postgresql_database, the databases are created bypassing PgBouncer.
+		ddlDB, err := db.directConnection()
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			sql := fmt.Sprintf(
+				"CREATE DATABASE %s OWNER %s TEMPLATE %s ENCODING '%s'",
+				pq.QuoteIdentifier(name), pq.QuoteIdentifier(owner), pq.QuoteIdentifier(template), pqQuoteLiteral(encoding),
+			)
+			if _, err := ddlDB.Exec(sql); err != nil {
+				if isPQErrorCode(err, pqErrorCodeDuplicateDatabase) {
+					return fmt.Errorf("Error creating database %q: %w (import it in a postgresql_database or drop it)", name, err)
+				}
+				return fmt.Errorf("Error creating database %q: %w", name, err)
+			}
+			created = append(created, name)
+		}
+		return nil
+	})
+	return created, err
+}
+
+// dropBatchDatabases drops the databases *names* as postgresql_database does,
+// and returns the ones dropped or already missing even if it fails on one of them.
+func dropBatchDatabases(db *DBConnection, names []string) ([]string, error) {
+	// The system databases are checked before dropping any database.
+	for _, name := range names {
+		if err := checkDBDroppable(db, name); err != nil {
+			return nil, fmt.Errorf("%w, remove the resource from the state instead (terraform state rm)", err)
+		}
+	}
+
+	dropped := []string{}
+	for _, name := range names {
+		if err := dropBatchDatabase(db, name); err != nil {
+			return dropped, err
+		}
+		dropped = append(dropped, name)
+	}
+
+	return dropped, nil
+}
+
+// dropBatchDatabase drops the database *name* if it exists, while the connecting user
+// is a member of its owner: IS_TEMPLATE is cleared and the connections are terminated first.
+func dropBatchDatabase(db *DBConnection, name string) error {
+	// A database cannot be dropped from a connection to itself.
+	db, err := maintenanceDBConnection(db, name)
+	if err != nil {
+		return err
+	}
+
+	// The owner is read from the catalog, it may differ from the one of the resource.
+	owner, exists, err := readDBOwner(db, name)
+	if err != nil || !exists {
+		return err
+	}
+
+	return withOwnerMembership(db, owner, func() error {
+		if err := clearDBIsTemplate(db, name); err != nil {
+			return err
+		}
+		if err := terminateBConnections(db, name, false); err != nil {
+			return err
+		}
+		return dropDatabase(db, name, "")
+	})
+}
@@ -0,0 +1,310 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccPostgresqlDatabases_Basic(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabasesDestroy("tf_tests_tenant_a", "tf_tests_tenant_b", "tf_tests_tenant_c"),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_databases "tenants" {
+	names = ["tf_tests_tenant_a", "tf_tests_tenant_b"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDatabasesExist("tf_tests_tenant_a", "tf_tests_tenant_b"),
+					resource.TestCheckResourceAttr("postgresql_databases.tenants", "encoding", "UTF8"),
+					resource.TestCheckResourceAttr("postgresql_databases.tenants", "owner", config.Username),
+					resource.TestCheckResourceAttr("postgresql_databases.tenants", "databases.#", "2"),
+					resource.TestCheckResourceAttr("postgresql_databases.tenants", "databases.0.name", "tf_tests_tenant_a"),
+					resource.TestCheckResourceAttr("postgresql_databases.tenants", "databases.0.status", "present"),
+				),
+			},
+			{
+				Config: `
+resource postgresql_role "owner" {
+	name = "tf_tests_tenants_owner"
+}
+
+resource postgresql_databases "tenants" {
+	names = ["tf_tests_tenant_b", "tf_tests_tenant_c"]
+	owner = postgresql_role.owner.name
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDatabasesExist("tf_tests_tenant_b", "tf_tests_tenant_c"),
+					testAccCheckPostgresqlDatabasesDestroy("tf_tests_tenant_a"),
+					resource.TestCheckResourceAttr("postgresql_databases.tenants", "owner", "tf_tests_tenants_owner"),
+					resource.TestCheckResourceAttr("postgresql_databases.tenants", "databases.0.owner", "tf_tests_tenants_owner"),
+					resource.TestCheckResourceAttr("postgresql_databases.tenants", "databases.1.owner", "tf_tests_tenants_owner"),
+				),
+			},
+			{
+				// A database dropped outside of Terraform is created again.
+				PreConfig: func() {
+					dbExecute(t, dsn, "DROP DATABASE tf_tests_tenant_c")
+				},
+				Config: `
+resource postgresql_role "owner" {
+	name = "tf_tests_tenants_owner"
+}
+
+resource postgresql_databases "tenants" {
+	names = ["tf_tests_tenant_b", "tf_tests_tenant_c"]
+	owner = postgresql_role.owner.name
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDatabasesExist("tf_tests_tenant_b", "tf_tests_tenant_c"),
+					resource.TestCheckResourceAttr("postgresql_databases.tenants", "databases.1.status", "present"),
+				),
+			},
+			{
+				ResourceName:  "postgresql_databases.tenants",
+				ImportState:   true,
+				ImportStateId: "tf_tests_tenant_b,tf_tests_tenant_c",
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 {
+						return fmt.Errorf("expected 1 imported resource, got %d", len(states))
+					}
+					if id := states[0].ID; id != batchDatabasesID([]string{"tf_tests_tenant_b", "tf_tests_tenant_c"}) {
+						return fmt.Errorf("unexpected imported ID %q", id)
+					}
+					attrs := states[0].Attributes
+					if attrs["names.#"] != "2" || attrs["owner"] != "tf_tests_tenants_owner" || attrs["encoding"] != "UTF8" {
+						return fmt.Errorf("unexpected imported attributes: %v", attrs)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabases_PreventRecreate(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabasesDestroy("tf_tests_tenant_a"),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_databases "tenants" {
+	names = ["tf_tests_tenant_a"]
+}
+`,
+				Check: testAccCheckDatabasesExist("tf_tests_tenant_a"),
+			},
+			{
+				// The databases are not recreated from another template.
+				Config: `
+resource postgresql_databases "tenants" {
+	names    = ["tf_tests_tenant_a"]
+	template = "template1"
+}
+`,
+				ExpectError: regexp.MustCompile(`template cannot be changed once the databases are created`),
+			},
+			{
+				Config: `
+resource postgresql_databases "tenants" {
+	names    = ["tf_tests_tenant_a"]
+	encoding = "SQL_ASCII"
+}
+`,
+				ExpectError: regexp.MustCompile(`encoding cannot be changed once the databases are created`),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabases_AlreadyExists(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	dbExecute(t, dsn, "CREATE DATABASE tf_tests_tenant_existing")
+	defer dbExecute(t, dsn, "DROP DATABASE IF EXISTS tf_tests_tenant_existing")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabasesDestroy("tf_tests_tenant_new"),
+		Steps: []resource.TestStep{
+			{
+				// The existing database is not adopted.
+				Config: `
+resource postgresql_databases "tenants" {
+	names = ["tf_tests_tenant_new", "tf_tests_tenant_existing"]
+}
+`,
+				ExpectError: regexp.MustCompile(`database "tf_tests_tenant_existing" already exists`),
+			},
+		},
+	})
+}
+
+func TestBatchDatabasesID(t *testing.T) {
+	id := batchDatabasesID([]string{"tenant_b", "tenant_a"})
+	if !regexp.MustCompile(`^databases-[0-9a-f]{16}$`).MatchString(id) {
+		t.Errorf("batchDatabasesID returned %q, expected databases- and 16 hexadecimal digits", id)
+	}
+	if other := batchDatabasesID([]string{"tenant_a", "tenant_b"}); other != id {
+		t.Errorf("batchDatabasesID depends on the order of the names: %q and %q", id, other)
+	}
+	// The names are not simply joined.
+	if other := batchDatabasesID([]string{"tenant_a,tenant_b"}); other == id {
+		t.Errorf("batchDatabasesID returned %q for a single database named tenant_a,tenant_b", other)
+	}
+
+	// The ID stays short with many databases.
+	names := make([]string, 500)
+	for i := range names {
+		names[i] = fmt.Sprintf("tenant_%d", i)
+	}
+	if id := batchDatabasesID(names); len(id) != len("databases-")+16 {
+		t.Errorf("batchDatabasesID returned %q for 500 databases", id)
+	}
+}
+
+func TestCheckBatchDatabasesRecreate(t *testing.T) {
+	if err := checkBatchDatabasesRecreate(nil); err != nil {
+		t.Errorf("checkBatchDatabasesRecreate returned an error without change: %v", err)
+	}
+	err := checkBatchDatabasesRecreate([]string{"encoding", "template"})
+	if err == nil || !strings.Contains(err.Error(), "encoding, template cannot be changed") {
+		t.Errorf("checkBatchDatabasesRecreate returned %v, expected an error for encoding and template", err)
+	}
+}
+
+func TestResourcePostgreSQLDatabasesImport(t *testing.T) {
+	d := resourcePostgreSQLDatabases().TestResourceData()
+	d.SetId("tenant_a,tenant_b")
+	if _, err := resourcePostgreSQLDatabasesImport(context.Background(), d, nil); err != nil {
+		t.Fatalf("could not import: %v", err)
+	}
+	names := setToStringSlice(d.Get("names").(*schema.Set))
+	sort.Strings(names)
+	if !reflect.DeepEqual(names, []string{"tenant_a", "tenant_b"}) {
+		t.Errorf("imported names %v, expected tenant_a and tenant_b", names)
+	}
+	if template := d.Get("template").(string); template != "template0" {
+		t.Errorf("imported template %q, expected template0", template)
+	}
+	if id := d.Id(); id != batchDatabasesID([]string{"tenant_a", "tenant_b"}) {
+		t.Errorf("imported ID %q, expected the one derived from the names", id)
+	}
+
+	d.SetId("tenant_a,,tenant_b")
+	if _, err := resourcePostgreSQLDatabasesImport(context.Background(), d, nil); err == nil {
+		t.Errorf("expected an error for an empty name in the ID")
+	}
+}
+
+func TestDropBatchDatabasesProtectsSystemDatabases(t *testing.T) {
+	db := &DBConnection{client: &Client{config: Config{ProtectSystemDatabases: true}}}
+
+	// Nothing is dropped if one of the databases is protected.
+	dropped, err := dropBatchDatabases(db, []string{"tenant_a", "postgres"})
+	if err == nil || !strings.Contains(err.Error(), `database "postgres" is a system database`) {
+		t.Fatalf("Expected an error for the system database, got %v", err)
+	}
+	if len(dropped) != 0 {
+		t.Errorf("Expected no database to be dropped, got %v", dropped)
+	}
+}
+
+func TestAccPostgresqlDatabases_DropTemplate(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn := config.connStr("postgres")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureDBIsTemplate)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabasesDestroy("tf_tests_tenant_a", "tf_tests_tenant_b"),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_databases "tenants" {
+	names = ["tf_tests_tenant_a", "tf_tests_tenant_b"]
+}
+`,
+			},
+			{
+				// A database turned into a template outside of Terraform is still dropped.
+				PreConfig: func() {
+					dbExecute(t, dsn, "ALTER DATABASE tf_tests_tenant_a IS_TEMPLATE true")
+				},
+				Config: `
+resource postgresql_databases "tenants" {
+	names = ["tf_tests_tenant_b"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDatabasesExist("tf_tests_tenant_b"),
+					testAccCheckPostgresqlDatabasesDestroy("tf_tests_tenant_a"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckDatabasesExist(names ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		for _, name := range names {
+			exists, err := checkDatabaseExists(client, name)
+			if err != nil {
+				return fmt.Errorf("Error checking db %s", err)
+			}
+			if !exists {
+				return fmt.Errorf("Db %s not found", name)
+			}
+		}
+		return nil
+	}
+}
+
+func testAccCheckPostgresqlDatabasesDestroy(names ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		for _, name := range names {
+			exists, err := checkDatabaseExists(client, name)
+			if err != nil {
+				return fmt.Errorf("Error checking db %s", err)
+			}
+			if exists {
+				return fmt.Errorf("Db %s still exists", name)
+			}
+		}
+		return nil
+	}
+}
@@ -1,7 +1,6 @@
 package postgresql
 
 import (
-	"database/sql"
 	"fmt"
 	"log"
 	"strings"
@@ -15,10 +14,10 @@ import (
 
 func resourcePostgreSQLDefaultPrivileges() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesCreate),
-		Update: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLDefaultPrivilegesRead),
-		Delete: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesDelete),
+		CreateContext: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesCreate),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLDefaultPrivilegesRead),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesDelete),
 
 		Schema: map[string]*schema.Schema{
 			"role": {
@@ -76,16 +75,25 @@ func resourcePostgreSQLDefaultPrivileges() *schema.Resource {
 	}
 }
 
-func resourcePostgreSQLDefaultPrivilegesRead(db *DBConnection, d *schema.ResourceData) error {
-	pgSchema := d.Get("schema").(string)
-	objectType := d.Get("object_type").(string)
-
-	if pgSchema != "" && objectType == "schema" && !db.featureSupported(featurePrivilegesOnSchemas) {
+// checkDefaultPrivilegesObjectType returns an error if the server does not support
+// the default privileges on *objectType*: the ones on schemas (defaclobjtype 'n')
+// exist since PostgreSQL 10.
+func checkDefaultPrivilegesObjectType(db *DBConnection, objectType string) error {
+	if objectType == "schema" && !db.featureSupported(featurePrivilegesOnSchemas) {
 		return fmt.Errorf(
 			"changing default privileges for schemas is not supported for this Postgres version (%s)",
 			db.version,
 		)
 	}
+	return nil
+}
+
+func resourcePostgreSQLDefaultPrivilegesRead(db *DBConnection, d *schema.ResourceData) error {
+	objectType := d.Get("object_type").(string)
+
+	if err := checkDefaultPrivilegesObjectType(db, objectType); err != nil {
+		return err
+	}
 
 	exists, err := checkRoleDBSchemaExists(db, d)
 	if err != nil {
@@ -109,13 +117,12 @@ func resourcePostgreSQLDefaultPrivilegesCreate(db *DBConnection, d *schema.Resou
 	pgSchema := d.Get("schema").(string)
 	objectType := d.Get("object_type").(string)
 
+	if err := checkDefaultPrivilegesObjectType(db, objectType); err != nil {
+		return err
+	}
+	// The default privileges on schemas apply to the schemas created later,
+	// they cannot be restricted to a schema.
 	if pgSchema != "" && objectType == "schema" {
-		if !db.featureSupported(featurePrivilegesOnSchemas) {
-			return fmt.Errorf(
-				"changing default privileges for schemas is not supported for this Postgres version (%s)",
-				db.version,
-			)
-		}
 		return fmt.Errorf("cannot specify `schema` when `object_type` is `schema`")
 	}
 
@@ -175,14 +182,10 @@ func resourcePostgreSQLDefaultPrivilegesCreate(db *DBConnection, d *schema.Resou
 
 func resourcePostgreSQLDefaultPrivilegesDelete(db *DBConnection, d *schema.ResourceData) error {
 	owner := d.Get("owner").(string)
-	pgSchema := d.Get("schema").(string)
 	objectType := d.Get("object_type").(string)
 
-	if pgSchema != "" && objectType == "schema" && !db.featureSupported(featurePrivilegesOnSchemas) {
-		return fmt.Errorf(
-			"changing default privileges for schemas is not supported for this Postgres version (%s)",
-			db.version,
-		)
+	if err := checkDefaultPrivilegesObjectType(db, objectType); err != nil {
+		return err
 	}
 
 	txn, err := startTransaction(db.client, d.Get("database").(string))
@@ -209,7 +212,7 @@ func resourcePostgreSQLDefaultPrivilegesDelete(db *DBConnection, d *schema.Resou
 	return nil
 }
 
-func readRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
+func readRoleDefaultPrivileges(txn *DBTransaction, d *schema.ResourceData) error {
 	role := d.Get("role").(string)
 	owner := d.Get("owner").(string)
 	pgSchema := d.Get("schema").(string)
@@ -278,7 +281,7 @@ func readRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func grantRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
+func grantRoleDefaultPrivileges(txn *DBTransaction, d *schema.ResourceData) error {
 	role := d.Get("role").(string)
 	pgSchema := d.Get("schema").(string)
 
@@ -321,7 +324,7 @@ func grantRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func revokeRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
+func revokeRoleDefaultPrivileges(txn *DBTransaction, d *schema.ResourceData) error {
 	pgSchema := d.Get("schema").(string)
 
 	var inSchema string
@@ -4,10 +4,39 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/blang/semver"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
+func TestCheckDefaultPrivilegesObjectType(t *testing.T) {
+	cases := []struct {
+		version    string
+		objectType string
+		supported  bool
+	}{
+		{"9.6.0", "table", true},
+		{"9.6.0", "sequence", true},
+		{"9.6.0", "function", true},
+		{"9.6.0", "type", true},
+		{"9.6.0", "schema", false},
+		{"10.0.0", "schema", true},
+		{"15.0.0", "schema", true},
+		{"15.0.0", "table", true},
+	}
+
+	for _, c := range cases {
+		db := &DBConnection{client: &Client{}, version: semver.MustParse(c.version)}
+		err := checkDefaultPrivilegesObjectType(db, c.objectType)
+		if c.supported && err != nil {
+			t.Errorf("Unexpected error for %s on version %s: %v", c.objectType, c.version, err)
+		}
+		if !c.supported && err == nil {
+			t.Errorf("Expected an error for %s on version %s", c.objectType, c.version)
+		}
+	}
+}
+
 func TestAccPostgresqlDefaultPrivileges(t *testing.T) {
 	skipIfNotAcc(t)
 
@@ -23,11 +23,11 @@ const (
 
 func resourcePostgreSQLExtension() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLExtensionCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLExtensionRead),
-		Update: PGResourceFunc(resourcePostgreSQLExtensionUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLExtensionDelete),
-		Exists: PGResourceExistsFunc(resourcePostgreSQLExtensionExists),
+		CreateContext: PGResourceFunc(resourcePostgreSQLExtensionCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLExtensionRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLExtensionUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLExtensionDelete),
+		Exists:        PGResourceExistsFunc(resourcePostgreSQLExtensionExists),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -272,7 +272,7 @@ func resourcePostgreSQLExtensionUpdate(db *DBConnection, d *schema.ResourceData)
 	return resourcePostgreSQLExtensionReadImpl(db, d)
 }
 
-func setExtSchema(txn *sql.Tx, d *schema.ResourceData) error {
+func setExtSchema(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(extSchemaAttr) {
 		return nil
 	}
@@ -293,7 +293,7 @@ func setExtSchema(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func setExtVersion(txn *sql.Tx, d *schema.ResourceData) error {
+func setExtVersion(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(extVersionAttr) {
 		return nil
 	}
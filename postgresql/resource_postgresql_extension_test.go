@@ -154,7 +154,7 @@ func TestAccPostgresqlExtension_SchemaRename(t *testing.T) {
 	})
 }
 
-func checkExtensionExists(txn *sql.Tx, extensionName string) (bool, error) {
+func checkExtensionExists(txn *DBTransaction, extensionName string) (bool, error) {
 	var _rez bool
 	err := txn.QueryRow("SELECT TRUE from pg_catalog.pg_extension d WHERE extname=$1", extensionName).Scan(&_rez)
 	switch {
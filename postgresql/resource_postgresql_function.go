@@ -37,11 +37,11 @@ const (
 
 func resourcePostgreSQLFunction() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLFunctionCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLFunctionRead),
-		Update: PGResourceFunc(resourcePostgreSQLFunctionUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLFunctionDelete),
-		Exists: PGResourceExistsFunc(resourcePostgreSQLFunctionExists),
+		CreateContext: PGResourceFunc(resourcePostgreSQLFunctionCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLFunctionRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLFunctionUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLFunctionDelete),
+		Exists:        PGResourceExistsFunc(resourcePostgreSQLFunctionExists),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -290,7 +290,7 @@ func testAccCheckPostgresqlFunctionDestroy(s *terraform.State) error {
 	return nil
 }
 
-func checkFunctionExists(txn *sql.Tx, signature string) (bool, error) {
+func checkFunctionExists(txn *DBTransaction, signature string) (bool, error) {
 	var _rez bool
 	err := txn.QueryRow(fmt.Sprintf("SELECT to_regprocedure('%s') IS NOT NULL", signature)).Scan(&_rez)
 	switch {
@@ -38,10 +38,10 @@ type ResourceSchemeGetter func(string) interface{}
 
 func resourcePostgreSQLGrant() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLGrantCreate),
-		Update: PGResourceFunc(resourcePostgreSQLGrantUpdate),
-		Read:   PGResourceFunc(resourcePostgreSQLGrantRead),
-		Delete: PGResourceFunc(resourcePostgreSQLGrantDelete),
+		CreateContext: PGResourceFunc(resourcePostgreSQLGrantCreate),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLGrantUpdate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLGrantRead),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLGrantDelete),
 
 		Schema: map[string]*schema.Schema{
 			"role": {
@@ -262,7 +262,7 @@ func resourcePostgreSQLGrantDelete(db *DBConnection, d *schema.ResourceData) err
 	return nil
 }
 
-func readDatabaseRolePrivileges(txn *sql.Tx, d *schema.ResourceData, roleOID uint32) error {
+func readDatabaseRolePrivileges(txn *DBTransaction, d *schema.ResourceData, roleOID uint32) error {
 	dbName := d.Get("database").(string)
 	query := `
 SELECT array_agg(privilege_type)
@@ -283,7 +283,7 @@ WHERE grantee = $2
 	return nil
 }
 
-func readSchemaRolePrivileges(txn *sql.Tx, d *schema.ResourceData, roleOID uint32) error {
+func readSchemaRolePrivileges(txn *DBTransaction, d *schema.ResourceData, roleOID uint32) error {
 	dbName := d.Get("schema").(string)
 	query := `
 SELECT array_agg(privilege_type)
@@ -305,7 +305,7 @@ WHERE grantee = $2
 	return nil
 }
 
-func readForeignDataWrapperRolePrivileges(txn *sql.Tx, d *schema.ResourceData, roleOID uint32) error {
+func readForeignDataWrapperRolePrivileges(txn *DBTransaction, d *schema.ResourceData, roleOID uint32) error {
 	objects := d.Get("objects").(*schema.Set).List()
 	fdwName := objects[0].(string)
 	query := `
@@ -328,7 +328,7 @@ WHERE grantee = $2
 	return nil
 }
 
-func readForeignServerRolePrivileges(txn *sql.Tx, d *schema.ResourceData, roleOID uint32) error {
+func readForeignServerRolePrivileges(txn *DBTransaction, d *schema.ResourceData, roleOID uint32) error {
 	objects := d.Get("objects").(*schema.Set).List()
 	srvName := objects[0].(string)
 	query := `
@@ -351,7 +351,7 @@ WHERE grantee = $2
 	return nil
 }
 
-func readColumnRolePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
+func readColumnRolePrivileges(txn *DBTransaction, d *schema.ResourceData) error {
 	objects := d.Get("objects").(*schema.Set)
 
 	missingColumns := d.Get("columns").(*schema.Set) // Getting columns from state.
@@ -429,7 +429,7 @@ ORDER BY col_privs.attname
 	return nil
 }
 
-func readRolePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
+func readRolePrivileges(txn *DBTransaction, d *schema.ResourceData) error {
 	role := d.Get("role").(string)
 	objectType := d.Get("object_type").(string)
 	objects := d.Get("objects").(*schema.Set)
@@ -687,7 +687,7 @@ func createRevokeQuery(getter ResourceSchemeGetter) string {
 	return query
 }
 
-func grantRolePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
+func grantRolePrivileges(txn *DBTransaction, d *schema.ResourceData) error {
 	privileges := []string{}
 	for _, priv := range d.Get("privileges").(*schema.Set).List() {
 		privileges = append(privileges, priv.(string))
@@ -704,7 +704,7 @@ func grantRolePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 	return err
 }
 
-func revokeRolePrivileges(txn *sql.Tx, d *schema.ResourceData, usePrevious bool) error {
+func revokeRolePrivileges(txn *DBTransaction, d *schema.ResourceData, usePrevious bool) error {
 	getter := d.Get
 
 	if usePrevious {
@@ -796,7 +796,7 @@ func generateGrantID(d *schema.ResourceData) string {
 	return strings.Join(parts, "_")
 }
 
-func getRolesToGrant(txn *sql.Tx, d *schema.ResourceData) ([]string, error) {
+func getRolesToGrant(txn *DBTransaction, d *schema.ResourceData) ([]string, error) {
 	// If user we use for Terraform is not a superuser (e.g.: in RDS)
 	// we need to grant owner of the schema and owners of tables in the schema
 	// in order to change theirs permissions.
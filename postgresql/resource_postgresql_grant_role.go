@@ -28,9 +28,9 @@ WHERE
 
 func resourcePostgreSQLGrantRole() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLGrantRoleCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLGrantRoleRead),
-		Delete: PGResourceFunc(resourcePostgreSQLGrantRoleDelete),
+		CreateContext: PGResourceFunc(resourcePostgreSQLGrantRoleCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLGrantRoleRead),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLGrantRoleDelete),
 
 		Schema: map[string]*schema.Schema{
 			"role": {
@@ -182,7 +182,7 @@ func createRevokeRoleQuery(d *schema.ResourceData) string {
 	)
 }
 
-func grantRole(txn *sql.Tx, d *schema.ResourceData) error {
+func grantRole(txn *DBTransaction, d *schema.ResourceData) error {
 	query := createGrantRoleQuery(d)
 	if _, err := txn.Exec(query); err != nil {
 		return fmt.Errorf("could not execute grant query: %w", err)
@@ -190,7 +190,7 @@ func grantRole(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func revokeRole(txn *sql.Tx, d *schema.ResourceData) error {
+func revokeRole(txn *DBTransaction, d *schema.ResourceData) error {
 	query := createRevokeRoleQuery(d)
 	if _, err := txn.Exec(query); err != nil {
 		return fmt.Errorf("could not execute revoke query: %w", err)
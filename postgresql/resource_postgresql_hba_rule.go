@@ -0,0 +1,105 @@
+package postgresql
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	hbaRuleTypeAttr     = "type"
+	hbaRuleDatabaseAttr = "database"
+	hbaRuleUserAttr     = "user"
+	hbaRuleAddressAttr  = "address"
+	hbaRuleMethodAttr   = "method"
+	hbaRuleOptionsAttr  = "options"
+)
+
+// resourcePostgreSQLHBARule manages a host-based authentication rule on the servers
+// supporting it with SQL (see featureHBARules). As no server supports it yet, all its
+// operations fail with a "not supported on this server" error.
+func resourcePostgreSQLHBARule() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: PGResourceFunc(resourcePostgreSQLHBARuleCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLHBARuleRead),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLHBARuleDelete),
+
+		Schema: map[string]*schema.Schema{
+			hbaRuleTypeAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "The connection type matched by the rule: local, host, hostssl, hostnossl, hostgssenc or hostnogssenc",
+				ValidateFunc: validation.StringInSlice([]string{"local", "host", "hostssl", "hostnossl", "hostgssenc", "hostnogssenc"}, false),
+			},
+			hbaRuleDatabaseAttr: {
+				Type:        schema.TypeList,
+				Required:    true,
+				ForceNew:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The database names matched by the rule, or all, sameuser, samerole or replication",
+			},
+			hbaRuleUserAttr: {
+				Type:        schema.TypeList,
+				Required:    true,
+				ForceNew:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The role names matched by the rule, or all",
+			},
+			hbaRuleAddressAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The client addresses matched by the rule (e.g. 10.0.0.0/8), not used by the local rules",
+			},
+			hbaRuleMethodAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The authentication method of the connections matched by the rule (e.g. scram-sha-256)",
+			},
+			hbaRuleOptionsAttr: {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The options of the authentication method",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLHBARuleCreate(db *DBConnection, d *schema.ResourceData) error {
+	if err := checkHBARuleSupported(db); err != nil {
+		return err
+	}
+	// No server supports featureHBARules yet, so there is no statement to run.
+	return fmt.Errorf("the host-based authentication rules of this server (%s) cannot be created", db.version)
+}
+
+func resourcePostgreSQLHBARuleRead(db *DBConnection, d *schema.ResourceData) error {
+	return checkHBARuleSupported(db)
+}
+
+func resourcePostgreSQLHBARuleDelete(db *DBConnection, d *schema.ResourceData) error {
+	// The rule cannot have been created, there is nothing to drop.
+	d.SetId("")
+	return nil
+}
+
+// checkHBARuleSupported returns an error if the host-based authentication rules of the
+// server cannot be managed with SQL.
+func checkHBARuleSupported(db *DBConnection) error {
+	if db.featureSupported(featureHBARules) {
+		return nil
+	}
+	return fmt.Errorf(
+		"postgresql_hba_rule is not supported on this server (%s): its host-based authentication rules "+
+			"cannot be managed with SQL, they are read from pg_hba.conf "+
+			"(set with the ysql_hba_conf_csv flag on YugabyteDB)",
+		db.version,
+	)
+}
@@ -0,0 +1,36 @@
+package postgresql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestResourcePostgreSQLHBARuleNotSupported(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLHBARule().Schema, map[string]interface{}{
+		"type":     "hostssl",
+		"database": []interface{}{"all"},
+		"user":     []interface{}{"app"},
+		"address":  "10.0.0.0/8",
+		"method":   "scram-sha-256",
+	})
+
+	// YugabyteDB reports the version of PostgreSQL it is based on, e.g. 11.2 or 15.12.
+	for _, version := range []string{"9.6.0", "11.2.0", "15.12.0", "17.0.0"} {
+		db := &DBConnection{version: semver.MustParse(version), client: &Client{}}
+
+		err := resourcePostgreSQLHBARuleCreate(db, d)
+		if err == nil || !strings.Contains(err.Error(), "not supported on this server ("+version+")") {
+			t.Errorf("resourcePostgreSQLHBARuleCreate on %s returned %v, expected a not supported error", version, err)
+		}
+		if d.Id() != "" {
+			t.Errorf("resourcePostgreSQLHBARuleCreate on %s set the ID %q", version, d.Id())
+		}
+
+		if err := resourcePostgreSQLHBARuleRead(db, d); err == nil {
+			t.Errorf("resourcePostgreSQLHBARuleRead on %s returned no error", version)
+		}
+	}
+}
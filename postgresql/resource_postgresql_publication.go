@@ -24,11 +24,11 @@ const (
 
 func resourcePostgreSQLPublication() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLPublicationCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLPublicationRead),
-		Delete: PGResourceFunc(resourcePostgreSQLPublicationDelete),
-		Update: PGResourceFunc(resourcePostgreSQLPublicationUpdate),
-		Exists: PGResourceExistsFunc(resourcePostgreSQLPublicationExists),
+		CreateContext: PGResourceFunc(resourcePostgreSQLPublicationCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLPublicationRead),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLPublicationDelete),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLPublicationUpdate),
+		Exists:        PGResourceExistsFunc(resourcePostgreSQLPublicationExists),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -133,7 +133,7 @@ func resourcePostgreSQLPublicationUpdate(db *DBConnection, d *schema.ResourceDat
 	return resourcePostgreSQLPublicationReadImpl(db, d)
 }
 
-func setPubName(txn *sql.Tx, d *schema.ResourceData) error {
+func setPubName(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(pubNameAttr) {
 		return nil
 	}
@@ -149,7 +149,7 @@ func setPubName(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func setPubOwner(txn *sql.Tx, d *schema.ResourceData) error {
+func setPubOwner(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(pubOwnerAttr) {
 		return nil
 	}
@@ -165,7 +165,7 @@ func setPubOwner(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func setPubTables(txn *sql.Tx, d *schema.ResourceData) error {
+func setPubTables(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(pubTablesAttr) {
 		return nil
 	}
@@ -200,7 +200,7 @@ func setPubTables(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func setPubParams(txn *sql.Tx, d *schema.ResourceData, pubViaRootEnabled bool) error {
+func setPubParams(txn *DBTransaction, d *schema.ResourceData, pubViaRootEnabled bool) error {
 	pubName := d.Get(pubNameAttr).(string)
 	paramAlterTemplate := "ALTER PUBLICATION %s %s"
 	publicationParametersString, err := getPublicationParameters(d, pubViaRootEnabled)
@@ -482,7 +482,7 @@ func TestAccPostgresqlPublication_UpdateName(t *testing.T) {
 	})
 }
 
-func checkPublicationExists(txn *sql.Tx, pubName string) (bool, error) {
+func checkPublicationExists(txn *DBTransaction, pubName string) (bool, error) {
 	var _rez bool
 	err := txn.QueryRow("SELECT TRUE from pg_catalog.pg_publication WHERE pubname=$1", pubName).Scan(&_rez)
 	switch {
@@ -150,7 +150,7 @@ func TestAccPostgresqlReplicationSlot_Database(t *testing.T) {
 	})
 }
 
-func checkReplicationSlotExists(txn *sql.Tx, slotName string) (bool, error) {
+func checkReplicationSlotExists(txn *DBTransaction, slotName string) (bool, error) {
 	var _rez bool
 	err := txn.QueryRow("SELECT TRUE from pg_catalog.pg_replication_slots d WHERE slot_name=$1", slotName).Scan(&_rez)
 	switch {
@@ -42,11 +42,11 @@ const (
 
 func resourcePostgreSQLRole() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLRoleCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLRoleRead),
-		Update: PGResourceFunc(resourcePostgreSQLRoleUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLRoleDelete),
-		Exists: PGResourceExistsFunc(resourcePostgreSQLRoleExists),
+		CreateContext: PGResourceFunc(resourcePostgreSQLRoleCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLRoleRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLRoleUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLRoleDelete),
+		Exists:        PGResourceExistsFunc(resourcePostgreSQLRoleExists),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -696,7 +696,7 @@ func resourcePostgreSQLRoleUpdate(db *DBConnection, d *schema.ResourceData) erro
 	return resourcePostgreSQLRoleReadImpl(db, d)
 }
 
-func setRoleName(txn *sql.Tx, d *schema.ResourceData) error {
+func setRoleName(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(roleNameAttr) {
 		return nil
 	}
@@ -718,7 +718,7 @@ func setRoleName(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func setRolePassword(txn *sql.Tx, d *schema.ResourceData) error {
+func setRolePassword(txn *DBTransaction, d *schema.ResourceData) error {
 	// If role is renamed, password is reset (as the md5 sum is also base on the role name)
 	// so we need to update it
 	if !d.HasChange(rolePasswordAttr) && !d.HasChange(roleNameAttr) {
@@ -735,7 +735,7 @@ func setRolePassword(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func setRoleBypassRLS(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
+func setRoleBypassRLS(db *DBConnection, txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(roleBypassRLSAttr) {
 		return nil
 	}
@@ -758,7 +758,7 @@ func setRoleBypassRLS(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) err
 	return nil
 }
 
-func setRoleConnLimit(txn *sql.Tx, d *schema.ResourceData) error {
+func setRoleConnLimit(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(roleConnLimitAttr) {
 		return nil
 	}
@@ -773,7 +773,7 @@ func setRoleConnLimit(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func setRoleCreateDB(txn *sql.Tx, d *schema.ResourceData) error {
+func setRoleCreateDB(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(roleCreateDBAttr) {
 		return nil
 	}
@@ -792,7 +792,7 @@ func setRoleCreateDB(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func setRoleCreateRole(txn *sql.Tx, d *schema.ResourceData) error {
+func setRoleCreateRole(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(roleCreateRoleAttr) {
 		return nil
 	}
@@ -811,7 +811,7 @@ func setRoleCreateRole(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func setRoleInherit(txn *sql.Tx, d *schema.ResourceData) error {
+func setRoleInherit(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(roleInheritAttr) {
 		return nil
 	}
@@ -830,7 +830,7 @@ func setRoleInherit(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func setRoleLogin(txn *sql.Tx, d *schema.ResourceData) error {
+func setRoleLogin(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(roleLoginAttr) {
 		return nil
 	}
@@ -849,7 +849,7 @@ func setRoleLogin(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func setRoleReplication(txn *sql.Tx, d *schema.ResourceData) error {
+func setRoleReplication(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(roleReplicationAttr) {
 		return nil
 	}
@@ -868,7 +868,7 @@ func setRoleReplication(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func setRoleSuperuser(txn *sql.Tx, d *schema.ResourceData) error {
+func setRoleSuperuser(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(roleSuperuserAttr) {
 		return nil
 	}
@@ -887,7 +887,7 @@ func setRoleSuperuser(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func setRoleValidUntil(txn *sql.Tx, d *schema.ResourceData) error {
+func setRoleValidUntil(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(roleValidUntilAttr) {
 		return nil
 	}
@@ -908,7 +908,7 @@ func setRoleValidUntil(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func revokeRoles(txn *sql.Tx, d *schema.ResourceData) error {
+func revokeRoles(txn *DBTransaction, d *schema.ResourceData) error {
 	role := d.Get(roleNameAttr).(string)
 
 	query := `SELECT pg_get_userbyid(roleid)
@@ -947,7 +947,7 @@ func revokeRoles(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func grantRoles(txn *sql.Tx, d *schema.ResourceData) error {
+func grantRoles(txn *DBTransaction, d *schema.ResourceData) error {
 	role := d.Get(roleNameAttr).(string)
 
 	for _, grantingRole := range d.Get("roles").(*schema.Set).List() {
@@ -961,7 +961,7 @@ func grantRoles(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func alterSearchPath(txn *sql.Tx, d *schema.ResourceData) error {
+func alterSearchPath(txn *DBTransaction, d *schema.ResourceData) error {
 	role := d.Get(roleNameAttr).(string)
 	searchPathInterface := d.Get(roleSearchPathAttr).([]interface{})
 
@@ -988,7 +988,7 @@ func alterSearchPath(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func setStatementTimeout(txn *sql.Tx, d *schema.ResourceData) error {
+func setStatementTimeout(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(roleStatementTimeoutAttr) {
 		return nil
 	}
@@ -1013,7 +1013,7 @@ func setStatementTimeout(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func setIdleInTransactionSessionTimeout(txn *sql.Tx, d *schema.ResourceData) error {
+func setIdleInTransactionSessionTimeout(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(roleIdleInTransactionSessionTimeoutAttr) {
 		return nil
 	}
@@ -1038,7 +1038,7 @@ func setIdleInTransactionSessionTimeout(txn *sql.Tx, d *schema.ResourceData) err
 	return nil
 }
 
-func setAssumeRole(txn *sql.Tx, d *schema.ResourceData) error {
+func setAssumeRole(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(roleAssumeRoleAttr) {
 		return nil
 	}
@@ -31,11 +31,11 @@ const (
 
 func resourcePostgreSQLSchema() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLSchemaCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLSchemaRead),
-		Update: PGResourceFunc(resourcePostgreSQLSchemaUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLSchemaDelete),
-		Exists: PGResourceExistsFunc(resourcePostgreSQLSchemaExists),
+		CreateContext: PGResourceFunc(resourcePostgreSQLSchemaCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLSchemaRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLSchemaUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLSchemaDelete),
+		Exists:        PGResourceExistsFunc(resourcePostgreSQLSchemaExists),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -157,7 +157,7 @@ func resourcePostgreSQLSchemaCreate(db *DBConnection, d *schema.ResourceData) er
 	return resourcePostgreSQLSchemaReadImpl(db, d)
 }
 
-func createSchema(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
+func createSchema(db *DBConnection, txn *DBTransaction, d *schema.ResourceData) error {
 	schemaName := d.Get(schemaNameAttr).(string)
 
 	// Check if previous tasks haven't already create schema
@@ -391,7 +391,7 @@ func resourcePostgreSQLSchemaUpdate(db *DBConnection, d *schema.ResourceData) er
 	return resourcePostgreSQLSchemaReadImpl(db, d)
 }
 
-func setSchemaName(txn *sql.Tx, d *schema.ResourceData, databaseName string) error {
+func setSchemaName(txn *DBTransaction, d *schema.ResourceData, databaseName string) error {
 	if !d.HasChange(schemaNameAttr) {
 		return nil
 	}
@@ -412,7 +412,7 @@ func setSchemaName(txn *sql.Tx, d *schema.ResourceData, databaseName string) err
 	return nil
 }
 
-func setSchemaOwner(txn *sql.Tx, d *schema.ResourceData) error {
+func setSchemaOwner(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(schemaOwnerAttr) {
 		return nil
 	}
@@ -432,7 +432,7 @@ func setSchemaOwner(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func setSchemaPolicy(txn *sql.Tx, d *schema.ResourceData) error {
+func setSchemaPolicy(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(schemaPolicyAttr) {
 		return nil
 	}
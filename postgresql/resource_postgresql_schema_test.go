@@ -357,7 +357,7 @@ func testAccCheckPostgresqlSchemaExists(n string, schemaName string) resource.Te
 	}
 }
 
-func checkSchemaExists(txn *sql.Tx, schemaName string) (bool, error) {
+func checkSchemaExists(txn *DBTransaction, schemaName string) (bool, error) {
 	var _rez bool
 	err := txn.QueryRow("SELECT TRUE FROM pg_catalog.pg_namespace WHERE nspname=$1", schemaName).Scan(&_rez)
 	switch {
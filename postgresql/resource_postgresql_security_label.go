@@ -21,10 +21,10 @@ const (
 
 func resourcePostgreSQLSecurityLabel() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLSecurityLabelCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLSecurityLabelRead),
-		Update: PGResourceFunc(resourcePostgreSQLSecurityLabelUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLSecurityLabelDelete),
+		CreateContext: PGResourceFunc(resourcePostgreSQLSecurityLabelCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLSecurityLabelRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLSecurityLabelUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLSecurityLabelDelete),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
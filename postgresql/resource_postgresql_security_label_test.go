@@ -82,7 +82,7 @@ func TestAccPostgresqlSecurityLabel_Update(t *testing.T) {
 	})
 }
 
-func checkSecurityLabelExists(txn *sql.Tx, objectType string, objectName string, provider string) (bool, error) {
+func checkSecurityLabelExists(txn *DBTransaction, objectType string, objectName string, provider string) (bool, error) {
 	var _rez bool
 	err := txn.QueryRow("SELECT TRUE FROM pg_seclabels WHERE objtype = $1 AND objname = $2 AND provider = $3", objectType, quoteIdentifier(objectName), provider).Scan(&_rez)
 	switch {
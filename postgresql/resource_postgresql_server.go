@@ -23,10 +23,10 @@ const (
 
 func resourcePostgreSQLServer() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLServerCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLServerRead),
-		Update: PGResourceFunc(resourcePostgreSQLServerUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLServerDelete),
+		CreateContext: PGResourceFunc(resourcePostgreSQLServerCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLServerRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLServerUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLServerDelete),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -121,6 +121,18 @@ func resourcePostgreSQLServerCreate(db *DBConnection, d *schema.ResourceData) er
 	}
 	defer deferredRollback(txn)
 
+	fdwName := d.Get(serverFDWAttr).(string)
+	exists, err := fdwExists(txn, fdwName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf(
+			"foreign-data wrapper %q does not exist, the extension providing it (e.g. postgres_fdw or dblink_fdw) must be created first",
+			fdwName,
+		)
+	}
+
 	sql := b.String()
 	if _, err := txn.Exec(sql); err != nil {
 		return err
@@ -266,7 +278,7 @@ func resourcePostgreSQLServerUpdate(db *DBConnection, d *schema.ResourceData) er
 	return resourcePostgreSQLServerReadImpl(db, d)
 }
 
-func setServerVersionOptionsIfChanged(txn *sql.Tx, d *schema.ResourceData) error {
+func setServerVersionOptionsIfChanged(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(serverVersionAttr) && !d.HasChange(serverOptionsAttr) {
 		return nil
 	}
@@ -318,7 +330,7 @@ func setServerVersionOptionsIfChanged(txn *sql.Tx, d *schema.ResourceData) error
 	return nil
 }
 
-func setServerNameIfChanged(txn *sql.Tx, d *schema.ResourceData) error {
+func setServerNameIfChanged(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(serverNameAttr) {
 		return nil
 	}
@@ -336,14 +348,14 @@ func setServerNameIfChanged(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func setServerOwnerIfChanged(txn *sql.Tx, d *schema.ResourceData) error {
+func setServerOwnerIfChanged(txn *DBTransaction, d *schema.ResourceData) error {
 	if !d.HasChange(serverOwnerAttr) {
 		return nil
 	}
 	return setServerOwner(txn, d)
 }
 
-func setServerOwner(txn *sql.Tx, d *schema.ResourceData) error {
+func setServerOwner(txn *DBTransaction, d *schema.ResourceData) error {
 	serverName := d.Get(serverNameAttr).(string)
 	serverNewOwner := d.Get(serverOwnerAttr).(string)
 
@@ -357,3 +369,15 @@ func setServerOwner(txn *sql.Tx, d *schema.ResourceData) error {
 
 	return nil
 }
+
+func fdwExists(txn *DBTransaction, fdwName string) (bool, error) {
+	err := txn.QueryRow("SELECT 1 FROM pg_foreign_data_wrapper WHERE fdwname = $1", fdwName).Scan(&fdwName)
+	switch {
+	case err == sql.ErrNoRows:
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("could not check if foreign-data wrapper exists: %w", err)
+	}
+
+	return true, nil
+}
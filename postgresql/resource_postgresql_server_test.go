@@ -3,6 +3,7 @@ package postgresql
 import (
 	"database/sql"
 	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -56,6 +57,28 @@ func TestAccPostgresqlServer_Basic(t *testing.T) {
 	})
 }
 
+func TestAccPostgresqlServer_MissingFDW(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureServer)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlServerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "postgresql_server" "myserver_missing_fdw" {
+	server_name = "myserver_missing_fdw"
+	fdw_name    = "missing_fdw"
+}
+`,
+				ExpectError: regexp.MustCompile(`foreign-data wrapper "missing_fdw" does not exist`),
+			},
+		},
+	})
+}
+
 func testAccCheckPostgresqlServerDestroy(s *terraform.State) error {
 	client := testAccProvider.Meta().(*Client)
 
@@ -201,7 +224,7 @@ func TestAccPostgresqlServer_Update(t *testing.T) {
 	})
 }
 
-func checkServerExists(txn *sql.Tx, serverName string) (bool, error) {
+func checkServerExists(txn *DBTransaction, serverName string) (bool, error) {
 	var _rez bool
 	err := txn.QueryRow("SELECT TRUE FROM pg_foreign_server WHERE srvname=$1", serverName).Scan(&_rez)
 	switch {
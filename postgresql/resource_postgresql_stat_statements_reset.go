@@ -0,0 +1,130 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	statStatementsResetDatabaseAttr = "database"
+	statStatementsResetRoleAttr     = "role"
+	statStatementsResetQueryIDAttr  = "query_id"
+	statStatementsResetTriggersAttr = "triggers"
+)
+
+func resourcePostgreSQLStatStatementsReset() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: PGResourceFunc(resourcePostgreSQLStatStatementsResetCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLStatStatementsResetRead),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLStatStatementsResetDelete),
+
+		Schema: map[string]*schema.Schema{
+			statStatementsResetDatabaseAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The database whose pg_stat_statements statistics will be reset",
+			},
+			statStatementsResetRoleAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Only reset the statistics of the statements executed by this role",
+			},
+			statStatementsResetQueryIDAttr: {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Only reset the statistics of the statement with this query id",
+			},
+			statStatementsResetTriggersAttr: {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Arbitrary map of values that, when changed, will reset the statistics again",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLStatStatementsResetCreate(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featureStatStatementsResetDB) {
+		return fmt.Errorf(
+			"postgresql_stat_statements_reset resource is not supported for this Postgres version (%s)",
+			db.version,
+		)
+	}
+
+	database := d.Get(statStatementsResetDatabaseAttr).(string)
+	role := d.Get(statStatementsResetRoleAttr).(string)
+	queryID := d.Get(statStatementsResetQueryIDAttr).(int)
+
+	txn, err := startTransaction(db.client, database)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	// pg_stat_statements_reset is created in the schema of the extension.
+	var extSchema string
+	query := `SELECT n.nspname ` +
+		`FROM pg_catalog.pg_extension e, pg_catalog.pg_namespace n ` +
+		`WHERE n.oid = e.extnamespace AND e.extname = 'pg_stat_statements'`
+	err = txn.QueryRow(query).Scan(&extSchema)
+	switch {
+	case err == sql.ErrNoRows:
+		return fmt.Errorf("extension pg_stat_statements is not installed in database %s", database)
+	case err != nil:
+		return fmt.Errorf("could not check pg_stat_statements extension: %w", err)
+	}
+
+	// 0 means all the roles for userid.
+	var userID uint32
+	if role != "" {
+		if err := txn.QueryRow("SELECT oid FROM pg_catalog.pg_roles WHERE rolname = $1", role).Scan(&userID); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("role %s does not exist", role)
+			}
+			return fmt.Errorf("could not get oid of role %s: %w", role, err)
+		}
+	}
+
+	var dbID uint32
+	if err := txn.QueryRow("SELECT oid FROM pg_catalog.pg_database WHERE datname = $1", database).Scan(&dbID); err != nil {
+		return fmt.Errorf("could not get oid of database %s: %w", database, err)
+	}
+
+	sql := fmt.Sprintf(
+		"SELECT %s.pg_stat_statements_reset($1::oid, $2::oid, $3::bigint)",
+		pq.QuoteIdentifier(extSchema),
+	)
+	if _, err := txn.Exec(sql, userID, dbID, queryID); err != nil {
+		return fmt.Errorf("could not reset pg_stat_statements for database %s: %w", database, err)
+	}
+
+	if err := txn.Commit(); err != nil {
+		return fmt.Errorf("error committing pg_stat_statements reset: %w", err)
+	}
+	log.Printf("[DEBUG] pg_stat_statements reset for database %s", database)
+
+	d.SetId(fmt.Sprintf("%s_%s", database, strconv.FormatInt(time.Now().UnixNano(), 10)))
+
+	return nil
+}
+
+func resourcePostgreSQLStatStatementsResetRead(db *DBConnection, d *schema.ResourceData) error {
+	// Resetting the statistics is a one-off action, there is nothing to refresh.
+	return nil
+}
+
+func resourcePostgreSQLStatStatementsResetDelete(db *DBConnection, d *schema.ResourceData) error {
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,82 @@
+package postgresql
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresqlStatStatementsReset(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+	config := getTestConfig(t)
+
+	var preloaded bool
+	db, err := config.NewClient("postgres").Connect()
+	if err != nil {
+		t.Fatalf("could not connect to database: %v", err)
+	}
+	if err := db.QueryRow(
+		"SELECT current_setting('shared_preload_libraries') LIKE '%pg_stat_statements%'",
+	).Scan(&preloaded); err != nil {
+		t.Fatalf("could not read shared_preload_libraries: %v", err)
+	}
+	if !preloaded {
+		t.Skip("Skip test: pg_stat_statements is not in shared_preload_libraries")
+	}
+
+	dbExecute(t, config.connStr(dbName), "CREATE EXTENSION pg_stat_statements")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureStatStatementsResetDB)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "postgresql_stat_statements_reset" "test" {
+	database = "%s"
+}
+`, dbName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_stat_statements_reset.test", "database", dbName),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlStatStatementsReset_MissingExtension(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureStatStatementsResetDB)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "postgresql_stat_statements_reset" "test" {
+	database = "%s"
+}
+`, dbName),
+				ExpectError: regexp.MustCompile("extension pg_stat_statements is not installed"),
+			},
+		},
+	})
+}
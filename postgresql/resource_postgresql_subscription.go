@@ -13,11 +13,11 @@ import (
 
 func resourcePostgreSQLSubscription() *schema.Resource {
 	return &schema.Resource{
-		Create:   PGResourceFunc(resourcePostgreSQLSubscriptionCreate),
-		Read:     PGResourceFunc(resourcePostgreSQLSubscriptionRead),
-		Delete:   PGResourceFunc(resourcePostgreSQLSubscriptionDelete),
-		Exists:   PGResourceExistsFunc(resourcePostgreSQLSubscriptionExists),
-		Importer: &schema.ResourceImporter{StateContext: schema.ImportStatePassthroughContext},
+		CreateContext: PGResourceFunc(resourcePostgreSQLSubscriptionCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLSubscriptionRead),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLSubscriptionDelete),
+		Exists:        PGResourceExistsFunc(resourcePostgreSQLSubscriptionExists),
+		Importer:      &schema.ResourceImporter{StateContext: schema.ImportStatePassthroughContext},
 
 		Schema: map[string]*schema.Schema{
 			"name": {
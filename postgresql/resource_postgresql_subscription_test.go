@@ -52,7 +52,7 @@ func testAccCheckPostgresqlSubscriptionDestroy(s *terraform.State) error {
 	return nil
 }
 
-func checkSubscriptionExists(txn *sql.Tx, subName string) (bool, error) {
+func checkSubscriptionExists(txn *DBTransaction, subName string) (bool, error) {
 	var _rez bool
 	err := txn.QueryRow("SELECT TRUE from pg_catalog.pg_subscription WHERE subname=$1", subName).Scan(&_rez)
 
@@ -66,7 +66,7 @@ func checkSubscriptionExists(txn *sql.Tx, subName string) (bool, error) {
 	return true, nil
 }
 
-func checkSubscriptionStreams(txn *sql.Tx, subName string) (bool, error) {
+func checkSubscriptionStreams(txn *DBTransaction, subName string) (bool, error) {
 	var _rez bool
 	err := txn.QueryRow("SELECT TRUE from pg_catalog.pg_stat_replication WHERE application_name=$1 and state='streaming'", subName).Scan(&_rez)
 
@@ -0,0 +1,71 @@
+package postgresql
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	terminateConnsDatabaseAttr       = "database"
+	terminateConnsTriggersAttr       = "triggers"
+	terminateConnsTerminatedPidsAttr = "terminated_pids"
+)
+
+func resourcePostgreSQLTerminateConnections() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: PGResourceFunc(resourcePostgreSQLTerminateConnectionsCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLTerminateConnectionsRead),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLTerminateConnectionsDelete),
+
+		Schema: map[string]*schema.Schema{
+			terminateConnsDatabaseAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The database whose connections will be terminated",
+			},
+			terminateConnsTriggersAttr: {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Arbitrary map of values that, when changed, will terminate the connections again",
+			},
+			terminateConnsTerminatedPidsAttr: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+				Description: "The pids of the terminated backends",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLTerminateConnectionsCreate(db *DBConnection, d *schema.ResourceData) error {
+	database := d.Get(terminateConnsDatabaseAttr).(string)
+
+	pids, err := terminateDBBackends(db, database, true)
+	if err != nil {
+		return fmt.Errorf("could not terminate connections to database %s: %w", database, err)
+	}
+	log.Printf("[DEBUG] terminated %d connections to database %s: %v", len(pids), database, pids)
+
+	d.Set(terminateConnsTerminatedPidsAttr, pids)
+	d.SetId(fmt.Sprintf("%s_%s", database, strconv.FormatInt(time.Now().UnixNano(), 10)))
+
+	return nil
+}
+
+func resourcePostgreSQLTerminateConnectionsRead(db *DBConnection, d *schema.ResourceData) error {
+	// Terminating connections is a one-off action, there is nothing to refresh.
+	return nil
+}
+
+func resourcePostgreSQLTerminateConnectionsDelete(db *DBConnection, d *schema.ResourceData) error {
+	d.SetId("")
+	return nil
+}
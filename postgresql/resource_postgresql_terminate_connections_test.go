@@ -0,0 +1,72 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccPostgresqlTerminateConnections(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+	config := getTestConfig(t)
+
+	// Open a session on the test database which should be terminated.
+	conn, err := sql.Open("postgres", config.connStr(dbName))
+	if err != nil {
+		t.Fatalf("could not create connection pool: %v", err)
+	}
+	defer conn.Close()
+
+	var sessionPid int
+	if err := conn.QueryRow("SELECT pg_backend_pid()").Scan(&sessionPid); err != nil {
+		t.Fatalf("could not open session on database %s: %v", dbName, err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "postgresql_terminate_connections" "test" {
+	database = "%s"
+}
+`, dbName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_terminate_connections.test", "database", dbName),
+					resource.TestCheckTypeSetElemAttr(
+						"postgresql_terminate_connections.test", "terminated_pids.*", fmt.Sprint(sessionPid),
+					),
+					testAccCheckBackendTerminated(sessionPid),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckBackendTerminated(pid int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		db, err := client.Connect()
+		if err != nil {
+			return err
+		}
+
+		var exists bool
+		if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_stat_activity WHERE pid = $1)", pid).Scan(&exists); err != nil {
+			return fmt.Errorf("could not check backend %d: %w", pid, err)
+		}
+		if exists {
+			return fmt.Errorf("backend %d has not been terminated", pid)
+		}
+		return nil
+	}
+}
@@ -19,10 +19,10 @@ const (
 
 func resourcePostgreSQLUserMapping() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLUserMappingCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLUserMappingRead),
-		Update: PGResourceFunc(resourcePostgreSQLUserMappingUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLUserMappingDelete),
+		CreateContext: PGResourceFunc(resourcePostgreSQLUserMappingCreate),
+		ReadContext:   PGResourceFunc(resourcePostgreSQLUserMappingRead),
+		UpdateContext: PGResourceFunc(resourcePostgreSQLUserMappingUpdate),
+		DeleteContext: PGResourceFunc(resourcePostgreSQLUserMappingDelete),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -80,7 +80,7 @@ func TestAccPostgresqlUserMapping_Update(t *testing.T) {
 	})
 }
 
-func checkUserMappingExists(txn *sql.Tx, username string, serverName string) (bool, error) {
+func checkUserMappingExists(txn *DBTransaction, username string, serverName string) (bool, error) {
 	var _rez bool
 	err := txn.QueryRow("SELECT TRUE FROM pg_user_mappings WHERE usename = $1 AND srvname = $2", username, serverName).Scan(&_rez)
 	switch {
@@ -0,0 +1,92 @@
+package postgresql
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+)
+
+var (
+	sqlLogsLock sync.Mutex
+	// sqlLogs are the open SQL log files, by path: the provider configurations
+	// logging to the same file share it.
+	sqlLogs = map[string]*sqlLog{}
+
+	// sqlPasswordRegexp matches the PASSWORD clause of CREATE and ALTER ROLE,
+	// e.g. PASSWORD 'secret' or ENCRYPTED PASSWORD 'it''s'.
+	sqlPasswordRegexp = regexp.MustCompile(`(?i)(\bPASSWORD\s+)'(?:[^']|'')*'`)
+	sqlNewlineRegexp  = regexp.MustCompile(`\s*\n\s*`)
+)
+
+// sqlLog appends the statements executed by the provider to a file (sql_log_file).
+// Each statement is written with a single write on the file opened in append mode,
+// so nothing is buffered and lost if the provider is stopped.
+type sqlLog struct {
+	lock     sync.Mutex
+	file     *os.File
+	password string
+}
+
+// openSQLLog returns the SQL log writing to *path*, opening the file if it is not already.
+// *password* is the password of the provider, redacted from the statements.
+func openSQLLog(path, password string) (*sqlLog, error) {
+	sqlLogsLock.Lock()
+	defer sqlLogsLock.Unlock()
+
+	if l, ok := sqlLogs[path]; ok {
+		return l, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("could not open sql_log_file: %w", err)
+	}
+	l := &sqlLog{file: file, password: password}
+	sqlLogs[path] = l
+	return l, nil
+}
+
+// CloseSQLLogs closes the SQL log files, when the provider shuts down.
+func CloseSQLLogs() {
+	sqlLogsLock.Lock()
+	defer sqlLogsLock.Unlock()
+
+	for path, l := range sqlLogs {
+		l.lock.Lock()
+		if err := l.file.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "[ERR] could not close sql_log_file %s: %v\n", path, err)
+		}
+		l.lock.Unlock()
+		delete(sqlLogs, path)
+	}
+}
+
+// record writes the *query* started at *start*, with its duration and its error if any.
+// The arguments of the query are not written, and the passwords are redacted.
+func (l *sqlLog) record(start time.Time, query string, err error) {
+	if l == nil {
+		return
+	}
+
+	line := fmt.Sprintf(
+		"%s duration=%s %s",
+		start.UTC().Format(time.RFC3339Nano), time.Since(start).Round(time.Microsecond), redactSQL(query, l.password),
+	)
+	if err != nil {
+		line += fmt.Sprintf(" -- error: %s", redactSQL(err.Error(), l.password))
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if _, err := l.file.WriteString(line + "\n"); err != nil {
+		fmt.Fprintf(os.Stderr, "[ERR] could not write to sql_log_file: %v\n", err)
+	}
+}
+
+// redactSQL removes the passwords from the statement *query*, on a single line.
+func redactSQL(query, password string) string {
+	query = sqlPasswordRegexp.ReplaceAllString(query, "${1}'"+redactedPassword+"'")
+	return sqlNewlineRegexp.ReplaceAllString(redactDSN(query, password), " ")
+}
@@ -0,0 +1,137 @@
+package postgresql
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+)
+
+func TestRedactSQL(t *testing.T) {
+	var tests = []struct {
+		query    string
+		password string
+		expected string
+	}{
+		{
+			query:    "CREATE ROLE \"app\" WITH LOGIN PASSWORD 's3cret'",
+			expected: "CREATE ROLE \"app\" WITH LOGIN PASSWORD 'XXXX'",
+		},
+		{
+			query:    "ALTER ROLE \"app\" ENCRYPTED password 'it''s secret' VALID UNTIL 'infinity'",
+			expected: "ALTER ROLE \"app\" ENCRYPTED password 'XXXX' VALID UNTIL 'infinity'",
+		},
+		{
+			query:    "CREATE SUBSCRIPTION s CONNECTION 'host=primary password=s3cret' PUBLICATION p",
+			expected: "CREATE SUBSCRIPTION s CONNECTION 'host=primary password=XXXX PUBLICATION p",
+		},
+		{
+			query:    "SELECT 'provider p@ss'",
+			password: "provider p@ss",
+			expected: "SELECT 'XXXX'",
+		},
+		{
+			query:    "SELECT 1\n  FROM pg_catalog.pg_database\n",
+			expected: "SELECT 1 FROM pg_catalog.pg_database ",
+		},
+	}
+
+	for _, test := range tests {
+		if actual := redactSQL(test.query, test.password); actual != test.expected {
+			t.Errorf("redactSQL(%q) = %q, want %q", test.query, actual, test.expected)
+		}
+	}
+}
+
+func TestSQLLogRecordsStatements(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sql.log")
+	sqlLog, err := openSQLLog(path, "provider p@ss")
+	if err != nil {
+		t.Fatalf("could not open the SQL log: %v", err)
+	}
+	defer CloseSQLLogs()
+
+	if other, _ := openSQLLog(path, ""); other != sqlLog {
+		t.Errorf("the SQL log of %s should be shared", path)
+	}
+
+	// Nothing listens on port 1, the statements fail but are still logged.
+	pool, err := sql.Open("postgres", "host=127.0.0.1 port=1 user=user password='provider p@ss' sslmode=disable connect_timeout=1")
+	if err != nil {
+		t.Fatalf("could not open the connection pool: %v", err)
+	}
+	defer pool.Close()
+	config := Config{sqlLog: sqlLog}
+	db := &DBConnection{pool, config.NewClient("postgres"), semver.MustParse(defaultExpectedPostgreSQLVersion)}
+
+	db.Exec("ALTER ROLE \"app\" PASSWORD 's3cret'")
+	db.Query("SELECT datname FROM pg_catalog.pg_database")
+	db.QueryRow("SELECT 1")
+	CloseSQLLogs()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read the SQL log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	expected := []string{
+		"ALTER ROLE \"app\" PASSWORD 'XXXX'",
+		"SELECT datname FROM pg_catalog.pg_database",
+		"SELECT 1",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d statements in the SQL log, got:\n%s", len(expected), content)
+	}
+	for i, line := range lines {
+		if !strings.Contains(line, " duration=") || !strings.Contains(line, expected[i]+" -- error: ") {
+			t.Errorf("unexpected SQL log entry %q, want the duration and %q", line, expected[i])
+		}
+	}
+	for _, password := range []string{"s3cret", "provider p@ss"} {
+		if strings.Contains(string(content), password) {
+			t.Errorf("the SQL log contains the password %q:\n%s", password, content)
+		}
+	}
+}
+
+func TestAccSQLLogRecordsTransactions(t *testing.T) {
+	skipIfNotAcc(t)
+
+	path := filepath.Join(t.TempDir(), "sql.log")
+	sqlLog, err := openSQLLog(path, "")
+	if err != nil {
+		t.Fatalf("could not open the SQL log: %v", err)
+	}
+	defer CloseSQLLogs()
+
+	config := getTestConfig(t)
+	config.sqlLog = sqlLog
+
+	// The statements executed within a transaction are logged like the other ones.
+	txn, err := startTransaction(config.NewClient("postgres"), "")
+	if err != nil {
+		t.Fatalf("could not start transaction: %v", err)
+	}
+	defer deferredRollback(txn)
+	if _, err := txn.Exec("SELECT 1"); err != nil {
+		t.Fatalf("could not execute the statement: %v", err)
+	}
+	var value int
+	if err := txn.QueryRow("SELECT 2").Scan(&value); err != nil {
+		t.Fatalf("could not execute the query: %v", err)
+	}
+	CloseSQLLogs()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read the SQL log: %v", err)
+	}
+	for _, statement := range []string{"SELECT 1\n", "SELECT 2\n"} {
+		if !strings.Contains(string(content), statement) {
+			t.Errorf("the SQL log does not contain the statement %q of the transaction:\n%s", statement, content)
+		}
+	}
+}